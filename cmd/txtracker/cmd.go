@@ -1 +1,230 @@
 package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"txtracker/internal/compiler"
+	"txtracker/internal/txtracker"
+)
+
+type PrinterType string
+
+const (
+	ANALYZE           PrinterType = "analyze"
+	AST_PRINTER       PrinterType = "ast"
+	CFG_PRINTER       PrinterType = "cfg"
+	CALLGRAPH_PRINTER PrinterType = "callgraph"
+	SYMBOLS_PRINTER   PrinterType = "symbols"
+	TXSEQ_PRINTER     PrinterType = "txseq"
+	DETECT            PrinterType = "detect"
+	ACCESS_PRINTER    PrinterType = "access"
+	REPORT            PrinterType = "report"
+	SUMMARY           PrinterType = "summary"
+)
+
+const DEFAULT_INPUT = "../../dataset/contracts"
+
+// command describes a subcommand and the output formats it supports.
+// The first format is the default one.
+type command struct {
+	Name    PrinterType
+	Summary string
+	Formats []string
+}
+
+var commands = []command{
+	{ANALYZE, "compile, parse and build the CFG, then report a summary per contract", []string{"text"}},
+	{AST_PRINTER, "print the AST tree of each source unit", []string{"text"}},
+	{CFG_PRINTER, "print the control flow graph of every entry point", []string{"text", "json", "dot", "mermaid"}},
+	{CALLGRAPH_PRINTER, "print the call graph of every contract", []string{"text", "dot"}},
+	{SYMBOLS_PRINTER, "print the global symbol table", []string{"text", "json"}},
+	{TXSEQ_PRINTER, "print the transaction sequences linked by state variable writes and reads", []string{"text", "json"}},
+	{DETECT, "run the detectors and report their findings", []string{"text", "json", "sarif"}},
+	{ACCESS_PRINTER, "print which entry points write which state variables, under which guard", []string{"text", "json"}},
+	{SUMMARY, "print what each entry point reads, writes, emits, calls and checks", []string{"csv", "jsonl"}},
+	{REPORT, "write a self-contained HTML report per deployed contract", []string{"html"}},
+}
+
+// Options is the parsed command line.
+type Options struct {
+	Command PrinterType
+	Input   string // a .sol file, a .sol.ast.json file or a directory of .sol files
+	Format  string
+	Output  string // empty means stdout
+	Length  int    // maximum length of the transaction sequences
+	SolcDir string // directory of the solc builds
+	// the input is a build-info file or a project with build-info artifacts
+	BuildInfo bool
+	// standard JSON mode, for projects with imports
+	StandardJSON bool
+	BasePath     string // root of the project, defaults to the input directory
+	Remappings   stringList
+	Optimize     bool
+	OptimizeRuns int
+	// CFG diagrams
+	GraphDir    string // write a file per graph in this directory, empty means the output
+	PerFunction bool   // a graph per entry point instead of one per contract
+	ReportDir   string // directory of the HTML reports
+	// detectors
+	ListDetectors bool
+	Enable        stringList // IDs of the detectors to run, all of them if empty
+	Disable       stringList
+}
+
+// stringList is a flag that can be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set appends a value, a comma separated list adds each of its items
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if string(c.Name) == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// cmd parses the arguments following the program name.
+// It returns flag.ErrHelp when only the help text was requested.
+func cmd(args []string) (*Options, error) {
+	return parseArgs(args, os.Stderr)
+}
+
+func parseArgs(args []string, usageOut io.Writer) (*Options, error) {
+	if len(args) == 0 {
+		printUsage(usageOut)
+		return nil, errors.New("no command given")
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help":
+		printUsage(usageOut)
+		return nil, flag.ErrHelp
+	case "help":
+		if len(args) >= 2 {
+			if c, ok := lookupCommand(args[1]); ok {
+				newFlagSet(c, &Options{}, usageOut).Usage()
+				return nil, flag.ErrHelp
+			}
+		}
+		printUsage(usageOut)
+		return nil, flag.ErrHelp
+	}
+
+	c, ok := lookupCommand(args[0])
+	if !ok {
+		printUsage(usageOut)
+		return nil, fmt.Errorf("unknown command %q", args[0])
+	}
+
+	opts := &Options{Command: c.Name}
+	fs := newFlagSet(c, opts, usageOut)
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+
+	// A single positional argument is accepted as the input path
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		opts.Input = fs.Arg(0)
+	default:
+		fs.Usage()
+		return nil, fmt.Errorf("too many arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if opts.Format == "" {
+		opts.Format = c.Formats[0]
+	}
+	if !contains(c.Formats, opts.Format) {
+		return nil, fmt.Errorf("command %s does not support format %q (supported: %s)",
+			c.Name, opts.Format, strings.Join(c.Formats, ", "))
+	}
+	if (opts.GraphDir != "" || opts.PerFunction) && opts.Format != "dot" && opts.Format != "mermaid" {
+		return nil, errors.New("--graph-dir and --per-function require the dot or mermaid format")
+	}
+
+	return opts, nil
+}
+
+func newFlagSet(c command, opts *Options, usageOut io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(string(c.Name), flag.ContinueOnError)
+	fs.SetOutput(usageOut)
+
+	fs.StringVar(&opts.Input, "input", DEFAULT_INPUT, "`path` to a .sol file, a .sol.ast.json file or a directory of .sol files")
+	fs.StringVar(&opts.Input, "i", DEFAULT_INPUT, "shorthand for --input")
+	fs.StringVar(&opts.Format, "format", "", "output `format`: "+strings.Join(c.Formats, ", "))
+	fs.StringVar(&opts.Format, "f", "", "shorthand for --format")
+	fs.StringVar(&opts.Output, "output", "", "write the output to `file` instead of stdout")
+	fs.StringVar(&opts.Output, "o", "", "shorthand for --output")
+	fs.StringVar(&opts.SolcDir, "solc-dir", compiler.DefaultSolcDir(), "`directory` of the solc builds, the version is picked from the pragma of each file")
+	fs.BoolVar(&opts.BuildInfo, "build-info", false, "read the ASTs from the build-info artifacts of the input, a Foundry or Hardhat project, without running solc")
+	fs.BoolVar(&opts.StandardJSON, "standard-json", false, "compile with solc --standard-json, resolving imports and remappings")
+	fs.StringVar(&opts.BasePath, "base-path", "", "root `directory` of the project for --standard-json, defaults to the input directory")
+	fs.Var(&opts.Remappings, "remap", "import `remapping` [context:]prefix=target for --standard-json, can be repeated (default: remappings.txt of the base path)")
+	fs.BoolVar(&opts.Optimize, "optimize", false, "enable the solc optimizer for --standard-json")
+	fs.IntVar(&opts.OptimizeRuns, "optimize-runs", 200, "`number` of optimizer runs for --standard-json")
+	if c.Name == TXSEQ_PRINTER {
+		fs.IntVar(&opts.Length, "length", txtracker.DEFAULT_MAX_LENGTH, "maximum `number` of transactions of a sequence")
+		fs.IntVar(&opts.Length, "n", txtracker.DEFAULT_MAX_LENGTH, "shorthand for --length")
+	}
+	if c.Name == CFG_PRINTER {
+		fs.StringVar(&opts.GraphDir, "graph-dir", "", "write each dot or mermaid graph to a file of this `directory`")
+		fs.BoolVar(&opts.PerFunction, "per-function", false, "draw a dot or mermaid graph per entry point instead of one per contract")
+	}
+	if c.Name == REPORT {
+		fs.StringVar(&opts.ReportDir, "report-dir", "reports", "`directory` of the reports, one file per contract")
+	}
+	if c.Name == DETECT {
+		fs.BoolVar(&opts.ListDetectors, "list", false, "list the available detectors and exit")
+	}
+	if c.Name == DETECT || c.Name == REPORT {
+		fs.Var(&opts.Enable, "enable", "comma separated `IDs` of the only detectors to run, can be repeated")
+		fs.Var(&opts.Disable, "disable", "comma separated `IDs` of detectors not to run, can be repeated")
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(usageOut, "Usage: txtracker %s [flags] [input]\n\n%s\n\nFlags:\n", c.Name, c.Summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "TxTracker: a static checker for Solidity contracts")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage: txtracker <command> [flags] [input]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.Name, c.Summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'txtracker help <command>' for the flags of a command.")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
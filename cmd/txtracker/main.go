@@ -1,77 +1,435 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	AST "txtracker/internal/ast"
+	"txtracker/internal/callgraph"
 	CFG "txtracker/internal/cfg"
 	"txtracker/internal/compiler"
+	"txtracker/internal/detectors"
 	"txtracker/internal/filehandler"
 	"txtracker/internal/logger"
 	"txtracker/internal/parser"
 	"txtracker/internal/printer"
+	"txtracker/internal/srcmap"
 	symboltable "txtracker/internal/symbol_table"
+	"txtracker/internal/txtracker"
 )
 
 func main() {
+	opts, err := cmd(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "txtracker:", err)
+		os.Exit(2)
+	}
+
+	if err := run(opts); err != nil {
+		logger.Fatal.Println(err)
+		fmt.Fprintln(os.Stderr, "txtracker:", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts *Options) error {
+	if opts.Output == "" {
+		return execute(opts, os.Stdout)
+	}
+	file, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %v", err)
+	}
+	err = execute(opts, file)
+	// a failed flush truncates the output, it must not exit 0
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing output file: %v", closeErr)
+	}
+	return err
+}
+
+// execute runs the command of the options, writing its output to out
+func execute(opts *Options, out io.Writer) error {
+	var selected []detectors.Detector
+	var findings []detectors.Finding
+	var warnings []detectors.Step // of the CFG construction
+	if opts.Command == DETECT || opts.Command == REPORT {
+		if opts.ListDetectors {
+			printer.PrintDetectors(detectors.All(), out)
+			return nil
+		}
+		var err error
+		if selected, err = detectors.Select(opts.Enable, opts.Disable); err != nil {
+			return err
+		}
+	}
+	summary_printer := printer.NewSummaryPrinter(out) // shared by the source units, for a single table
+	process := func(path string, root *AST.Common, symbol_table *symboltable.GlobalSymbolTable, sources *srcmap.SourceMap) error {
+		if opts.Command == DETECT {
+			unitFindings, unitWarnings := detect(selected, newContext(path, root, symbol_table, sources))
+			findings = append(findings, unitFindings...)
+			warnings = append(warnings, unitWarnings...)
+			return nil
+		}
+		if opts.Command == REPORT {
+			return report(opts, selected, newContext(path, root, symbol_table, sources))
+		}
+		if opts.Command == SUMMARY {
+			summaries := detectors.NewFunctionSummaries(newContext(path, root, symbol_table, sources))
+			if opts.Format == "jsonl" {
+				return summary_printer.PrintJSONL(summaries)
+			}
+			return summary_printer.PrintCSV(summaries)
+		}
+		return printOutput(opts, out, path, root, symbol_table, sources)
+	}
+
+	// the findings of the files parsed are printed even if others were skipped
+	err := load(opts, process)
+	var skipped *skippedError
+	if err != nil && !errors.As(err, &skipped) {
+		return err
+	}
+
+	if opts.Command == DETECT {
+		if err := printFindings(opts, out, selected, findings, warnings); err != nil {
+			return err
+		}
+	}
+	return err
+}
 
-	var SPECIFIC_CONTRACT string
-	var PRINTER PrinterType
-	SPECIFIC_CONTRACT, PRINTER = cmd(os.Args)
-	// if len(os.Args) >= 2 {
-	// 	SPECIFIC_CONTRACT = os.Args[1]
-	// } else {
-	// 	SPECIFIC_CONTRACT = ""
-	// }
+func printFindings(opts *Options, out io.Writer, selected []detectors.Detector, findings []detectors.Finding, warnings []detectors.Step) error {
+	if opts.Format == "sarif" {
+		return printer.NewSARIFPrinter(findings, selected, warnings, out).Print()
+	}
+	findings_printer := printer.NewFindingsPrinter(findings, out)
+	if opts.Format == "json" {
+		return findings_printer.PrintJSON()
+	}
+	findings_printer.Print()
+	return nil
+}
 
-	filehandler, err := filehandler.NewFileHandler("../../dataset/contracts", SPECIFIC_CONTRACT)
+// load compiles or reads the AST of every source unit of the input, then calls process
+// on the units that are compilation targets, with the symbol table and the source
+// files of their compilation; the imported units are only part of the symbol table.
+func load(opts *Options, process func(path string, root *AST.Common, symbol_table *symboltable.GlobalSymbolTable, sources *srcmap.SourceMap) error) error {
+	if opts.BuildInfo {
+		units, err := parser.NewBuildInfoLoader().Load(opts.Input)
+		if err != nil {
+			return err
+		}
+		symbol_tables := make(map[*parser.Compilation]*symboltable.GlobalSymbolTable)
+		for _, unit := range units {
+			fmt.Fprintln(os.Stderr, "Processing:", unit.Path)
+			symbol_table, ok := symbol_tables[unit.Compilation]
+			if !ok {
+				symbol_table, err = symboltable.NewGlobalSymbolTable(unit.Compilation.Roots...)
+				if err != nil {
+					return fmt.Errorf("error building the symbol table of %s: %v", unit.Path, err)
+				}
+				symbol_tables[unit.Compilation] = symbol_table
+			}
+			if err := process(unit.Path, unit.Root, symbol_table, unit.Sources); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	filehandler, err := filehandler.NewFileHandler(opts.Input)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	// Compile then Parse AST
-	compiler := compiler.NewSolidityCompiler()
-	parser := parser.NewASTParser()
+	compiler := newCompiler(opts)
+	ast_parser := parser.NewASTParser()
 	solFilePaths := filehandler.GetContractSolPathList()
+	var skipped []string
 	for _, path := range solFilePaths {
-		fmt.Println("Processing:", path)
-		err := compiler.SolidityToAST_JSON(path)
+		fmt.Fprintln(os.Stderr, "Processing:", path)
+		astFilePath := path
+		compiled := !strings.HasSuffix(path, ".ast.json")
+		if compiled {
+			err := compiler.SolidityToAST_JSON(path)
+			printWarnings(compiler)
+			if err != nil {
+				return fmt.Errorf("error compiling %s: %v", path, err)
+			}
+			astFilePath = path + ".ast.json"
+		}
+		units, err := ast_parser.ParseSources(astFilePath)
+		if err != nil {
+			// a malformed AST does not stop the analysis of the other files
+			fmt.Fprintln(os.Stderr, "txtracker: skipping", path+":", err)
+			skipped = append(skipped, path)
+			continue
+		}
+		target, err := parser.TargetUnit(units, strings.TrimSuffix(astFilePath, ".ast.json"))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "txtracker: skipping", path+":", err)
+			skipped = append(skipped, path)
+			continue
+		}
+		root := target.Root
+		symbol_table, err := symboltable.NewGlobalSymbolTable(target.Compilation.Roots...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "txtracker: skipping", path+":", err)
+			skipped = append(skipped, path)
+			continue
+		}
+
+		sources := readSource(strings.TrimSuffix(path, ".ast.json"), root)
+		if compiled {
+			sources = compiledSources(compiler, sources)
+		}
+		if err := process(strings.TrimSuffix(path, ".ast.json"), root, symbol_table, sources); err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		return &skippedError{paths: skipped}
+	}
+	return nil
+}
+
+// skippedError reports the files whose AST could not be parsed, the others were
+// processed
+type skippedError struct {
+	paths []string
+}
+
+func (e *skippedError) Error() string {
+	return fmt.Sprintf("%d file(s) skipped: %s", len(e.paths), strings.Join(e.paths, ", "))
+}
+
+func newCompiler(opts *Options) compiler.Compiler {
+	if !opts.StandardJSON {
+		return compiler.NewSolidityCompiler(opts.SolcDir)
+	}
+
+	basePath := opts.BasePath
+	if basePath == "" {
+		basePath = opts.Input
+		if info, err := os.Stat(opts.Input); err == nil && !info.IsDir() {
+			basePath = filepath.Dir(opts.Input)
+		}
+	}
+	c := compiler.NewStandardJSONCompiler(opts.SolcDir, basePath, opts.Remappings)
+	c.Optimizer = compiler.OptimizerSettings{Enabled: opts.Optimize, Runs: opts.OptimizeRuns}
+	return c
+}
+
+// printWarnings reports the warnings of the last standard JSON compilation
+func printWarnings(c compiler.Compiler) {
+	if c, ok := c.(*compiler.StandardJSONCompiler); ok && c.Output != nil {
+		for _, warning := range c.Output.Warnings() {
+			fmt.Fprintln(os.Stderr, warning.String())
+		}
+	}
+}
+
+// compiledSources returns the source files of the last standard JSON compilation,
+// with its imports, or sources for a file compiled on its own
+func compiledSources(c compiler.Compiler, sources *srcmap.SourceMap) *srcmap.SourceMap {
+	if c, ok := c.(*compiler.StandardJSONCompiler); ok && c.Output != nil {
+		return c.Sources()
+	}
+	return sources
+}
+
+// readSource returns the source map of a file compiled on its own, empty if the
+// file cannot be read, e.g. for an .ast.json file without its .sol file
+func readSource(path string, root *AST.Common) *srcmap.SourceMap {
+	sources := srcmap.NewSourceMap()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logger.Warning.Println("No source file for the locations:", err)
+		return sources
+	}
+	index := 0
+	if r, err := srcmap.Parse(root.Src); err == nil {
+		index = r.File
+	}
+	sources.Add(index, path, content)
+	return sources
+}
+
+// detect runs the selected detectors over a source unit, it returns their findings
+// and the warnings of the CFG construction
+func detect(selected []detectors.Detector, ctx *detectors.Context) ([]detectors.Finding, []detectors.Step) {
+	var warnings []detectors.Step
+	for _, w := range ctx.CFG.Warnings {
+		warnings = append(warnings, detectors.Step{Location: detectors.SrcLocation(ctx, w.Src), Message: w.Message})
+	}
+	return detectors.Run(selected, ctx), warnings
+}
+
+// newContext builds the CFG of a source unit over the symbol table of its compilation
+func newContext(path string, root *AST.Common, symbol_table *symboltable.GlobalSymbolTable, sources *srcmap.SourceMap) *detectors.Context {
+	return &detectors.Context{
+		Path:        path,
+		Root:        root,
+		SymbolTable: symbol_table,
+		CFG:         CFG.NewCFG(root, symbol_table),
+		Sources:     sources,
+	}
+}
+
+// report writes the HTML report of every deployed contract of a source unit to
+// the report directory
+func report(opts *Options, selected []detectors.Detector, ctx *detectors.Context) error {
+	findings := detectors.Run(selected, ctx)
+	report_printer := printer.NewHTMLPrinter(ctx, findings, nil)
+	for _, contract := range report_printer.Contracts() {
+		name := filepath.Join(opts.ReportDir, report_printer.FileName(contract))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return fmt.Errorf("error creating report directory: %v", err)
+		}
+		file, err := os.Create(name)
 		if err != nil {
-			logger.Fatal.Println("Error compiling:", err)
-			panic(err)
+			return fmt.Errorf("error creating report file: %v", err)
 		}
-		astFilePath := path + ".ast.json"
-		root := parser.ParseAST_JSON(astFilePath)
-		symbol_table := symboltable.NewGlobalSymbolTable(root)
-		cfg := CFG.NewCFG(root, symbol_table)
+		report_printer.Out = file
+		err = report_printer.Print(contract)
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("error writing report file: %v", closeErr)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Written:", name)
+	}
+	return nil
+}
 
-		cfg_printer := printer.NewCFGPrinter(cfg)
+func printOutput(opts *Options, out io.Writer, path string, root *AST.Common, symbol_table *symboltable.GlobalSymbolTable, sources *srcmap.SourceMap) error {
+	if opts.Command == AST_PRINTER {
+		printer.NewASTPrinter(root, out).PrintAST()
+		return nil
+	}
 
-		switch PRINTER {
-		case CFG_PRINTER:
-			cfg_printer.Print()
+	if opts.Command == SYMBOLS_PRINTER {
+		symbol_printer := printer.NewSymbolPrinter(symbol_table, out)
+		if opts.Format == "json" {
+			return symbol_printer.PrintJSON()
 		}
+		symbol_printer.Print()
+		return nil
+	}
 
+	if opts.Command == CALLGRAPH_PRINTER {
+		callgraph_printer := printer.NewCallGraphPrinter(callgraph.NewCallGraph(root, symbol_table), out)
+		if opts.Format == "dot" {
+			callgraph_printer.PrintDOT()
+		} else {
+			callgraph_printer.Print()
+		}
+		return nil
 	}
 
+	cfg := CFG.NewCFG(root, symbol_table)
+	ctx := &detectors.Context{Path: path, Root: root, SymbolTable: symbol_table, CFG: cfg, Sources: sources}
+
+	switch opts.Command {
+	case CFG_PRINTER:
+		cfg_printer := printer.NewCFGPrinter(cfg, sources, out)
+		switch opts.Format {
+		case "json":
+			return cfg_printer.PrintJSON(path)
+		case "dot", "mermaid":
+			return printGraphs(opts, out, path, cfg, sources)
+		}
+		cfg_printer.Print()
+	case ANALYZE:
+		printSummary(out, cfg)
+	case TXSEQ_PRINTER:
+		txseq_printer := printer.NewTxSequencePrinter(txtracker.NewGenerator(ctx, opts.Length).Generate(), out)
+		if opts.Format == "json" {
+			return txseq_printer.PrintJSON()
+		}
+		txseq_printer.Print()
+	case ACCESS_PRINTER:
+		access_printer := printer.NewAccessPrinter(detectors.NewPermissionMatrices(ctx), out)
+		if opts.Format == "json" {
+			return access_printer.PrintJSON()
+		}
+		access_printer.Print()
+	}
+	return nil
 }
 
-type PrinterType string
+// printGraphs draws the CFG per contract or per entry point, to the output or
+// to a file per graph in a directory per source unit. On the output, the
+// Mermaid graphs are fenced so that they render in Markdown.
+func printGraphs(opts *Options, out io.Writer, path string, cfg *CFG.CFG, sources *srcmap.SourceMap) error {
+	graphs := printer.CFGGraphsByContract(cfg)
+	if opts.PerFunction {
+		graphs = printer.CFGGraphsByFunction(cfg)
+	}
 
-const (
-	CFG_PRINTER       PrinterType = "cfg"
-	CALLGRAPH_PRINTER PrinterType = "callgraph"
-)
+	if opts.GraphDir == "" {
+		for _, graph := range graphs {
+			graph_printer := printer.NewCFGPrinter(cfg, sources, out)
+			if opts.Format == "dot" {
+				graph_printer.PrintDOT(graph)
+			} else {
+				fmt.Fprintln(out, "```mermaid")
+				graph_printer.PrintMermaid(graph)
+				fmt.Fprintln(out, "```")
+			}
+			fmt.Fprintln(out)
+		}
+		return nil
+	}
 
-type SPECIFIC_CONTRACT = string
+	dir := filepath.Join(opts.GraphDir, printer.UnitDir(path))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating graph directory: %v", err)
+	}
+	ext := map[string]string{"dot": "dot", "mermaid": "mmd"}[opts.Format]
+	for _, graph := range graphs {
+		name := filepath.Join(dir, graph.FileName(ext))
+		file, err := os.Create(name)
+		if err != nil {
+			return fmt.Errorf("error creating graph file: %v", err)
+		}
+		graph_printer := printer.NewCFGPrinter(cfg, sources, file)
+		if opts.Format == "dot" {
+			graph_printer.PrintDOT(graph)
+		} else {
+			graph_printer.PrintMermaid(graph)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("error writing graph file: %v", err)
+		}
+		fmt.Fprintln(os.Stderr, "Written:", name)
+	}
+	return nil
+}
 
-func cmd(args []string) (SPECIFIC_CONTRACT, PrinterType) {
-	var SPECIFIC_CONTRACT string
-	var PRINTER PrinterType
-	SPECIFIC_CONTRACT, PRINTER = "", CFG_PRINTER
-	if len(args) >= 2 {
-		SPECIFIC_CONTRACT = args[1]
-	} else {
-		SPECIFIC_CONTRACT = ""
+// printSummary lists the number of entry points found per contract
+func printSummary(out io.Writer, cfg *CFG.CFG) {
+	var contracts []string
+	entryPoints := make(map[string]int)
+	for _, entry := range cfg.EntryPoints {
+		contract := strings.Split(entry.Name, "::")[0]
+		if _, ok := entryPoints[contract]; !ok {
+			contracts = append(contracts, contract)
+		}
+		entryPoints[contract]++
+	}
+	for _, contract := range contracts {
+		fmt.Fprintf(out, "%s: %d entry points\n", contract, entryPoints[contract])
 	}
-	return SPECIFIC_CONTRACT, PRINTER
 }
@@ -35,9 +35,7 @@ func (c *Common) SetParent(parent *Common) {
 // Type Conversion
 func (c *Common) ToBlock() *Block {
 	if c.NodeType == "Block" {
-		return &Block{
-			Common: *c,
-		}
+		return as[Block](c)
 	} else {
 		logger.Fatal.Println("Cannot convert to Block")
 		panic("Cannot convert to Block")
@@ -46,9 +44,7 @@ func (c *Common) ToBlock() *Block {
 
 func (c *Common) ToParameterList() *ParameterList {
 	if c.NodeType == "ParameterList" {
-		return &ParameterList{
-			Common: *c,
-		}
+		return as[ParameterList](c)
 	} else {
 		logger.Fatal.Println("Cannot convert to ParameterList")
 		panic("Cannot convert to ParameterList")
@@ -56,10 +52,9 @@ func (c *Common) ToParameterList() *ParameterList {
 }
 
 func (c *Common) ToStructuredDocumentation() *StructuredDocumentation {
-	if c.NodeType == "Documentation" {
-		return &StructuredDocumentation{
-			Common: *c,
-		}
+	// "Documentation" is not emitted by solc, kept for hand-written ASTs
+	if c.NodeType == "StructuredDocumentation" || c.NodeType == "Documentation" {
+		return as[StructuredDocumentation](c)
 	} else {
 		logger.Fatal.Println("Cannot convert to StructuredDocumentation")
 		panic("Cannot convert to StructuredDocumentation")
@@ -68,9 +63,7 @@ func (c *Common) ToStructuredDocumentation() *StructuredDocumentation {
 
 func (c *Common) ToEnumValue() *EnumValue {
 	if c.NodeType == "EnumValue" {
-		return &EnumValue{
-			Common: *c,
-		}
+		return as[EnumValue](c)
 	} else {
 		logger.Fatal.Println("Cannot convert to EnumValue")
 		panic("Cannot convert to EnumValue")
@@ -79,9 +72,7 @@ func (c *Common) ToEnumValue() *EnumValue {
 
 func (c *Common) ToModifierInvocation() *ModifierInvocation {
 	if c.NodeType == "ModifierInvocation" {
-		return &ModifierInvocation{
-			Common: *c,
-		}
+		return as[ModifierInvocation](c)
 	} else {
 		logger.Fatal.Println("Cannot convert to ModifierInvocation")
 		panic("Cannot convert to ModifierInvocation")
@@ -90,20 +81,16 @@ func (c *Common) ToModifierInvocation() *ModifierInvocation {
 
 func (c *Common) ToOverrideSpecifier() *OverrideSpecifier {
 	if c.NodeType == "OverrideSpecifier" {
-		return &OverrideSpecifier{
-			Common: *c,
-		}
+		return as[OverrideSpecifier](c)
 	} else {
-		logger.Fatal.Println("Cannot convert to ModifierDefinition")
-		panic("Cannot convert to ModifierDefinition")
+		logger.Fatal.Println("Cannot convert to OverrideSpecifier")
+		panic("Cannot convert to OverrideSpecifier")
 	}
 }
 
 func (c *Common) ToTypeDescriptions() *TypeDescriptions {
 	if c.NodeType == "TypeDescriptions" {
-		return &TypeDescriptions{
-			Common: *c,
-		}
+		return as[TypeDescriptions](c)
 	} else {
 		logger.Fatal.Println("Cannot convert to TypeDescriptions")
 		panic("Cannot convert to TypeDescriptions")
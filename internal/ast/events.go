@@ -30,9 +30,8 @@ func (e *EventDefinition) Constructor(data *map[string]interface{}) {
 		e.Anonymous = data
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		e.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		e.Documentation.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		e.Documentation = *node.ToStructuredDocumentation()
 	}
 
 	if data, ok := (*data)["eventSelector"].(string); ok {
@@ -47,8 +46,7 @@ func (e *EventDefinition) Constructor(data *map[string]interface{}) {
 		e.NameLocation = data
 	}
 
-	if data, ok := (*data)["parameters"].(map[string]interface{}); ok {
-		e.Parameters = *NodeFactory(data).ToParameterList()
-		e.Parameters.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["parameters"]); ok {
+		e.Parameters = *node.ToParameterList()
 	}
 }
@@ -27,7 +27,8 @@ func (e *Common) RetrieveVarSymbols() []string {
 			symbols = append(symbols, arg.RetrieveVarSymbols()...)
 		}
 	case "FunctionCallOptions":
-		// do nothing
+		options := (*e).ASTNode.(*FunctionCallOptions)
+		symbols = append(symbols, options.Expression.RetrieveVarSymbols()...)
 	case "Identifier":
 		ident := (*e).ASTNode.(*Identifier)
 		symbols = append(symbols, ident.Name)
@@ -55,6 +56,73 @@ func (e *Common) RetrieveVarSymbols() []string {
 	return symbols
 }
 
+// GetTypeDescriptions returns the type of an expression or a type name, or an
+// empty TypeDescriptions if the node carries none
+func (e *Common) GetTypeDescriptions() TypeDescriptions {
+	if e == nil {
+		return TypeDescriptions{}
+	}
+	switch n := e.ASTNode.(type) {
+	case *Assignment:
+		return n.TypeDescriptions
+	case *BinaryOperation:
+		return n.TypeDescriptions
+	case *Conditional:
+		return n.TypeDescriptions
+	case *ElementaryTypeNameExpression:
+		return n.TypeDescriptions
+	case *FunctionCall:
+		return n.TypeDescriptions
+	case *FunctionCallOptions:
+		return n.TypeDescriptions
+	case *Identifier:
+		return n.TypeDescriptions
+	case *IndexAccess:
+		return n.TypeDescriptions
+	case *IndexRangeAccess:
+		return n.TypeDescriptions
+	case *Literal:
+		return n.TypeDescriptions
+	case *MemberAccess:
+		return n.TypeDescriptions
+	case *NewExpression:
+		return n.TypeDescriptions
+	case *TupleExpression:
+		return n.TypeDescriptions
+	case *UnaryOperation:
+		return n.TypeDescriptions
+	case *ElementaryTypeName:
+		return n.TypeDescriptions
+	case *UserDefinedTypeName:
+		return n.TypeDescriptions
+	case *Mapping:
+		return n.TypeDescriptions
+	case *ArrayTypeName:
+		return n.TypeDescriptions
+	case *FunctionTypeName:
+		return n.TypeDescriptions
+	}
+	return TypeDescriptions{}
+}
+
+// LValues returns the expressions assigned by the left-hand side of an assignment,
+// the components of a tuple one by one, e.g. a, b and c for (a, (b, c)) = f().
+// The components left out, as in (, b) = f(), are skipped.
+func LValues(lhs *Common) []*Common {
+	if lhs == nil {
+		return nil
+	}
+	tuple, ok := lhs.ASTNode.(*TupleExpression)
+	if !ok {
+		return []*Common{lhs}
+	}
+	var res []*Common
+	for _, component := range tuple.Components {
+		res = append(res, LValues(component)...)
+	}
+	return res
+}
+
 type BinaryOperation struct {
 	Common
 	ArgumentTypes    []TypeDescriptions `json:"argumentTypes"` // TypeDescriptions[] | null
@@ -100,8 +168,8 @@ func (b *BinaryOperation) Constructor(data *map[string]interface{}) {
 		b.CommonType.Constructor(&data)
 	}
 
-	if data, ok := (*data)["function"].(int); ok {
-		b.Function = data
+	if data, ok := (*data)["function"].(float64); ok {
+		b.Function = int(data)
 	}
 
 	if data, ok := (*data)["isConstant"].(bool); ok {
@@ -120,18 +188,16 @@ func (b *BinaryOperation) Constructor(data *map[string]interface{}) {
 		b.LValueRequested = data
 	}
 
-	if data, ok := (*data)["leftExpression"].(map[string]interface{}); ok {
-		b.LeftExpression = NodeFactory(data)
-		b.LeftExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["leftExpression"]); ok {
+		b.LeftExpression = node
 	}
 
 	if data, ok := (*data)["operator"].(string); ok {
 		b.Operator = Operator(data)
 	}
 
-	if data, ok := (*data)["rightExpression"].(map[string]interface{}); ok {
-		b.RightExpression = NodeFactory(data)
-		b.RightExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["rightExpression"]); ok {
+		b.RightExpression = node
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -182,8 +248,8 @@ func (i *Identifier) Constructor(data *map[string]interface{}) {
 		}
 	}
 
-	if data, ok := (*data)["referencedDeclaration"].(int); ok {
-		i.ReferencedDeclaration = data
+	if data, ok := (*data)["referencedDeclaration"].(float64); ok {
+		i.ReferencedDeclaration = int(data)
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -317,18 +383,16 @@ func (a *Assignment) Constructor(data *map[string]interface{}) {
 		a.LValueRequested = data
 	}
 
-	if data, ok := (*data)["leftHandSide"].(map[string]interface{}); ok {
-		a.LeftHandSide = NodeFactory(data)
-		a.LeftHandSide.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["leftHandSide"]); ok {
+		a.LeftHandSide = node
 	}
 
 	if data, ok := (*data)["operator"].(string); ok {
 		a.Operator = AssignmentOperator(data)
 	}
 
-	if data, ok := (*data)["rightHandSide"].(map[string]interface{}); ok {
-		a.RightHandSide = NodeFactory(data)
-		a.RightHandSide.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["rightHandSide"]); ok {
+		a.RightHandSide = node
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -375,9 +439,8 @@ func (m *MemberAccess) Constructor(data *map[string]interface{}) {
 		}
 	}
 
-	if data, ok := (*data)["expression"].(map[string]interface{}); ok {
-		m.Expression = NodeFactory(data)
-		m.Expression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["expression"]); ok {
+		m.Expression = node
 	}
 
 	if data, ok := (*data)["isConstant"].(bool); ok {
@@ -404,8 +467,8 @@ func (m *MemberAccess) Constructor(data *map[string]interface{}) {
 		m.MemberName = data
 	}
 
-	if data, ok := (*data)["referencedDeclaration"].(int); ok {
-		m.ReferencedDeclaration = data
+	if data, ok := (*data)["referencedDeclaration"].(float64); ok {
+		m.ReferencedDeclaration = int(data)
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -448,14 +511,12 @@ func (i *IndexAccess) Constructor(data *map[string]interface{}) {
 		}
 	}
 
-	if data, ok := (*data)["baseExpression"].(map[string]interface{}); ok {
-		i.BaseExpression = NodeFactory(data)
-		i.BaseExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["baseExpression"]); ok {
+		i.BaseExpression = node
 	}
 
-	if data, ok := (*data)["indexExpression"].(map[string]interface{}); ok {
-		i.IndexExpression = NodeFactory(data)
-		i.IndexExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["indexExpression"]); ok {
+		i.IndexExpression = node
 	}
 
 	if data, ok := (*data)["isConstant"].(bool); ok {
@@ -533,16 +594,18 @@ func (e *ElementaryTypeNameExpression) Constructor(data *map[string]interface{})
 		e.TypeDescriptions.Constructor(&data)
 	}
 
-	if data, ok := (*data)["typeName"].(map[string]interface{}); ok {
-		e.TypeName = ElementaryTypeName{}
-		e.TypeName.Constructor(&data)
+	// the name of the type before solc 0.6, e.g. "address"
+	if name, ok := (*data)["typeName"].(string); ok {
+		e.TypeName = ElementaryTypeName{Name: name}
+	} else if node, ok := nodeOf((*data)["typeName"]); ok {
+		e.TypeName = *as[ElementaryTypeName](node)
 	}
 }
 
 type TupleExpression struct {
 	Common
 	ArgumentTypes    []TypeDescriptions `json:"argumentTypes"` // TypeDescriptions[] | null
-	Components       []Expression       `json:"components"`
+	Components       []Expression       `json:"components"`    // nil for a component left out
 	IsConstant       bool               `json:"isConstant"`
 	IsInlineArray    bool               `json:"isInlineArray"`
 	IsLValue         bool               `json:"isLValue"`
@@ -576,9 +639,8 @@ func (t *TupleExpression) Constructor(data *map[string]interface{}) {
 
 	if data, ok := (*data)["components"].([]interface{}); ok {
 		for _, v := range data {
-			v := v.(map[string]interface{})
-			expr := NodeFactory(v)
-			expr.ASTNode.Constructor(&v)
+			// (ok, ) = ... leaves a component out, nil
+			expr, _ := nodeOf(v)
 			t.Components = append(t.Components, expr)
 		}
 	}
@@ -661,8 +723,157 @@ func (n *NewExpression) Constructor(data *map[string]interface{}) {
 		n.TypeDescriptions.Constructor(&data)
 	}
 
-	if data, ok := (*data)["typeName"].(map[string]interface{}); ok {
-		n.TypeName = NodeFactory(data)
-		n.TypeName.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["typeName"]); ok {
+		n.TypeName = node
+	}
+}
+
+// FunctionCallOptions is the `{value: v, gas: g}` part of a call of solc >= 0.6.2,
+// e.g. the expression of the FunctionCall addr.call{value: v}("")
+type FunctionCallOptions struct {
+	Common
+	ArgumentTypes    []TypeDescriptions `json:"argumentTypes"` // TypeDescriptions[] | null
+	Expression       Expression         `json:"expression"`
+	IsConstant       bool               `json:"isConstant"`
+	IsLValue         bool               `json:"isLValue"`
+	IsPure           bool               `json:"isPure"`
+	LValueRequested  bool               `json:"lValueRequested"`
+	Names            []string           `json:"names"`   // e.g. ["value", "gas"]
+	Options          []Expression       `json:"options"` // the values, in the order of Names
+	TypeDescriptions TypeDescriptions   `json:"typeDescriptions"`
+}
+
+func (f *FunctionCallOptions) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"ArgumentTypes":    f.ArgumentTypes,
+		"Expression":       f.Expression,
+		"IsConstant":       f.IsConstant,
+		"IsLValue":         f.IsLValue,
+		"IsPure":           f.IsPure,
+		"LValueRequested":  f.LValueRequested,
+		"Names":            f.Names,
+		"Options":          f.Options,
+		"TypeDescriptions": f.TypeDescriptions,
+	}
+}
+
+func (f *FunctionCallOptions) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["argumentTypes"].([]interface{}); ok {
+		for _, v := range data {
+			v := v.(map[string]interface{})
+			td := TypeDescriptions{}
+			td.Constructor(&v)
+			f.ArgumentTypes = append(f.ArgumentTypes, td)
+		}
+	}
+
+	if node, ok := nodeOf((*data)["expression"]); ok {
+		f.Expression = node
+	}
+
+	if data, ok := (*data)["isConstant"].(bool); ok {
+		f.IsConstant = data
+	}
+
+	if data, ok := (*data)["isLValue"].(bool); ok {
+		f.IsLValue = data
+	}
+
+	if data, ok := (*data)["isPure"].(bool); ok {
+		f.IsPure = data
+	}
+
+	if data, ok := (*data)["lValueRequested"].(bool); ok {
+		f.LValueRequested = data
+	}
+
+	if data, ok := (*data)["names"].([]interface{}); ok {
+		for _, v := range data {
+			if v, ok := v.(string); ok {
+				f.Names = append(f.Names, v)
+			}
+		}
+	}
+
+	if data, ok := (*data)["options"].([]interface{}); ok {
+		for _, v := range data {
+			expr, _ := nodeOf(v)
+			f.Options = append(f.Options, expr)
+		}
+	}
+
+	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
+		f.TypeDescriptions.Constructor(&data)
+	}
+}
+
+// IndexRangeAccess is a slice of a calldata array of solc >= 0.6, e.g. data[4:]
+type IndexRangeAccess struct {
+	Common
+	ArgumentTypes    []TypeDescriptions `json:"argumentTypes"` // TypeDescriptions[] | null
+	BaseExpression   Expression         `json:"baseExpression"`
+	EndExpression    Expression         `json:"endExpression"` // Expression | null
+	IsConstant       bool               `json:"isConstant"`
+	IsLValue         bool               `json:"isLValue"`
+	IsPure           bool               `json:"isPure"`
+	LValueRequested  bool               `json:"lValueRequested"`
+	StartExpression  Expression         `json:"startExpression"` // Expression | null
+	TypeDescriptions TypeDescriptions   `json:"typeDescriptions"`
+}
+
+func (i *IndexRangeAccess) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"ArgumentTypes":    i.ArgumentTypes,
+		"BaseExpression":   i.BaseExpression,
+		"EndExpression":    i.EndExpression,
+		"IsConstant":       i.IsConstant,
+		"IsLValue":         i.IsLValue,
+		"IsPure":           i.IsPure,
+		"LValueRequested":  i.LValueRequested,
+		"StartExpression":  i.StartExpression,
+		"TypeDescriptions": i.TypeDescriptions,
+	}
+}
+
+func (i *IndexRangeAccess) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["argumentTypes"].([]interface{}); ok {
+		for _, v := range data {
+			v := v.(map[string]interface{})
+			td := TypeDescriptions{}
+			td.Constructor(&v)
+			i.ArgumentTypes = append(i.ArgumentTypes, td)
+		}
+	}
+
+	if node, ok := nodeOf((*data)["baseExpression"]); ok {
+		i.BaseExpression = node
+	}
+
+	if node, ok := nodeOf((*data)["endExpression"]); ok {
+		i.EndExpression = node
+	}
+
+	if data, ok := (*data)["isConstant"].(bool); ok {
+		i.IsConstant = data
+	}
+
+	if data, ok := (*data)["isLValue"].(bool); ok {
+		i.IsLValue = data
+	}
+
+	if data, ok := (*data)["isPure"].(bool); ok {
+		i.IsPure = data
+	}
+
+	if data, ok := (*data)["lValueRequested"].(bool); ok {
+		i.LValueRequested = data
+	}
+
+	if node, ok := nodeOf((*data)["startExpression"]); ok {
+		i.StartExpression = node
+	}
+
+	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
+		i.TypeDescriptions.Constructor(&data)
 	}
 }
@@ -1,22 +1,79 @@
 package ast
 
 import (
+	"strings"
 	"txtracker/internal/logger"
 )
 
+// NewNode builds the node of a JSON object, a Yul node with YulNodeFactory. Its
+// fields may hold nodes already built, as the parser builds the nodes of the JSON
+// as it reads them, or JSON objects, built here.
+func NewNode(data map[string]interface{}) *Common {
+	var node *Common
+	if nodeType, _ := data["nodeType"].(string); strings.HasPrefix(nodeType, "Yul") {
+		node = YulNodeFactory(data)
+	} else {
+		node = NodeFactory(data)
+	}
+	node.ASTNode.Constructor(&data)
+	return node
+}
+
+// nodeOf returns the node of a field, false for null or a missing field
+func nodeOf(value interface{}) (*Common, bool) {
+	switch value := value.(type) {
+	case *Common:
+		return value, true
+	case map[string]interface{}:
+		return NewNode(value), true
+	}
+	return nil, false
+}
+
+// as returns the struct of a node, e.g. the Block of a function body, with the
+// common fields of the node. The struct is empty if the node is of another type.
+func as[T any, P interface {
+	*T
+	Instance() *Common
+}](c *Common) *T {
+	res := new(T)
+	if node, ok := c.ASTNode.(P); ok {
+		*res = *node
+	}
+	*P(res).Instance() = *c
+	return res
+}
+
+// NodeFactory returns the node of a JSON object with its common fields, the
+// caller then calls its Constructor. A node type without a struct gives a
+// GenericNode; an object without nodeType, src or id panics with a *NodeError.
 func NodeFactory(data map[string]interface{}) *Common {
 	common, nodeType := commonFactory(data)
-	common.ASTNode = astNodes[nodeType]()
+	if newNode, ok := astNodes[nodeType]; ok {
+		common.ASTNode = newNode()
+	} else {
+		logger.Warning.Println("Unknown node type", nodeType+":", common.Src)
+		common.ASTNode = &GenericNode{}
+	}
 	return common
 }
 
 var astNodes = map[string]func() ASTNode{
-	"SourceUnit":          func() ASTNode { return &SourceUnit{} },
-	"ContractDefinition":  func() ASTNode { return &ContractDefinition{} },
-	"PragmaDirective":     func() ASTNode { return &PragmaDirective{} },
-	"FunctionDefinition":  func() ASTNode { return &FunctionDefinition{} },
-	"VariableDeclaration": func() ASTNode { return &VariableDeclaration{} },
-	"UsingForDirective":   func() ASTNode { return &UsingForDirective{} },
+	"SourceUnit":                     func() ASTNode { return &SourceUnit{} },
+	"ContractDefinition":             func() ASTNode { return &ContractDefinition{} },
+	"PragmaDirective":                func() ASTNode { return &PragmaDirective{} },
+	"ImportDirective":                func() ASTNode { return &ImportDirective{} },
+	"FunctionDefinition":             func() ASTNode { return &FunctionDefinition{} },
+	"VariableDeclaration":            func() ASTNode { return &VariableDeclaration{} },
+	"UsingForDirective":              func() ASTNode { return &UsingForDirective{} },
+	"ErrorDefinition":                func() ASTNode { return &ErrorDefinition{} },
+	"UserDefinedValueTypeDefinition": func() ASTNode { return &UserDefinedValueTypeDefinition{} },
+
+	// Inline nodes of the top-level nodes
+	"InheritanceSpecifier":    func() ASTNode { return &InheritanceSpecifier{} },
+	"StructuredDocumentation": func() ASTNode { return &StructuredDocumentation{} },
+	"IdentifierPath":          func() ASTNode { return &IdentifierPath{} },
+	"OverrideSpecifier":       func() ASTNode { return &OverrideSpecifier{} },
 
 	// Conditional
 	"Conditional": func() ASTNode { return &Conditional{} },
@@ -40,6 +97,7 @@ var astNodes = map[string]func() ASTNode{
 
 	// Statements
 	"Block":                        func() ASTNode { return &Block{} },
+	"UncheckedBlock":               func() ASTNode { return &UncheckedBlock{} },
 	"IfStatement":                  func() ASTNode { return &IfStatement{} },
 	"Return":                       func() ASTNode { return &Return{} },
 	"VariableDeclarationStatement": func() ASTNode { return &VariableDeclarationStatement{} },
@@ -47,6 +105,15 @@ var astNodes = map[string]func() ASTNode{
 	"PlaceholderStatement":         func() ASTNode { return &PlaceholderStatement{} },
 	"ForStatement":                 func() ASTNode { return &ForStatement{} },
 	"Break":                        func() ASTNode { return &Break{} },
+	"Continue":                     func() ASTNode { return &Continue{} },
+	"WhileStatement":               func() ASTNode { return &WhileStatement{} },
+	"DoWhileStatement":             func() ASTNode { return &DoWhileStatement{} },
+	"Throw":                        func() ASTNode { return &Throw{} },
+	"RevertStatement":              func() ASTNode { return &RevertStatement{} },
+	"TryStatement":                 func() ASTNode { return &TryStatement{} },
+	"TryCatchClause":               func() ASTNode { return &TryCatchClause{} },
+	"EmitStatement":                func() ASTNode { return &EmitStatement{} },
+	"InlineAssembly":               func() ASTNode { return &InlineAssembly{} },
 
 	// Expressions
 	"BinaryOperation":              func() ASTNode { return &BinaryOperation{} },
@@ -59,6 +126,8 @@ var astNodes = map[string]func() ASTNode{
 	"ElementaryTypeNameExpression": func() ASTNode { return &ElementaryTypeNameExpression{} },
 	"TupleExpression":              func() ASTNode { return &TupleExpression{} },
 	"NewExpression":                func() ASTNode { return &NewExpression{} },
+	"FunctionCallOptions":          func() ASTNode { return &FunctionCallOptions{} },
+	"IndexRangeAccess":             func() ASTNode { return &IndexRangeAccess{} },
 
 	// TypeNames
 	"ElementaryTypeName":  func() ASTNode { return &ElementaryTypeName{} },
@@ -66,28 +135,26 @@ var astNodes = map[string]func() ASTNode{
 	"Mapping":             func() ASTNode { return &Mapping{} },
 	"StructDefinition":    func() ASTNode { return &StructDefinition{} },
 	"ArrayTypeName":       func() ASTNode { return &ArrayTypeName{} },
+	"FunctionTypeName":    func() ASTNode { return &FunctionTypeName{} },
 }
 
 func commonFactory(data map[string]interface{}) (*Common, string) {
 	nodeType, ok := data["nodeType"].(string)
 	if !ok || nodeType == "" {
-		logger.Fatal.Println("nodeType not found")
-		panic("nodeType not found")
+		panic(&NodeError{Field: "nodeType", Node: data})
 	}
-	src := data["src"].(string)
+	src, ok := data["src"].(string)
 	if !ok || src == "" {
-		logger.Fatal.Println("src not found")
-		panic("src not found")
+		panic(&NodeError{Field: "src", Node: data})
 	}
-	id := int(data["id"].(float64))
-	if !ok || id == 0 {
-		logger.Fatal.Println("id not found or id is 0")
-		panic("id not found or id is 0")
+	id, ok := data["id"].(float64)
+	if !ok {
+		panic(&NodeError{Field: "id", Node: data})
 	}
 	common := &Common{
 		NodeType: nodeType,
 		Src:      src,
-		ID:       id,
+		ID:       int(id),
 	}
 	return common, nodeType
 }
@@ -0,0 +1,33 @@
+package ast
+
+import "fmt"
+
+// GenericNode is a node of a type NodeFactory does not know, e.g. from a newer
+// solc. It keeps the JSON object, so that a pass may skip it with a warning or
+// still read its fields.
+type GenericNode struct {
+	Common
+	Data map[string]interface{}
+}
+
+func (g *GenericNode) Attributes() *map[string]interface{} {
+	return &g.Data
+}
+
+func (g *GenericNode) Constructor(data *map[string]interface{}) {
+	g.Data = *data
+}
+
+// NodeError is a JSON object found where a node is expected that is not one,
+// e.g. without nodeType. NodeFactory panics with it, the parser recovers it.
+type NodeError struct {
+	Field string // the missing or invalid field, e.g. "nodeType"
+	Node  map[string]interface{}
+}
+
+func (e *NodeError) Error() string {
+	if nodeType, ok := e.Node["nodeType"].(string); ok {
+		return fmt.Sprintf("%s node without a valid %s", nodeType, e.Field)
+	}
+	return fmt.Sprintf("node without a valid %s", e.Field)
+}
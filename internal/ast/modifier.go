@@ -30,18 +30,18 @@ func (m *ModifierDefinition) Attributes() *map[string]interface{} {
 }
 
 func (m *ModifierDefinition) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["baseModifiers"].([]int); ok {
-		m.BaseModifiers = data
+	if data, ok := (*data)["baseModifiers"].([]interface{}); ok {
+		for _, v := range data {
+			m.BaseModifiers = append(m.BaseModifiers, int(v.(float64)))
+		}
 	}
 
-	if data, ok := (*data)["body"].(map[string]interface{}); ok {
-		m.Body = *NodeFactory(data).ToBlock()
-		m.Body.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["body"]); ok {
+		m.Body = *node.ToBlock()
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		m.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		m.Documentation.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		m.Documentation = *node.ToStructuredDocumentation()
 	}
 
 	if data, ok := (*data)["name"].(string); ok {
@@ -52,14 +52,12 @@ func (m *ModifierDefinition) Constructor(data *map[string]interface{}) {
 		m.NameLocation = data
 	}
 
-	if data, ok := (*data)["overrides"].(map[string]interface{}); ok {
-		m.Overrides = *NodeFactory(data).ToOverrideSpecifier()
-		m.Overrides.Constructor(&data)
+	if node, ok := nodeOf((*data)["overrides"]); ok {
+		m.Overrides = *node.ToOverrideSpecifier()
 	}
 
-	if data, ok := (*data)["parameters"].(map[string]interface{}); ok {
-		m.Parameters = *NodeFactory(data).ToParameterList()
-		m.Parameters.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["parameters"]); ok {
+		m.Parameters = *node.ToParameterList()
 	}
 
 	if data, ok := (*data)["virtual"].(bool); ok {
@@ -21,14 +21,12 @@ func (i *InheritanceSpecifier) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["arguments"].([]interface{}); ok {
 		i.Arguments = make([]Expression, len(data))
 		for cnt, v := range data {
-			v := v.(map[string]interface{})
-			i.Arguments[cnt].ASTNode.Constructor(&v)
+			i.Arguments[cnt], _ = nodeOf(v)
 		}
 	}
 
-	if data, ok := (*data)["baseName"].(map[string]interface{}); ok {
-		i.BaseName = NodeFactory(data)
-		i.BaseName.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["baseName"]); ok {
+		i.BaseName = node
 	}
 }
 
@@ -77,12 +75,12 @@ func (u *UserDefinedTypeName) Constructor(data *map[string]interface{}) {
 		u.Name = data
 	}
 
-	if data, ok := (*data)["pathNode"].(map[string]interface{}); ok {
-		u.PathNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["pathNode"]); ok {
+		u.PathNode = *as[IdentifierPath](node)
 	}
 
-	if data, ok := (*data)["referenceDeclaration"].(int); ok {
-		u.ReferenceDeclaration = data
+	if data, ok := (*data)["referencedDeclaration"].(float64); ok {
+		u.ReferenceDeclaration = int(data)
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -97,7 +95,7 @@ func (u *UserDefinedTypeName) DescribeOverrideSpecifier() string {
 type IdentifierPath struct {
 	Common
 	Name                  string        `json:"name"`
-	NameLocations         NameLocations `json:"nameLocation"` // string[] | null
+	NameLocations         NameLocations `json:"nameLocations"` // string[] | null
 	ReferencedDeclaration int           `json:"referencedDeclaration"`
 }
 
@@ -114,13 +112,18 @@ func (i *IdentifierPath) Constructor(data *map[string]interface{}) {
 		i.Name = data
 	}
 
-	if data, ok := (*data)["nameLocation"].([]string); ok {
-		i.NameLocations = make(NameLocations, len(data))
-		i.NameLocations.Constructor(&data)
+	if data, ok := (*data)["nameLocations"].([]interface{}); ok {
+		locations := make([]string, 0, len(data))
+		for _, v := range data {
+			if v, ok := v.(string); ok {
+				locations = append(locations, v)
+			}
+		}
+		i.NameLocations.Constructor(&locations)
 	}
 
-	if data, ok := (*data)["referencedDeclaration"].(int); ok {
-		i.ReferencedDeclaration = data
+	if data, ok := (*data)["referencedDeclaration"].(float64); ok {
+		i.ReferencedDeclaration = int(data)
 	}
 }
 
@@ -188,8 +191,9 @@ func (p *ParameterList) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["parameters"].([]interface{}); ok {
 		p.Parameters = make([]VariableDeclaration, len(data))
 		for cnt, v := range data {
-			v := v.(map[string]interface{})
-			p.Parameters[cnt].Constructor(&v)
+			// the identifiers of the body refer to the parameters by their ID
+			node, _ := nodeOf(v)
+			p.Parameters[cnt] = *as[VariableDeclaration](node)
 		}
 	}
 }
@@ -217,9 +221,7 @@ func (o *OverrideSpecifier) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["overrides"].([]interface{}); ok {
 		//o.Overrides = make([]OverrideSpecifier, len(data))
 		for _, v := range data {
-			v := v.(map[string]interface{})
-			override := NodeFactory(v)
-			override.ASTNode.Constructor(&v)
+			override, _ := nodeOf(v)
 			o.Overrides = append(o.Overrides, override)
 		}
 	}
@@ -253,16 +255,15 @@ func (s *StructDefinition) Constructor(data *map[string]interface{}) {
 		s.CanonicaName = data
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		s.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		s.Documentation.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		s.Documentation = *node.ToStructuredDocumentation()
 	}
 
 	if data, ok := (*data)["members"].([]interface{}); ok {
 		s.Members = make([]VariableDeclaration, len(data))
 		for cnt, v := range data {
-			v := v.(map[string]interface{})
-			s.Members[cnt].Constructor(&v)
+			node, _ := nodeOf(v)
+			s.Members[cnt] = *as[VariableDeclaration](node)
 		}
 	}
 
@@ -274,8 +275,8 @@ func (s *StructDefinition) Constructor(data *map[string]interface{}) {
 		s.NameLocation = data
 	}
 
-	if data, ok := (*data)["scope"].(int); ok {
-		s.Scope = data
+	if data, ok := (*data)["scope"].(float64); ok {
+		s.Scope = int(data)
 	}
 
 	if data, ok := (*data)["visibility"].(string); ok {
@@ -319,13 +320,12 @@ func (u *UsingForDirective) Constructor(data *map[string]interface{}) {
 		u.Global = data
 	}
 
-	if data, ok := (*data)["libraryName"].(map[string]interface{}); ok {
-		u.LibraryName.Constructor(&data)
+	if node, ok := nodeOf((*data)["libraryName"]); ok {
+		u.LibraryName = *as[IdentifierPath](node)
 	}
 
-	if data, ok := (*data)["typeName"].(map[string]interface{}); ok {
-		u.TypeName = NodeFactory(data)
-		u.TypeName.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["typeName"]); ok {
+		u.TypeName = node
 	}
 }
 
@@ -365,14 +365,12 @@ func (c *Conditional) Constructor(data *map[string]interface{}) {
 		}
 	}
 
-	if data, ok := (*data)["condition"].(map[string]interface{}); ok {
-		c.Condition = NodeFactory(data)
-		c.Condition.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["condition"]); ok {
+		c.Condition = node
 	}
 
-	if data, ok := (*data)["falseExpression"].(map[string]interface{}); ok {
-		c.FalseExpression = NodeFactory(data)
-		c.FalseExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["falseExpression"]); ok {
+		c.FalseExpression = node
 	}
 
 	if data, ok := (*data)["isConstant"].(bool); ok {
@@ -391,9 +389,8 @@ func (c *Conditional) Constructor(data *map[string]interface{}) {
 		c.LValueRequested = data
 	}
 
-	if data, ok := (*data)["trueExpression"].(map[string]interface{}); ok {
-		c.TrueExpression = NodeFactory(data)
-		c.TrueExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["trueExpression"]); ok {
+		c.TrueExpression = node
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -1,5 +1,7 @@
 package ast
 
+import "strings"
+
 // SourceUnit's nodes are follows:
 //
 // (ContractDefinition | EnumDefinition | ErrorDefinition | FunctionDefinition | ImportDirective | PragmaDirective | StructDefinition | UserDefinedValueTypeDefinition | UsingForDirective | VariableDeclaration)[]
@@ -31,7 +33,7 @@ func (s *SourceUnit) Constructor(data *map[string]interface{}) {
 		s.ExperimentalSolidity = false
 	}
 
-	if data, ok := (*data)["exportedSymbols"].(map[string][]float64); ok {
+	if data, ok := (*data)["exportedSymbols"].(map[string]interface{}); ok {
 		s.ExportedSymbols = make(ExportedSymbols)
 		s.ExportedSymbols.Constructor(&data)
 	}
@@ -87,8 +89,8 @@ func (c *ContractDefinition) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["baseContracts"].([]interface{}); ok {
 		c.BaseContracts = make([]InheritanceSpecifier, len(data))
 		for i, v := range data {
-			v := v.(map[string]interface{})
-			c.BaseContracts[i].Constructor(&v)
+			node, _ := nodeOf(v)
+			c.BaseContracts[i] = *as[InheritanceSpecifier](node)
 		}
 	}
 
@@ -96,8 +98,7 @@ func (c *ContractDefinition) Constructor(data *map[string]interface{}) {
 		c.CanonicaName = data
 	}
 
-	if data, ok := (*data)["contractDependencies"].([]float64); ok {
-		c.ContractDependencies = make(ContractDependencies, len(data))
+	if data, ok := (*data)["contractDependencies"].([]interface{}); ok {
 		c.ContractDependencies.Constructor(&data)
 	}
 
@@ -105,22 +106,20 @@ func (c *ContractDefinition) Constructor(data *map[string]interface{}) {
 		c.ContractKind = ContractKind(data)
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		c.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		c.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		c.Documentation = *node.ToStructuredDocumentation()
 	}
 
 	if data, ok := (*data)["fullyImplemented"].(bool); ok {
 		c.FullyImplemented = data
 	}
 
-	if data, ok := (*data)["internalFunctionIDs"].(map[string]float64); ok {
+	if data, ok := (*data)["internalFunctionIDs"].(map[string]interface{}); ok {
 		c.InternalFunctionIDs = make(InternalFunctionIDs)
 		c.InternalFunctionIDs.Constructor(&data)
 	}
 
-	if data, ok := (*data)["linearizedBaseContracts"].([]float64); ok {
-		c.LinearizedBaseContracts = make(LinearizedBaseContracts, len(data))
+	if data, ok := (*data)["linearizedBaseContracts"].([]interface{}); ok {
 		c.LinearizedBaseContracts.Constructor(&data)
 	}
 
@@ -128,22 +127,21 @@ func (c *ContractDefinition) Constructor(data *map[string]interface{}) {
 		c.Name = data
 	}
 
-	if data, ok := (*data)["scope"].(int); ok {
-		c.Scope = data
+	if data, ok := (*data)["scope"].(float64); ok {
+		c.Scope = int(data)
 	}
 
-	if data, ok := (*data)["usedErrors"].([]float64); ok {
-		c.UsedErrors = make(UsedErrors, len(data))
+	if data, ok := (*data)["usedErrors"].([]interface{}); ok {
 		c.UsedErrors.Constructor(&data)
 	}
 
-	if data, ok := (*data)["usedEvents"].([]float64); ok {
-		c.UsedEvents = make(UsedEvents, len(data))
+	if data, ok := (*data)["usedEvents"].([]interface{}); ok {
 		c.UsedEvents.Constructor(&data)
 	}
 }
 
 type EnumDefinition struct {
+	Common
 	CanonicaName  string                  `json:"canonicalName"`
 	Documentation StructuredDocumentation `json:"documentation"`
 	Members       []EnumValue             `json:"members"`
@@ -165,16 +163,14 @@ func (e *EnumDefinition) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["canonicalName"].(string); ok {
 		e.CanonicaName = data
 	}
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		e.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		e.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		e.Documentation = *node.ToStructuredDocumentation()
 	}
 	if data, ok := (*data)["members"].([]interface{}); ok {
 		//e.Members = make([]EnumValue, len(data))
 		for _, v := range data {
-			v := v.(map[string]interface{})
-			member := NodeFactory(v).ToEnumValue()
-			member.Constructor(&v)
+			node, _ := nodeOf(v)
+			member := node.ToEnumValue()
 			e.Members = append(e.Members, *member)
 		}
 	}
@@ -187,6 +183,7 @@ func (e *EnumDefinition) Constructor(data *map[string]interface{}) {
 }
 
 type ErrorDefinition struct {
+	Common
 	Documentation StructuredDocumentation `json:"documentation"` // StructuredDocumentation | null
 	ErrorSelector string                  `json:"errorSelector"` // string | null
 	Name          string                  `json:"name"`
@@ -205,9 +202,8 @@ func (e *ErrorDefinition) Attributes() *map[string]interface{} {
 }
 
 func (e *ErrorDefinition) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		e.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		e.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		e.Documentation = *node.ToStructuredDocumentation()
 	}
 	if data, ok := (*data)["errorSelector"].(string); ok {
 		e.ErrorSelector = data
@@ -221,9 +217,117 @@ func (e *ErrorDefinition) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["nameLocation"].(string); ok {
 		e.NameLocation = data
 	}
-	if data, ok := (*data)["parameters"].(map[string]interface{}); ok {
-		e.Parameters = *NodeFactory(data).ToParameterList()
-		e.Parameters.Constructor(&data)
+	if node, ok := nodeOf((*data)["parameters"]); ok {
+		e.Parameters = *node.ToParameterList()
+	}
+}
+
+// SymbolAlias is a symbol imported by name, e.g. B as C in import {B as C} from "./B.sol"
+type SymbolAlias struct {
+	Foreign      Identifier `json:"foreign"`
+	Local        string     `json:"local"` // string | null
+	NameLocation string     `json:"nameLocation"`
+}
+
+type ImportDirective struct {
+	Common
+	AbsolutePath  string        `json:"absolutePath"`
+	File          string        `json:"file"` // as written in the source
+	NameLocation  string        `json:"nameLocation"`
+	Scope         int           `json:"scope"`
+	SourceUnit    int           `json:"sourceUnit"` // ID of the imported SourceUnit
+	SymbolAliases []SymbolAlias `json:"symbolAliases"`
+	UnitAlias     string        `json:"unitAlias"` // "" unless import "./B.sol" as B
+}
+
+func (i *ImportDirective) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"AbsolutePath":  i.AbsolutePath,
+		"File":          i.File,
+		"NameLocation":  i.NameLocation,
+		"Scope":         i.Scope,
+		"SourceUnit":    i.SourceUnit,
+		"SymbolAliases": i.SymbolAliases,
+		"UnitAlias":     i.UnitAlias,
+	}
+}
+
+func (i *ImportDirective) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["absolutePath"].(string); ok {
+		i.AbsolutePath = data
+	}
+
+	if data, ok := (*data)["file"].(string); ok {
+		i.File = data
+	}
+
+	if data, ok := (*data)["nameLocation"].(string); ok {
+		i.NameLocation = data
+	}
+
+	if data, ok := (*data)["scope"].(float64); ok {
+		i.Scope = int(data)
+	}
+
+	if data, ok := (*data)["sourceUnit"].(float64); ok {
+		i.SourceUnit = int(data)
+	}
+
+	if data, ok := (*data)["symbolAliases"].([]interface{}); ok {
+		for _, v := range data {
+			v := v.(map[string]interface{})
+			var alias SymbolAlias
+			if node, ok := nodeOf(v["foreign"]); ok {
+				alias.Foreign = *as[Identifier](node)
+			}
+			if local, ok := v["local"].(string); ok {
+				alias.Local = local
+			}
+			if nameLocation, ok := v["nameLocation"].(string); ok {
+				alias.NameLocation = nameLocation
+			}
+			i.SymbolAliases = append(i.SymbolAliases, alias)
+		}
+	}
+
+	if data, ok := (*data)["unitAlias"].(string); ok {
+		i.UnitAlias = data
+	}
+}
+
+// UserDefinedValueTypeDefinition is a `type T is uint256;` of solc >= 0.8.8
+type UserDefinedValueTypeDefinition struct {
+	Common
+	CanonicalName  string   `json:"canonicalName"` // string | null
+	Name           string   `json:"name"`
+	NameLocation   string   `json:"nameLocation"`
+	UnderlyingType TypeName `json:"underlyingType"` // ElementaryTypeName
+}
+
+func (u *UserDefinedValueTypeDefinition) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"CanonicalName":  u.CanonicalName,
+		"Name":           u.Name,
+		"NameLocation":   u.NameLocation,
+		"UnderlyingType": u.UnderlyingType,
+	}
+}
+
+func (u *UserDefinedValueTypeDefinition) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["canonicalName"].(string); ok {
+		u.CanonicalName = data
+	}
+
+	if data, ok := (*data)["name"].(string); ok {
+		u.Name = data
+	}
+
+	if data, ok := (*data)["nameLocation"].(string); ok {
+		u.NameLocation = data
+	}
+
+	if node, ok := nodeOf((*data)["underlyingType"]); ok {
+		u.UnderlyingType = node
 	}
 }
 
@@ -239,14 +343,44 @@ func (p *PragmaDirective) Attributes() *map[string]interface{} {
 }
 
 func (p *PragmaDirective) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["literals"].([]string); ok {
-		p.Literals = make(Literals, len(data))
-		p.Literals.Constructor(&data)
+	if data, ok := (*data)["literals"].([]interface{}); ok {
+		literals := make([]string, 0, len(data))
+		for _, v := range data {
+			if v, ok := v.(string); ok {
+				literals = append(literals, v)
+			}
+		}
+		p.Literals.Constructor(&literals)
+	}
+}
+
+// VersionRange returns the range of a `pragma solidity` directive as written,
+// e.g. ">=0.4.22 <0.9.0" for the literals [solidity >= 0.4 .22 < 0.9 .0].
+// ok is false for the other pragmas, e.g. `pragma experimental ABIEncoderV2`.
+func (p *PragmaDirective) VersionRange() (string, bool) {
+	if len(p.Literals) < 2 || p.Literals[0] != "solidity" {
+		return "", false
+	}
+	var b strings.Builder
+	glued := true // the next literal follows an operator
+	for _, literal := range p.Literals[1:] {
+		if !glued && !strings.HasPrefix(literal, ".") {
+			b.WriteString(" ")
+		}
+		b.WriteString(literal)
+		switch literal {
+		case "^", "~", ">=", "<=", ">", "<", "=":
+			glued = true
+		default:
+			glued = false
+		}
 	}
+	return b.String(), true
 }
 
 type FunctionDefinition struct {
 	Common
+	BaseFunctions    BaseFunctions           `json:"baseFunctions"`    // int[] | null
 	Body             Block                   `json:"body"`             // Block | null
 	Documentation    StructuredDocumentation `json:"documentation"`    // StructuredDocumentation | null
 	FunctionSelector string                  `json:"functionSelector"` // string | null
@@ -266,6 +400,7 @@ type FunctionDefinition struct {
 
 func (f *FunctionDefinition) Attributes() *map[string]interface{} {
 	return &map[string]interface{}{
+		"BaseFunctions":    f.BaseFunctions,
 		"Body":             f.Body,
 		"Documentation":    f.Documentation,
 		"FunctionSelector": f.FunctionSelector,
@@ -285,14 +420,16 @@ func (f *FunctionDefinition) Attributes() *map[string]interface{} {
 }
 
 func (f *FunctionDefinition) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["body"].(map[string]interface{}); ok {
-		f.Body = *NodeFactory(data).ToBlock()
-		f.Body.Constructor(&data)
+	if data, ok := (*data)["baseFunctions"].([]interface{}); ok {
+		f.BaseFunctions.Constructor(&data)
+	}
+
+	if node, ok := nodeOf((*data)["body"]); ok {
+		f.Body = *node.ToBlock()
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		f.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		f.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		f.Documentation = *node.ToStructuredDocumentation()
 	}
 
 	if data, ok := (*data)["functionSelector"].(string); ok {
@@ -310,9 +447,8 @@ func (f *FunctionDefinition) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["modifiers"].([]interface{}); ok {
 		//f.Modifiers = make([]ModifierInvocation, len(data))
 		for _, v := range data {
-			v := v.(map[string]interface{})
-			mi := NodeFactory(v).ToModifierInvocation()
-			mi.Constructor(&v)
+			node, _ := nodeOf(v)
+			mi := node.ToModifierInvocation()
 			f.Modifiers = append(f.Modifiers, *mi)
 		}
 	}
@@ -325,23 +461,20 @@ func (f *FunctionDefinition) Constructor(data *map[string]interface{}) {
 		f.NameLocation = data
 	}
 
-	if data, ok := (*data)["overrides"].(map[string]interface{}); ok {
-		f.Overrides = *NodeFactory(data).ToOverrideSpecifier()
-		f.Overrides.Constructor(&data)
+	if node, ok := nodeOf((*data)["overrides"]); ok {
+		f.Overrides = *node.ToOverrideSpecifier()
 	}
 
-	if data, ok := (*data)["parameters"].(map[string]interface{}); ok {
-		f.Parameters = *NodeFactory(data).ToParameterList()
-		f.Parameters.Constructor(&data)
+	if node, ok := nodeOf((*data)["parameters"]); ok {
+		f.Parameters = *node.ToParameterList()
 	}
 
-	if data, ok := (*data)["returnParameters"].(map[string]interface{}); ok {
-		f.ReturnParameters = *NodeFactory(data).ToParameterList()
-		f.ReturnParameters.Constructor(&data)
+	if node, ok := nodeOf((*data)["returnParameters"]); ok {
+		f.ReturnParameters = *node.ToParameterList()
 	}
 
-	if data, ok := (*data)["scope"].(int); ok {
-		f.Scope = data
+	if data, ok := (*data)["scope"].(float64); ok {
+		f.Scope = int(data)
 	}
 
 	if data, ok := (*data)["stateMutability"].(string); ok {
@@ -355,6 +488,17 @@ func (f *FunctionDefinition) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["visibility"].(string); ok {
 		f.Visibility = Visibility(data)
 	}
+
+	// solc < 0.5 has no kind
+	if f.Kind == "" {
+		if isConstructor, _ := (*data)["isConstructor"].(bool); isConstructor {
+			f.Kind = FunctionKind_Constructor
+		} else if f.Name == "" {
+			f.Kind = FunctionKind_Fallback
+		} else {
+			f.Kind = FunctionKind_Function
+		}
+	}
 }
 
 func (f *FunctionDefinition) IsPublic() bool {
@@ -369,6 +513,47 @@ func (f *FunctionDefinition) IsImplemented() bool {
 	return f.Implemented
 }
 
+// DisplayName returns the name of the function, or its kind for the unnamed
+// constructor, fallback and receive functions
+func (f *FunctionDefinition) DisplayName() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	if f.Kind != "" {
+		return f.Kind.String()
+	}
+	// solc < 0.5 has no kind, an unnamed function is the fallback
+	return FunctionKind_Fallback.String()
+}
+
+// Signature returns the name and the parameter types of the function, without
+// data locations, so that an override has the same signature as its base function.
+// Constructors, fallback and receive functions are identified by their kind.
+func (f *FunctionDefinition) Signature() string {
+	if f.Kind != FunctionKind_Function && f.Kind != FunctionKind_FreeFunction {
+		return f.Kind.String()
+	}
+
+	var params []string
+	for _, param := range f.Parameters.Parameters {
+		params = append(params, signatureType(param.TypeDescriptions.TypeString))
+	}
+	return f.Name + "(" + strings.Join(params, ",") + ")"
+}
+
+// signatureType removes the data location of a type string, e.g. string memory
+func signatureType(typeString string) string {
+	var words []string
+	for _, word := range strings.Fields(typeString) {
+		switch word {
+		case "memory", "calldata", "storage", "ref", "pointer":
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
 type ModifierInvocation struct {
 	Common
 	Arguments    []Expression `json:"arguments"` // Expression[] || null
@@ -384,14 +569,26 @@ func (m *ModifierInvocation) Attributes() *map[string]interface{} {
 	}
 }
 
+// ReferencedDeclaration returns the ID of the invoked ModifierDefinition,
+// or of the ContractDefinition for a base constructor specifier
+func (m *ModifierInvocation) ReferencedDeclaration() (int, bool) {
+	if m.ModifierName == nil {
+		return 0, false
+	}
+	switch n := m.ModifierName.ASTNode.(type) {
+	case *Identifier:
+		return n.ReferencedDeclaration, true
+	case *IdentifierPath:
+		return n.ReferencedDeclaration, true
+	}
+	return 0, false
+}
+
 func (m *ModifierInvocation) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["arguments"].([]interface{}); ok {
-		//m.Arguments = make([]Expression, len(data))
 		for _, v := range data {
-			v := v.(map[string]interface{})
-			//m.Arguments[i].ASTNode.Constructor(&v)
-			expr := NodeFactory(v)
-			expr.ASTNode.Constructor(&v)
+			expr, _ := nodeOf(v)
+			m.Arguments = append(m.Arguments, expr)
 		}
 	}
 
@@ -399,9 +596,8 @@ func (m *ModifierInvocation) Constructor(data *map[string]interface{}) {
 		m.Kind = ModifierKind(data)
 	}
 
-	if data, ok := (*data)["modifierName"].(map[string]interface{}); ok {
-		m.ModifierName = NodeFactory(data)
-		m.ModifierName.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["modifierName"]); ok {
+		m.ModifierName = node
 	}
 }
 
@@ -443,25 +639,22 @@ func (f *FunctionCall) Constructor(data *map[string]interface{}) {
 		//f.ArgumentTypes = make([]TypeDescriptions, len(data))
 		for _, v := range data {
 			v := v.(map[string]interface{})
-			td := NodeFactory(v).ToTypeDescriptions()
+			td := TypeDescriptions{}
 			td.Constructor(&v)
-			f.ArgumentTypes = append(f.ArgumentTypes, *td)
+			f.ArgumentTypes = append(f.ArgumentTypes, td)
 		}
 	}
 
 	if data, ok := (*data)["arguments"].([]interface{}); ok {
 		//f.Arguments = make([]Expression, len(data))
 		for _, v := range data {
-			v := v.(map[string]interface{})
-			expr := NodeFactory(v)
-			expr.ASTNode.Constructor(&v)
+			expr, _ := nodeOf(v)
 			f.Arguments = append(f.Arguments, expr)
 		}
 	}
 
-	if data, ok := (*data)["expression"].(map[string]interface{}); ok {
-		f.Expression = NodeFactory(data)
-		f.Expression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["expression"]); ok {
+		f.Expression = node
 	}
 
 	if data, ok := (*data)["isConstant"].(bool); ok {
@@ -484,12 +677,21 @@ func (f *FunctionCall) Constructor(data *map[string]interface{}) {
 		f.LValueRequested = data
 	}
 
-	if data, ok := (*data)["nameLocations"].([]string); ok {
-		f.NameLocations = data
+	if data, ok := (*data)["nameLocations"].([]interface{}); ok {
+		for _, v := range data {
+			if v, ok := v.(string); ok {
+				f.NameLocations = append(f.NameLocations, v)
+			}
+		}
 	}
 
-	if data, ok := (*data)["names"].([]string); ok {
-		f.Names = data
+	// the names of the arguments of a call f({a: 1, b: 2})
+	if data, ok := (*data)["names"].([]interface{}); ok {
+		for _, v := range data {
+			if v, ok := v.(string); ok {
+				f.Names = append(f.Names, v)
+			}
+		}
 	}
 
 	if data, ok := (*data)["tryCall"].(bool); ok {
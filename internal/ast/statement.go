@@ -1,5 +1,10 @@
 package ast
 
+import (
+	"strings"
+	"txtracker/internal/logger"
+)
+
 type Statement *Common
 
 type Block struct {
@@ -17,9 +22,7 @@ func (b *Block) Constructor(data *map[string]interface{}) {
 
 	if data, ok := (*data)["statements"].([]interface{}); ok {
 		for _, v := range data {
-			v := v.(map[string]interface{})
-			sm := NodeFactory(v)
-			sm.ASTNode.Constructor(&v)
+			sm, _ := nodeOf(v)
 			b.Statements = append(b.Statements, sm)
 		}
 	}
@@ -29,6 +32,28 @@ func (b *Block) DescribeStatement() string {
 	return "Block"
 }
 
+// UncheckedBlock is an `unchecked { ... }` block of solc >= 0.8, where the
+// arithmetic wraps around instead of reverting
+type UncheckedBlock struct {
+	Common
+	Statements []*Common `json:"statements"`
+}
+
+func (u *UncheckedBlock) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Statements": u.Statements,
+	}
+}
+
+func (u *UncheckedBlock) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["statements"].([]interface{}); ok {
+		for _, v := range data {
+			sm, _ := nodeOf(v)
+			u.Statements = append(u.Statements, sm)
+		}
+	}
+}
+
 type IfStatement struct {
 	Common
 	Condition     Expression              `json:"condition"` // Expression | null
@@ -47,23 +72,20 @@ func (i *IfStatement) Attributes() *map[string]interface{} {
 }
 
 func (i *IfStatement) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["condition"].(map[string]interface{}); ok {
-		i.Condition = NodeFactory(data)
-		i.Condition.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["condition"]); ok {
+		i.Condition = node
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		i.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		i.Documentation = *node.ToStructuredDocumentation()
 	}
 
-	if data, ok := (*data)["falseBody"].(map[string]interface{}); ok {
-		i.FalseBody = NodeFactory(data)
-		i.FalseBody.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["falseBody"]); ok {
+		i.FalseBody = node
 	}
 
-	if data, ok := (*data)["trueBody"].(map[string]interface{}); ok {
-		i.TrueBody = NodeFactory(data)
-		i.TrueBody.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["trueBody"]); ok {
+		i.TrueBody = node
 	}
 }
 func (i *IfStatement) DescribeStatement() string {
@@ -86,17 +108,16 @@ func (r *Return) Attributes() *map[string]interface{} {
 }
 
 func (r *Return) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		r.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		r.Documentation = *node.ToStructuredDocumentation()
 	}
 
-	if data, ok := (*data)["expression"].(map[string]interface{}); ok {
-		r.Expression = NodeFactory(data)
-		r.Expression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["expression"]); ok {
+		r.Expression = node
 	}
 
-	if data, ok := (*data)["functionReturnParameters"].(int); ok {
-		r.FunctionReturnParameters = data
+	if data, ok := (*data)["functionReturnParameters"].(float64); ok {
+		r.FunctionReturnParameters = int(data)
 	}
 }
 
@@ -106,8 +127,8 @@ func (r *Return) DescribeStatement() string {
 
 type VariableDeclarationStatement struct {
 	Common
-	Assignments   []int                   `json:"assignments"`  // int[] | null
-	Declarations  []*VariableDeclaration  `json:"declarations"` // VariableDeclaration
+	Assignments   []int                   `json:"assignments"`  // int[] | null, 0 for a component left out as in (bool ok, ) = ...
+	Declarations  []*VariableDeclaration  `json:"declarations"` // VariableDeclaration, nil for a component left out
 	Documentation StructuredDocumentation `json:"documentation"`
 	InitialValue  Expression              `json:"initialValue"` // Expression | null
 }
@@ -116,6 +137,9 @@ func (v *VariableDeclarationStatement) GetDeclarations() ([]string, []bool) {
 	var declarations []string
 	var statevariable []bool
 	for _, vd := range v.Declarations {
+		if vd == nil {
+			continue
+		}
 		declaration, state := vd.GetDeclaration()
 		declarations = append(declarations, declaration)
 		statevariable = append(statevariable, state)
@@ -142,28 +166,29 @@ func (v *VariableDeclarationStatement) Attributes() *map[string]interface{} {
 func (v *VariableDeclarationStatement) Constructor(data *map[string]interface{}) {
 	if data, ok := (*data)["assignments"].([]interface{}); ok {
 		for _, dt := range data {
-			dt := int(dt.(float64))
-			v.Assignments = append(v.Assignments, dt)
+			id, _ := dt.(float64)
+			v.Assignments = append(v.Assignments, int(id))
 		}
 	}
 
 	if data, ok := (*data)["declarations"].([]interface{}); ok {
 		v.Declarations = make([]*VariableDeclaration, 0)
 		for _, dt := range data {
-			dt := dt.(map[string]interface{})
-			vd := &VariableDeclaration{}
-			vd.Constructor(&dt)
-			v.Declarations = append(v.Declarations, vd)
+			node, ok := nodeOf(dt)
+			if !ok {
+				v.Declarations = append(v.Declarations, nil)
+				continue
+			}
+			v.Declarations = append(v.Declarations, as[VariableDeclaration](node))
 		}
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		v.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		v.Documentation = *node.ToStructuredDocumentation()
 	}
 
-	if data, ok := (*data)["initialValue"].(map[string]interface{}); ok {
-		v.InitialValue = NodeFactory(data)
-		v.InitialValue.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["initialValue"]); ok {
+		v.InitialValue = node
 	}
 }
 
@@ -195,6 +220,30 @@ func (v *VariableDeclaration) GetDeclaration() (string, bool) {
 	return v.Name, v.StateVariable
 }
 
+// IsPublic reports whether solc generates a getter for the state variable
+func (v *VariableDeclaration) IsPublic() bool {
+	return v.StateVariable && v.Visibility == "public"
+}
+
+// GetterSignature returns the signature of the getter of a public state variable,
+// with a parameter per mapping key and array index, e.g. allowance(address,address)
+func (v *VariableDeclaration) GetterSignature() string {
+	var params []string
+	for typeName := v.TypeName; typeName != nil; {
+		switch t := typeName.ASTNode.(type) {
+		case *Mapping:
+			params = append(params, signatureType(t.KeyType.GetTypeDescriptions().TypeString))
+			typeName = t.ValueType
+		case *ArrayTypeName:
+			params = append(params, "uint256")
+			typeName = t.BaseType
+		default:
+			typeName = nil
+		}
+	}
+	return v.Name + "(" + strings.Join(params, ",") + ")"
+}
+
 func (v *VariableDeclaration) Attributes() *map[string]interface{} {
 	return &map[string]interface{}{
 		"BaseFunctions":    v.BaseFunctions,
@@ -217,8 +266,7 @@ func (v *VariableDeclaration) Attributes() *map[string]interface{} {
 }
 
 func (v *VariableDeclaration) Constructor(_data *map[string]interface{}) {
-	if data, ok := (*_data)["baseFunctions"].([]float64); ok {
-		v.BaseFunctions = make(BaseFunctions, len(data))
+	if data, ok := (*_data)["baseFunctions"].([]interface{}); ok {
 		v.BaseFunctions.Constructor(&data)
 	}
 
@@ -226,8 +274,8 @@ func (v *VariableDeclaration) Constructor(_data *map[string]interface{}) {
 		v.Constant = data
 	}
 
-	if data, ok := (*_data)["documentation"].(map[string]interface{}); ok {
-		v.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*_data)["documentation"]); ok {
+		v.Documentation = *node.ToStructuredDocumentation()
 	}
 
 	if data, ok := (*_data)["functionSelector"].(string); ok {
@@ -250,8 +298,8 @@ func (v *VariableDeclaration) Constructor(_data *map[string]interface{}) {
 		v.NameLocation = data
 	}
 
-	if data, ok := (*_data)["overrides"].(map[string]interface{}); ok {
-		v.Overrides.Constructor(&data)
+	if node, ok := nodeOf((*_data)["overrides"]); ok {
+		v.Overrides = *node.ToOverrideSpecifier()
 	}
 
 	if data, ok := (*_data)["scope"].(float64); ok {
@@ -271,14 +319,12 @@ func (v *VariableDeclaration) Constructor(_data *map[string]interface{}) {
 		v.TypeDescriptions.Constructor(&data)
 	}
 
-	if data, ok := (*_data)["typeName"].(map[string]interface{}); ok {
-		v.TypeName = NodeFactory(data)
-		v.TypeName.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*_data)["typeName"]); ok {
+		v.TypeName = node
 	}
 
-	if data, ok := (*_data)["value"].(map[string]interface{}); ok {
-		v.Value = NodeFactory(data)
-		v.Value.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*_data)["value"]); ok {
+		v.Value = node
 	}
 
 	if data, ok := (*_data)["visibility"].(string); ok {
@@ -300,13 +346,12 @@ func (e *ExpressionStatement) Attributes() *map[string]interface{} {
 }
 
 func (e *ExpressionStatement) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["expression"].(map[string]interface{}); ok {
-		e.Expression = NodeFactory(data)
-		e.Expression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["expression"]); ok {
+		e.Expression = node
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		e.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		e.Documentation = *node.ToStructuredDocumentation()
 	}
 }
 
@@ -322,9 +367,8 @@ func (p *PlaceholderStatement) Attributes() *map[string]interface{} {
 }
 
 func (p *PlaceholderStatement) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		p.Documentation = *NodeFactory(data).ToStructuredDocumentation()
-		p.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		p.Documentation = *node.ToStructuredDocumentation()
 	}
 }
 
@@ -350,32 +394,28 @@ func (f *ForStatement) Attributes() *map[string]interface{} {
 }
 
 func (f *ForStatement) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["body"].(map[string]interface{}); ok {
-		f.Body = NodeFactory(data)
-		f.Body.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["body"]); ok {
+		f.Body = node
 	}
 
-	if data, ok := (*data)["condition"].(map[string]interface{}); ok {
-		f.Condition = NodeFactory(data)
-		f.Condition.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["condition"]); ok {
+		f.Condition = node
 	}
 
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		f.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		f.Documentation = *node.ToStructuredDocumentation()
 	}
 
-	if data, ok := (*data)["initializationExpression"].(map[string]interface{}); ok {
-		f.InitializationExpression = NodeFactory(data)
-		f.InitializationExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["initializationExpression"]); ok {
+		f.InitializationExpression = node
 	}
 
 	if data, ok := (*data)["isSimpleCounterLoop"].(bool); ok {
 		f.IsSimpleCounterLoop = data
 	}
 
-	if data, ok := (*data)["loopExpression"].(map[string]interface{}); ok {
-		f.LoopExpression = NodeFactory(data)
-		f.LoopExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["loopExpression"]); ok {
+		f.LoopExpression = node
 	}
 }
 
@@ -446,9 +486,8 @@ func (u *UnaryOperation) Constructor(data *map[string]interface{}) {
 		u.Prefix = data
 	}
 
-	if data, ok := (*data)["subExpression"].(map[string]interface{}); ok {
-		u.SubExpression = NodeFactory(data)
-		u.SubExpression.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["subExpression"]); ok {
+		u.SubExpression = node
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -468,7 +507,360 @@ func (b *Break) Attributes() *map[string]interface{} {
 }
 
 func (b *Break) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["documentation"].(map[string]interface{}); ok {
-		b.Documentation.Constructor(&data)
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		b.Documentation = *node.ToStructuredDocumentation()
+	}
+}
+
+type Continue struct {
+	Common
+	Documentation StructuredDocumentation `json:"documentation"`
+}
+
+func (c *Continue) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Documentation": c.Documentation,
+	}
+}
+
+func (c *Continue) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		c.Documentation = *node.ToStructuredDocumentation()
+	}
+}
+
+type WhileStatement struct {
+	Common
+	Body          Statement               `json:"body"`      // Statement
+	Condition     Expression              `json:"condition"` // Expression
+	Documentation StructuredDocumentation `json:"documentation"`
+}
+
+func (w *WhileStatement) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Body":          w.Body,
+		"Condition":     w.Condition,
+		"Documentation": w.Documentation,
+	}
+}
+
+func (w *WhileStatement) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["body"]); ok {
+		w.Body = node
+	}
+
+	if node, ok := nodeOf((*data)["condition"]); ok {
+		w.Condition = node
 	}
+
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		w.Documentation = *node.ToStructuredDocumentation()
+	}
+}
+
+type DoWhileStatement struct {
+	Common
+	Body          Statement               `json:"body"`      // Statement
+	Condition     Expression              `json:"condition"` // Expression
+	Documentation StructuredDocumentation `json:"documentation"`
+}
+
+func (d *DoWhileStatement) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Body":          d.Body,
+		"Condition":     d.Condition,
+		"Documentation": d.Documentation,
+	}
+}
+
+func (d *DoWhileStatement) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["body"]); ok {
+		d.Body = node
+	}
+
+	if node, ok := nodeOf((*data)["condition"]); ok {
+		d.Condition = node
+	}
+
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		d.Documentation = *node.ToStructuredDocumentation()
+	}
+}
+
+// Throw is the `throw;` statement of solc < 0.5
+type Throw struct {
+	Common
+	Documentation StructuredDocumentation `json:"documentation"`
+}
+
+func (t *Throw) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Documentation": t.Documentation,
+	}
+}
+
+func (t *Throw) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		t.Documentation = *node.ToStructuredDocumentation()
+	}
+}
+
+type RevertStatement struct {
+	Common
+	Documentation StructuredDocumentation `json:"documentation"`
+	ErrorCall     Expression              `json:"errorCall"` // FunctionCall
+}
+
+func (r *RevertStatement) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Documentation": r.Documentation,
+		"ErrorCall":     r.ErrorCall,
+	}
+}
+
+func (r *RevertStatement) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		r.Documentation = *node.ToStructuredDocumentation()
+	}
+
+	if node, ok := nodeOf((*data)["errorCall"]); ok {
+		r.ErrorCall = node
+	}
+}
+
+type TryStatement struct {
+	Common
+	Clauses       []*Common               `json:"clauses"` // TryCatchClause[]
+	Documentation StructuredDocumentation `json:"documentation"`
+	ExternalCall  Expression              `json:"externalCall"` // FunctionCall
+}
+
+func (t *TryStatement) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Clauses":       t.Clauses,
+		"Documentation": t.Documentation,
+		"ExternalCall":  t.ExternalCall,
+	}
+}
+
+func (t *TryStatement) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["clauses"].([]interface{}); ok {
+		for _, v := range data {
+			clause, _ := nodeOf(v)
+			t.Clauses = append(t.Clauses, clause)
+		}
+	}
+
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		t.Documentation = *node.ToStructuredDocumentation()
+	}
+
+	if node, ok := nodeOf((*data)["externalCall"]); ok {
+		t.ExternalCall = node
+	}
+}
+
+type TryCatchClause struct {
+	Common
+	Block      Block         `json:"block"`
+	ErrorName  string        `json:"errorName"`  // "" for the success clause and `catch { }`
+	Parameters ParameterList `json:"parameters"` // ParameterList | null
+}
+
+func (t *TryCatchClause) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Block":      t.Block,
+		"ErrorName":  t.ErrorName,
+		"Parameters": t.Parameters,
+	}
+}
+
+func (t *TryCatchClause) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["block"]); ok {
+		t.Block = *node.ToBlock()
+	}
+
+	if data, ok := (*data)["errorName"].(string); ok {
+		t.ErrorName = data
+	}
+
+	if node, ok := nodeOf((*data)["parameters"]); ok {
+		t.Parameters = *node.ToParameterList()
+	}
+}
+
+// EmitStatement is the `emit Event(...)` statement of solc >= 0.4.21
+type EmitStatement struct {
+	Common
+	Documentation StructuredDocumentation `json:"documentation"`
+	EventCall     Expression              `json:"eventCall"` // FunctionCall
+}
+
+func (e *EmitStatement) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Documentation": e.Documentation,
+		"EventCall":     e.EventCall,
+	}
+}
+
+func (e *EmitStatement) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		e.Documentation = *node.ToStructuredDocumentation()
+	}
+
+	if node, ok := nodeOf((*data)["eventCall"]); ok {
+		e.EventCall = node
+	}
+}
+
+// InlineAssemblyReference is a Solidity declaration used in an assembly block
+type InlineAssemblyReference struct {
+	Name        string `json:"name"`        // the key of the reference in solc < 0.6, "" since
+	Declaration int    `json:"declaration"` // VariableDeclaration
+	IsOffset    bool   `json:"isOffset"`    // x.offset
+	IsSlot      bool   `json:"isSlot"`      // x.slot
+	Src         string `json:"src"`
+	Suffix      string `json:"suffix"` // "slot" | "offset" | "length" | null
+	ValueSize   int    `json:"valueSize"`
+}
+
+func (r *InlineAssemblyReference) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["declaration"].(float64); ok {
+		r.Declaration = int(data)
+	}
+
+	if data, ok := (*data)["isOffset"].(bool); ok {
+		r.IsOffset = data
+	}
+
+	if data, ok := (*data)["isSlot"].(bool); ok {
+		r.IsSlot = data
+	}
+
+	if data, ok := (*data)["src"].(string); ok {
+		r.Src = data
+	}
+
+	if data, ok := (*data)["suffix"].(string); ok {
+		r.Suffix = data
+	}
+
+	if data, ok := (*data)["valueSize"].(float64); ok {
+		r.ValueSize = int(data)
+	}
+}
+
+// InlineAssembly is an `assembly { ... }` block. solc >= 0.6 gives the Yul
+// tree in AST, older versions the source of the block in Operations, parsed
+// into AST by ParseYul. The YulIdentifiers of the external references are
+// given the Solidity declaration they refer to.
+type InlineAssembly struct {
+	Common
+	AST                *Common                   `json:"AST"` // YulBlock | null
+	Documentation      StructuredDocumentation   `json:"documentation"`
+	EvmVersion         string                    `json:"evmVersion"` // string | null
+	ExternalReferences []InlineAssemblyReference `json:"externalReferences"`
+	Flags              []string                  `json:"flags"`      // e.g. "memory-safe"
+	Operations         string                    `json:"operations"` // string | null
+}
+
+func (i *InlineAssembly) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"AST":                i.AST,
+		"Documentation":      i.Documentation,
+		"EvmVersion":         i.EvmVersion,
+		"ExternalReferences": i.ExternalReferences,
+		"Flags":              i.Flags,
+		"Operations":         i.Operations,
+	}
+}
+
+func (i *InlineAssembly) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["AST"]); ok {
+		i.AST = node
+	}
+
+	if node, ok := nodeOf((*data)["documentation"]); ok {
+		i.Documentation = *node.ToStructuredDocumentation()
+	}
+
+	if data, ok := (*data)["evmVersion"].(string); ok {
+		i.EvmVersion = data
+	}
+
+	if data, ok := (*data)["externalReferences"].([]interface{}); ok {
+		for _, v := range data {
+			v, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := v["declaration"]; ok {
+				var ref InlineAssemblyReference
+				ref.Constructor(&v)
+				i.ExternalReferences = append(i.ExternalReferences, ref)
+				continue
+			}
+			// solc < 0.6 maps the name of the reference to the reference
+			for name, dt := range v {
+				if dt, ok := dt.(map[string]interface{}); ok {
+					ref := InlineAssemblyReference{Name: name}
+					ref.Constructor(&dt)
+					i.ExternalReferences = append(i.ExternalReferences, ref)
+				}
+			}
+		}
+	}
+
+	if data, ok := (*data)["flags"].([]interface{}); ok {
+		for _, v := range data {
+			if v, ok := v.(string); ok {
+				i.Flags = append(i.Flags, v)
+			}
+		}
+	}
+
+	if data, ok := (*data)["operations"].(string); ok {
+		i.Operations = data
+	}
+
+	if i.AST == nil && i.Operations != "" {
+		src, _ := (*data)["src"].(string)
+		block, err := ParseYul(i.Operations, src)
+		if err != nil {
+			logger.Warning.Println("Cannot parse the assembly", src+":", err)
+		} else {
+			i.AST = block
+		}
+	}
+	i._resolveReferences()
+}
+
+// _resolveReferences sets the declaration of the YulIdentifiers referring to a
+// Solidity variable. solc >= 0.6 locates the references, older versions name
+// them, e.g. x_slot.
+func (i *InlineAssembly) _resolveReferences() {
+	Inspect(i.AST, func(node *Common) bool {
+		ident, ok := node.ASTNode.(*YulIdentifier)
+		if !ok {
+			return true
+		}
+		for _, ref := range i.ExternalReferences {
+			matched := ref.Name == ident.Name
+			if ref.Name == "" {
+				matched = ref.Src == node.Src
+			}
+			if !matched {
+				continue
+			}
+			ident.Declaration = ref.Declaration
+			ident.Suffix = ref.Suffix
+			if ident.Suffix == "" && ref.IsSlot {
+				ident.Suffix = "slot"
+			} else if ident.Suffix == "" && ref.IsOffset {
+				ident.Suffix = "offset"
+			}
+			break
+		}
+		return true
+	})
 }
@@ -64,9 +64,8 @@ func (m *Mapping) Constructor(data *map[string]interface{}) {
 		m.KeyNameLocation = data
 	}
 
-	if data, ok := (*data)["keyType"].(map[string]interface{}); ok {
-		m.KeyType = NodeFactory(data)
-		m.KeyType.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["keyType"]); ok {
+		m.KeyType = node
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
@@ -81,13 +80,13 @@ func (m *Mapping) Constructor(data *map[string]interface{}) {
 		m.ValueNameLocation = data
 	}
 
-	if data, ok := (*data)["valueType"].(map[string]interface{}); ok {
-		m.ValueType = NodeFactory(data)
-		m.ValueType.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["valueType"]); ok {
+		m.ValueType = node
 	}
 }
 
 type ArrayTypeName struct {
+	Common
 	BaseType         TypeName         `json:"baseType"`
 	Length           Expression       `json:"length"`
 	TypeDescriptions TypeDescriptions `json:"typeDescriptions"`
@@ -102,17 +101,57 @@ func (a *ArrayTypeName) Attributes() *map[string]interface{} {
 }
 
 func (a *ArrayTypeName) Constructor(data *map[string]interface{}) {
-	if data, ok := (*data)["baseType"].(map[string]interface{}); ok {
-		a.BaseType = NodeFactory(data)
-		a.BaseType.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["baseType"]); ok {
+		a.BaseType = node
 	}
 
-	if data, ok := (*data)["length"].(map[string]interface{}); ok {
-		a.Length = NodeFactory(data)
-		a.Length.ASTNode.Constructor(&data)
+	if node, ok := nodeOf((*data)["length"]); ok {
+		a.Length = node
 	}
 
 	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
 		a.TypeDescriptions.Constructor(&data)
 	}
 }
+
+// FunctionTypeName is a function type, e.g. function (uint256) external returns (bool)
+type FunctionTypeName struct {
+	Common
+	ParameterTypes       ParameterList    `json:"parameterTypes"`
+	ReturnParameterTypes ParameterList    `json:"returnParameterTypes"`
+	StateMutability      StateMutability  `json:"stateMutability"`
+	TypeDescriptions     TypeDescriptions `json:"typeDescriptions"`
+	Visibility           Visibility       `json:"visibility"`
+}
+
+func (f *FunctionTypeName) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"ParameterTypes":       f.ParameterTypes,
+		"ReturnParameterTypes": f.ReturnParameterTypes,
+		"StateMutability":      f.StateMutability,
+		"TypeDescriptions":     f.TypeDescriptions,
+		"Visibility":           f.Visibility,
+	}
+}
+
+func (f *FunctionTypeName) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["parameterTypes"]); ok {
+		f.ParameterTypes = *node.ToParameterList()
+	}
+
+	if node, ok := nodeOf((*data)["returnParameterTypes"]); ok {
+		f.ReturnParameterTypes = *node.ToParameterList()
+	}
+
+	if data, ok := (*data)["stateMutability"].(string); ok {
+		f.StateMutability = StateMutability(data)
+	}
+
+	if data, ok := (*data)["typeDescriptions"].(map[string]interface{}); ok {
+		f.TypeDescriptions.Constructor(&data)
+	}
+
+	if data, ok := (*data)["visibility"].(string); ok {
+		f.Visibility = Visibility(data)
+	}
+}
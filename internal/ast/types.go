@@ -3,11 +3,12 @@ package ast
 type ExportedSymbols map[string][]int
 
 // Constructors
-func (e *ExportedSymbols) Constructor(data *map[string][]float64) {
+func (e *ExportedSymbols) Constructor(data *map[string]interface{}) {
 	for key, value := range *data {
-		(*e)[key] = make([]int, len(value))
-		for i, v := range value {
-			(*e)[key][i] = int(v)
+		if ids, ok := value.([]interface{}); ok {
+			for _, id := range ids {
+				(*e)[key] = append((*e)[key], int(id.(float64)))
+			}
 		}
 	}
 }
@@ -15,42 +16,42 @@ func (e *ExportedSymbols) Constructor(data *map[string][]float64) {
 type InternalFunctionIDs map[string]int
 
 // Constructors
-func (i *InternalFunctionIDs) Constructor(data *map[string]float64) {
+func (i *InternalFunctionIDs) Constructor(data *map[string]interface{}) {
 	for key, value := range *data {
-		(*i)[key] = int(value)
+		(*i)[key] = int(value.(float64))
 	}
 }
 
 type LinearizedBaseContracts []int
 
 // Constructors
-func (l *LinearizedBaseContracts) Constructor(data *[]float64) {
+func (l *LinearizedBaseContracts) Constructor(data *[]interface{}) {
 	for _, value := range *data {
-		*l = append(*l, int(value))
+		*l = append(*l, int(value.(float64)))
 	}
 }
 
 type ContractDependencies []int
 
-func (c *ContractDependencies) Constructor(data *[]float64) {
+func (c *ContractDependencies) Constructor(data *[]interface{}) {
 	for _, value := range *data {
-		*c = append(*c, int(value))
+		*c = append(*c, int(value.(float64)))
 	}
 }
 
 type UsedErrors []int
 
-func (u *UsedErrors) Constructor(data *[]float64) {
+func (u *UsedErrors) Constructor(data *[]interface{}) {
 	for _, value := range *data {
-		*u = append(*u, int(value))
+		*u = append(*u, int(value.(float64)))
 	}
 }
 
 type UsedEvents []int
 
-func (u *UsedEvents) Constructor(data *[]float64) {
+func (u *UsedEvents) Constructor(data *[]interface{}) {
 	for _, value := range *data {
-		*u = append(*u, int(value))
+		*u = append(*u, int(value.(float64)))
 	}
 }
 
@@ -64,9 +65,9 @@ func (n *NameLocations) Constructor(data *[]string) {
 
 type BaseFunctions []int
 
-func (b *BaseFunctions) Constructor(data *[]float64) {
+func (b *BaseFunctions) Constructor(data *[]interface{}) {
 	for _, value := range *data {
-		*b = append(*b, int(value))
+		*b = append(*b, int(value.(float64)))
 	}
 }
 
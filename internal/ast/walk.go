@@ -0,0 +1,112 @@
+package ast
+
+// Inspect traverses the statements and expressions below node in depth-first
+// order. It calls f(node) first; if f returns false, the children of node are
+// skipped. Definitions (contracts, functions, ...) are reached through
+// Common.Children instead, so Inspect does not descend into them.
+func Inspect(node *Common, f func(*Common) bool) {
+	if node == nil || node.ASTNode == nil {
+		return
+	}
+	if !f(node) {
+		return
+	}
+
+	for _, child := range innerNodes(node) {
+		Inspect(child, f)
+	}
+}
+
+// InspectBlock calls Inspect on every statement of a block that was inlined
+// into its parent, like FunctionDefinition.Body.
+func InspectBlock(block *Block, f func(*Common) bool) {
+	for _, stmt := range block.Statements {
+		Inspect(stmt, f)
+	}
+}
+
+// innerNodes returns the statements and expressions directly below node
+func innerNodes(node *Common) []*Common {
+	var res []*Common
+	switch n := node.ASTNode.(type) {
+	// Statements
+	case *Block:
+		res = append(res, n.Statements...)
+	case *UncheckedBlock:
+		res = append(res, n.Statements...)
+	case *IfStatement:
+		res = append(res, n.Condition, n.TrueBody, n.FalseBody)
+	case *ForStatement:
+		res = append(res, n.InitializationExpression, n.Condition, n.LoopExpression, n.Body)
+	case *Return:
+		res = append(res, n.Expression)
+	case *VariableDeclarationStatement:
+		res = append(res, n.InitialValue)
+	case *ExpressionStatement:
+		res = append(res, n.Expression)
+	case *WhileStatement:
+		res = append(res, n.Condition, n.Body)
+	case *DoWhileStatement:
+		res = append(res, n.Body, n.Condition)
+	case *RevertStatement:
+		res = append(res, n.ErrorCall)
+	case *TryStatement:
+		res = append(res, n.ExternalCall)
+		res = append(res, n.Clauses...)
+	case *TryCatchClause:
+		res = append(res, n.Block.Statements...)
+	case *EmitStatement:
+		res = append(res, n.EventCall)
+	case *InlineAssembly:
+		res = append(res, n.AST)
+
+	// Yul
+	case *YulBlock:
+		res = append(res, n.Statements...)
+	case *YulVariableDeclaration:
+		res = append(res, n.Value)
+	case *YulAssignment:
+		res = append(res, n.VariableNames...)
+		res = append(res, n.Value)
+	case *YulExpressionStatement:
+		res = append(res, n.Expression)
+	case *YulIf:
+		res = append(res, n.Condition, n.Body)
+	case *YulSwitch:
+		res = append(res, n.Expression)
+		res = append(res, n.Cases...)
+	case *YulCase:
+		res = append(res, n.Value, n.Body)
+	case *YulForLoop:
+		res = append(res, n.Pre, n.Condition, n.Post, n.Body)
+	case *YulFunctionDefinition:
+		res = append(res, n.Body)
+	case *YulFunctionCall:
+		res = append(res, n.Arguments...)
+
+	// Expressions
+	case *Assignment:
+		res = append(res, n.LeftHandSide, n.RightHandSide)
+	case *BinaryOperation:
+		res = append(res, n.LeftExpression, n.RightExpression)
+	case *UnaryOperation:
+		res = append(res, n.SubExpression)
+	case *Conditional:
+		res = append(res, n.Condition, n.TrueExpression, n.FalseExpression)
+	case *FunctionCall:
+		res = append(res, n.Expression)
+		res = append(res, n.Arguments...)
+	case *MemberAccess:
+		res = append(res, n.Expression)
+	case *IndexAccess:
+		res = append(res, n.BaseExpression, n.IndexExpression)
+	case *IndexRangeAccess:
+		res = append(res, n.BaseExpression, n.StartExpression, n.EndExpression)
+	case *FunctionCallOptions:
+		res = append(res, n.Expression)
+		res = append(res, n.Options...)
+	case *TupleExpression:
+		res = append(res, n.Components...)
+	}
+	return res
+}
@@ -0,0 +1,486 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+	"txtracker/internal/logger"
+)
+
+// Yul nodes are the body of an InlineAssembly. They have no id, YulNodeFactory
+// builds them instead of NodeFactory.
+
+// YulNodeFactory returns the Yul node of a JSON object with its common fields,
+// the caller then calls its Constructor. An unknown Yul node type gives a
+// GenericNode; an object without nodeType panics with a *NodeError.
+func YulNodeFactory(data map[string]interface{}) *Common {
+	nodeType, ok := data["nodeType"].(string)
+	if !ok || nodeType == "" {
+		panic(&NodeError{Field: "nodeType", Node: data})
+	}
+	common := &Common{
+		NodeType: nodeType,
+	}
+	if src, ok := data["src"].(string); ok {
+		common.Src = src
+	}
+	if newNode, ok := yulNodes[nodeType]; ok {
+		common.ASTNode = newNode()
+	} else {
+		logger.Warning.Println("Unknown Yul node type", nodeType+":", common.Src)
+		common.ASTNode = &GenericNode{}
+	}
+	return common
+}
+
+var yulNodes = map[string]func() ASTNode{
+	"YulBlock":               func() ASTNode { return &YulBlock{} },
+	"YulVariableDeclaration": func() ASTNode { return &YulVariableDeclaration{} },
+	"YulAssignment":          func() ASTNode { return &YulAssignment{} },
+	"YulExpressionStatement": func() ASTNode { return &YulExpressionStatement{} },
+	"YulIf":                  func() ASTNode { return &YulIf{} },
+	"YulSwitch":              func() ASTNode { return &YulSwitch{} },
+	"YulCase":                func() ASTNode { return &YulCase{} },
+	"YulForLoop":             func() ASTNode { return &YulForLoop{} },
+	"YulFunctionDefinition":  func() ASTNode { return &YulFunctionDefinition{} },
+	"YulBreak":               func() ASTNode { return &YulBreak{} },
+	"YulContinue":            func() ASTNode { return &YulContinue{} },
+	"YulLeave":               func() ASTNode { return &YulLeave{} },
+	"YulTypedName":           func() ASTNode { return &YulTypedName{} },
+
+	// Expressions
+	"YulFunctionCall": func() ASTNode { return &YulFunctionCall{} },
+	"YulIdentifier":   func() ASTNode { return &YulIdentifier{} },
+	"YulLiteral":      func() ASTNode { return &YulLiteral{} },
+}
+
+// newYulNodes returns the Yul nodes of a list, skipping the values that are not nodes
+func newYulNodes(data []interface{}) []*Common {
+	var res []*Common
+	for _, v := range data {
+		if node, ok := nodeOf(v); ok {
+			res = append(res, node)
+		}
+	}
+	return res
+}
+
+type YulBlock struct {
+	Common
+	Statements []*Common `json:"statements"`
+}
+
+func (b *YulBlock) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Statements": b.Statements,
+	}
+}
+
+func (b *YulBlock) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["statements"].([]interface{}); ok {
+		b.Statements = newYulNodes(data)
+	}
+}
+
+// YulTypedName is a variable declared by let, or a parameter of a Yul function
+type YulTypedName struct {
+	Common
+	Name string `json:"name"`
+	Type string `json:"type"` // "" unless typed, e.g. x:u256
+}
+
+func (t *YulTypedName) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Name": t.Name,
+		"Type": t.Type,
+	}
+}
+
+func (t *YulTypedName) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["name"].(string); ok {
+		t.Name = data
+	}
+
+	if data, ok := (*data)["type"].(string); ok {
+		t.Type = data
+	}
+}
+
+func newYulTypedNames(data []interface{}) []YulTypedName {
+	var res []YulTypedName
+	for _, v := range data {
+		if node, ok := nodeOf(v); ok {
+			res = append(res, *as[YulTypedName](node))
+		}
+	}
+	return res
+}
+
+// YulVariableDeclaration is let x, y := value
+type YulVariableDeclaration struct {
+	Common
+	Value     *Common        `json:"value"` // Expression | null
+	Variables []YulTypedName `json:"variables"`
+}
+
+func (v *YulVariableDeclaration) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Value":     v.Value,
+		"Variables": v.Variables,
+	}
+}
+
+func (v *YulVariableDeclaration) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["value"]); ok {
+		v.Value = node
+	}
+
+	if data, ok := (*data)["variables"].([]interface{}); ok {
+		v.Variables = newYulTypedNames(data)
+	}
+}
+
+// YulAssignment is x, y := value, the variables are YulIdentifiers
+type YulAssignment struct {
+	Common
+	Value         *Common   `json:"value"`
+	VariableNames []*Common `json:"variableNames"`
+}
+
+func (a *YulAssignment) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Value":         a.Value,
+		"VariableNames": a.VariableNames,
+	}
+}
+
+func (a *YulAssignment) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["value"]); ok {
+		a.Value = node
+	}
+
+	if data, ok := (*data)["variableNames"].([]interface{}); ok {
+		a.VariableNames = newYulNodes(data)
+	}
+}
+
+type YulExpressionStatement struct {
+	Common
+	Expression *Common `json:"expression"`
+}
+
+func (e *YulExpressionStatement) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Expression": e.Expression,
+	}
+}
+
+func (e *YulExpressionStatement) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["expression"]); ok {
+		e.Expression = node
+	}
+}
+
+type YulIf struct {
+	Common
+	Body      *Common `json:"body"` // YulBlock
+	Condition *Common `json:"condition"`
+}
+
+func (i *YulIf) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Body":      i.Body,
+		"Condition": i.Condition,
+	}
+}
+
+func (i *YulIf) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["body"]); ok {
+		i.Body = node
+	}
+
+	if node, ok := nodeOf((*data)["condition"]); ok {
+		i.Condition = node
+	}
+}
+
+type YulSwitch struct {
+	Common
+	Cases      []*Common `json:"cases"` // YulCase
+	Expression *Common   `json:"expression"`
+}
+
+func (s *YulSwitch) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Cases":      s.Cases,
+		"Expression": s.Expression,
+	}
+}
+
+func (s *YulSwitch) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["cases"].([]interface{}); ok {
+		s.Cases = newYulNodes(data)
+	}
+
+	if node, ok := nodeOf((*data)["expression"]); ok {
+		s.Expression = node
+	}
+}
+
+// YulCase is a case of a switch, the default case has no value
+type YulCase struct {
+	Common
+	Body  *Common `json:"body"`  // YulBlock
+	Value *Common `json:"value"` // YulLiteral | "default"
+}
+
+func (c *YulCase) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Body":  c.Body,
+		"Value": c.Value,
+	}
+}
+
+func (c *YulCase) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["body"]); ok {
+		c.Body = node
+	}
+
+	if node, ok := nodeOf((*data)["value"]); ok {
+		c.Value = node
+	}
+}
+
+// YulForLoop is for { pre } condition { post } { body }
+type YulForLoop struct {
+	Common
+	Body      *Common `json:"body"`
+	Condition *Common `json:"condition"`
+	Post      *Common `json:"post"`
+	Pre       *Common `json:"pre"`
+}
+
+func (f *YulForLoop) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Body":      f.Body,
+		"Condition": f.Condition,
+		"Post":      f.Post,
+		"Pre":       f.Pre,
+	}
+}
+
+func (f *YulForLoop) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["body"]); ok {
+		f.Body = node
+	}
+
+	if node, ok := nodeOf((*data)["condition"]); ok {
+		f.Condition = node
+	}
+
+	if node, ok := nodeOf((*data)["post"]); ok {
+		f.Post = node
+	}
+
+	if node, ok := nodeOf((*data)["pre"]); ok {
+		f.Pre = node
+	}
+}
+
+type YulFunctionDefinition struct {
+	Common
+	Body            *Common        `json:"body"`
+	Name            string         `json:"name"`
+	Parameters      []YulTypedName `json:"parameters"`      // YulTypedName | null
+	ReturnVariables []YulTypedName `json:"returnVariables"` // YulTypedName | null
+}
+
+func (f *YulFunctionDefinition) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Body":            f.Body,
+		"Name":            f.Name,
+		"Parameters":      f.Parameters,
+		"ReturnVariables": f.ReturnVariables,
+	}
+}
+
+func (f *YulFunctionDefinition) Constructor(data *map[string]interface{}) {
+	if node, ok := nodeOf((*data)["body"]); ok {
+		f.Body = node
+	}
+
+	if data, ok := (*data)["name"].(string); ok {
+		f.Name = data
+	}
+
+	if data, ok := (*data)["parameters"].([]interface{}); ok {
+		f.Parameters = newYulTypedNames(data)
+	}
+
+	if data, ok := (*data)["returnVariables"].([]interface{}); ok {
+		f.ReturnVariables = newYulTypedNames(data)
+	}
+}
+
+// YulBreak, YulContinue and YulLeave only affect the control flow
+type YulBreak struct {
+	Common
+}
+
+func (b *YulBreak) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{}
+}
+
+func (b *YulBreak) Constructor(data *map[string]interface{}) {
+}
+
+type YulContinue struct {
+	Common
+}
+
+func (c *YulContinue) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{}
+}
+
+func (c *YulContinue) Constructor(data *map[string]interface{}) {
+}
+
+type YulLeave struct {
+	Common
+}
+
+func (l *YulLeave) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{}
+}
+
+func (l *YulLeave) Constructor(data *map[string]interface{}) {
+}
+
+// YulFunctionCall is the call of a builtin, e.g. sstore(slot, value), or of a
+// function defined in the assembly block
+type YulFunctionCall struct {
+	Common
+	Arguments    []*Common     `json:"arguments"`
+	FunctionName YulIdentifier `json:"functionName"`
+}
+
+func (c *YulFunctionCall) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Arguments":    c.Arguments,
+		"FunctionName": c.FunctionName,
+	}
+}
+
+func (c *YulFunctionCall) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["arguments"].([]interface{}); ok {
+		c.Arguments = newYulNodes(data)
+	}
+
+	if node, ok := nodeOf((*data)["functionName"]); ok {
+		c.FunctionName = *as[YulIdentifier](node)
+	}
+}
+
+// YulIdentifier is a variable of the assembly block, or a Solidity variable
+// given by the external references of the InlineAssembly, e.g. x.slot
+type YulIdentifier struct {
+	Common
+	Name        string `json:"name"`
+	Declaration int    `json:"-"` // the Solidity VariableDeclaration, 0 for a Yul variable
+	Suffix      string `json:"-"` // of the reference: "slot" | "offset" | "length" | ""
+}
+
+func (i *YulIdentifier) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"Name":        i.Name,
+		"Declaration": i.Declaration,
+		"Suffix":      i.Suffix,
+	}
+}
+
+func (i *YulIdentifier) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["name"].(string); ok {
+		i.Name = data
+	}
+}
+
+type YulLiteral struct {
+	Common
+	HexValue string `json:"hexValue"` // string | null, of the string literals
+	Kind     string `json:"kind"`     // "number" | "string" | "bool"
+	Type     string `json:"type"`     // "" unless typed, e.g. 1:u256
+	Value    string `json:"value"`
+}
+
+func (l *YulLiteral) Attributes() *map[string]interface{} {
+	return &map[string]interface{}{
+		"HexValue": l.HexValue,
+		"Kind":     l.Kind,
+		"Type":     l.Type,
+		"Value":    l.Value,
+	}
+}
+
+func (l *YulLiteral) Constructor(data *map[string]interface{}) {
+	if data, ok := (*data)["hexValue"].(string); ok {
+		l.HexValue = data
+	}
+
+	if data, ok := (*data)["kind"].(string); ok {
+		l.Kind = data
+	}
+
+	if data, ok := (*data)["type"].(string); ok {
+		l.Type = data
+	}
+
+	if data, ok := (*data)["value"].(string); ok {
+		l.Value = data
+	}
+}
+
+// YulSlots returns the YulIdentifiers of the storage slot of a Solidity variable
+// in a Yul expression, e.g. x.slot in sstore(add(x.slot, 1), v). The slot of a
+// mapping or array element is computed from the slot of the variable.
+func YulSlots(expr *Common) []*Common {
+	var res []*Common
+	Inspect(expr, func(node *Common) bool {
+		if ident, ok := node.ASTNode.(*YulIdentifier); ok && ident.Declaration != 0 && ident.Suffix == "slot" {
+			res = append(res, node)
+		}
+		return true
+	})
+	return res
+}
+
+// VariableName returns the name of the Solidity variable of a reference, e.g. x
+// for x.slot or x_slot
+func (i *YulIdentifier) VariableName() string {
+	if i.Suffix == "" {
+		return i.Name
+	}
+	name := strings.TrimSuffix(i.Name, "."+i.Suffix)
+	return strings.TrimSuffix(name, "_"+i.Suffix)
+}
+
+// YulSource renders a Yul expression, e.g. add(x.slot, 1)
+func YulSource(expr *Common) string {
+	if expr == nil {
+		return ""
+	}
+	switch n := expr.ASTNode.(type) {
+	case *YulIdentifier:
+		return n.Name
+	case *YulLiteral:
+		if n.Kind == "string" {
+			return strconv.Quote(n.Value)
+		}
+		return n.Value
+	case *YulFunctionCall:
+		var args []string
+		for _, arg := range n.Arguments {
+			args = append(args, YulSource(arg))
+		}
+		return n.FunctionName.Name + "(" + strings.Join(args, ", ") + ")"
+	}
+	return expr.NodeType
+}
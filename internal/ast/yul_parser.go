@@ -0,0 +1,378 @@
+package ast
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseYul builds the YulBlock of the source of an assembly block, the operations
+// of an InlineAssembly of solc < 0.6. The nodes are located at src, the location
+// of the block: the operations are printed again by solc, their offsets are not
+// those of the source file. The instructions of the EVM assembly of solc < 0.5,
+// e.g. labels, jumps and stack assignments (=: x), are not supported.
+func ParseYul(source string, src string) (*Common, error) {
+	tokens, err := yulTokens(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &yulParser{tokens: tokens, src: src}
+	block, err := p._block()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q after the assembly block", p.tokens[p.pos])
+	}
+	return block, nil
+}
+
+// yulTokens splits Yul source into tokens: punctuation, := and ->, identifiers,
+// numbers and quoted strings. Comments are skipped.
+func yulTokens(source string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(source[i:], "//"):
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case strings.HasPrefix(source[i:], ":=") || strings.HasPrefix(source[i:], "->"):
+			tokens = append(tokens, source[i:i+2])
+			i += 2
+		case strings.ContainsRune("{}(),:", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(source) && source[j] != c {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(source) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, source[i:j+1])
+			i = j + 1
+		case isYulIdentifierChar(c):
+			j := i
+			for j < len(source) && isYulIdentifierChar(source[j]) {
+				j++
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+// isYulIdentifierChar reports whether c may be part of an identifier or a number,
+// e.g. x.slot, x_offset or 0x40
+func isYulIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+type yulParser struct {
+	tokens []string
+	pos    int
+	src    string
+}
+
+func (p *yulParser) _peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *yulParser) _next() string {
+	token := p._peek()
+	p.pos++
+	return token
+}
+
+func (p *yulParser) _expect(token string) error {
+	if next := p._next(); next != token {
+		if next == "" {
+			return fmt.Errorf("expected %q at the end of the assembly", token)
+		}
+		return fmt.Errorf("expected %q, got %q", token, next)
+	}
+	return nil
+}
+
+func (p *yulParser) _node(nodeType string, node ASTNode) *Common {
+	return &Common{
+		NodeType: nodeType,
+		Src:      p.src,
+		ASTNode:  node,
+	}
+}
+
+func (p *yulParser) _block() (*Common, error) {
+	if err := p._expect("{"); err != nil {
+		return nil, err
+	}
+	block := &YulBlock{}
+	for p._peek() != "}" {
+		if p._peek() == "" {
+			return nil, p._expect("}")
+		}
+		stmt, err := p._statement()
+		if err != nil {
+			return nil, err
+		}
+		block.Statements = append(block.Statements, stmt)
+	}
+	p.pos++
+	return p._node("YulBlock", block), nil
+}
+
+func (p *yulParser) _statement() (*Common, error) {
+	switch p._peek() {
+	case "{":
+		return p._block()
+	case "let":
+		p.pos++
+		decl := &YulVariableDeclaration{}
+		var err error
+		if decl.Variables, err = p._typedNames(); err != nil {
+			return nil, err
+		}
+		if p._peek() == ":=" {
+			p.pos++
+			if decl.Value, err = p._expression(); err != nil {
+				return nil, err
+			}
+		}
+		return p._node("YulVariableDeclaration", decl), nil
+	case "if":
+		p.pos++
+		condition, err := p._expression()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p._block()
+		if err != nil {
+			return nil, err
+		}
+		return p._node("YulIf", &YulIf{Condition: condition, Body: body}), nil
+	case "switch":
+		return p._switch()
+	case "for":
+		p.pos++
+		loop := &YulForLoop{}
+		var err error
+		if loop.Pre, err = p._block(); err != nil {
+			return nil, err
+		}
+		if loop.Condition, err = p._expression(); err != nil {
+			return nil, err
+		}
+		if loop.Post, err = p._block(); err != nil {
+			return nil, err
+		}
+		if loop.Body, err = p._block(); err != nil {
+			return nil, err
+		}
+		return p._node("YulForLoop", loop), nil
+	case "function":
+		return p._functionDefinition()
+	case "break":
+		p.pos++
+		return p._node("YulBreak", &YulBreak{}), nil
+	case "continue":
+		p.pos++
+		return p._node("YulContinue", &YulContinue{}), nil
+	case "leave":
+		p.pos++
+		return p._node("YulLeave", &YulLeave{}), nil
+	}
+
+	expr, err := p._expression()
+	if err != nil {
+		return nil, err
+	}
+	if p._peek() != ":=" && p._peek() != "," {
+		return p._node("YulExpressionStatement", &YulExpressionStatement{Expression: expr}), nil
+	}
+
+	// x, y := value
+	assignment := &YulAssignment{VariableNames: []*Common{expr}}
+	for p._peek() == "," {
+		p.pos++
+		name, err := p._expression()
+		if err != nil {
+			return nil, err
+		}
+		assignment.VariableNames = append(assignment.VariableNames, name)
+	}
+	for _, name := range assignment.VariableNames {
+		if _, ok := name.ASTNode.(*YulIdentifier); !ok {
+			return nil, fmt.Errorf("cannot assign to a %s", name.NodeType)
+		}
+	}
+	if err := p._expect(":="); err != nil {
+		return nil, err
+	}
+	if assignment.Value, err = p._expression(); err != nil {
+		return nil, err
+	}
+	return p._node("YulAssignment", assignment), nil
+}
+
+// _switch parses switch expr case literal { ... } ... default { ... }
+func (p *yulParser) _switch() (*Common, error) {
+	p.pos++
+	expression, err := p._expression()
+	if err != nil {
+		return nil, err
+	}
+	switchStmt := &YulSwitch{Expression: expression}
+	for p._peek() == "case" || p._peek() == "default" {
+		yulCase := &YulCase{}
+		if p._next() == "case" {
+			if yulCase.Value, err = p._expression(); err != nil {
+				return nil, err
+			}
+			if _, ok := yulCase.Value.ASTNode.(*YulLiteral); !ok {
+				return nil, fmt.Errorf("expected a literal after case")
+			}
+		}
+		if yulCase.Body, err = p._block(); err != nil {
+			return nil, err
+		}
+		switchStmt.Cases = append(switchStmt.Cases, p._node("YulCase", yulCase))
+	}
+	if len(switchStmt.Cases) == 0 {
+		return nil, fmt.Errorf("expected a case after switch")
+	}
+	return p._node("YulSwitch", switchStmt), nil
+}
+
+// _functionDefinition parses function f(a, b) -> c { ... }
+func (p *yulParser) _functionDefinition() (*Common, error) {
+	p.pos++
+	funcDef := &YulFunctionDefinition{Name: p._next()}
+	if !isYulName(funcDef.Name) {
+		return nil, fmt.Errorf("expected the name of a function, got %q", funcDef.Name)
+	}
+	if err := p._expect("("); err != nil {
+		return nil, err
+	}
+	var err error
+	if p._peek() != ")" {
+		if funcDef.Parameters, err = p._typedNames(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p._expect(")"); err != nil {
+		return nil, err
+	}
+	if p._peek() == "->" {
+		p.pos++
+		if funcDef.ReturnVariables, err = p._typedNames(); err != nil {
+			return nil, err
+		}
+	}
+	if funcDef.Body, err = p._block(); err != nil {
+		return nil, err
+	}
+	return p._node("YulFunctionDefinition", funcDef), nil
+}
+
+// _typedNames parses a, b:u256
+func (p *yulParser) _typedNames() ([]YulTypedName, error) {
+	var res []YulTypedName
+	for {
+		name := p._next()
+		if !isYulName(name) {
+			return nil, fmt.Errorf("expected a name, got %q", name)
+		}
+		typed := YulTypedName{Name: name}
+		if p._peek() == ":" {
+			p.pos++
+			typed.Type = p._next()
+		}
+		res = append(res, typed)
+		if p._peek() != "," {
+			return res, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *yulParser) _expression() (*Common, error) {
+	token := p._next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("expected an expression at the end of the assembly")
+	case token == "true" || token == "false":
+		return p._literal(&YulLiteral{Kind: "bool", Value: token})
+	case token[0] == '"' || token[0] == '\'':
+		value, err := strconv.Unquote(`"` + strings.ReplaceAll(token[1:len(token)-1], `"`, `\"`) + `"`)
+		if err != nil {
+			value = token[1 : len(token)-1]
+		}
+		return p._literal(&YulLiteral{Kind: "string", Value: value, HexValue: hex.EncodeToString([]byte(value))})
+	case token[0] >= '0' && token[0] <= '9':
+		return p._literal(&YulLiteral{Kind: "number", Value: token})
+	case !isYulName(token):
+		return nil, fmt.Errorf("unexpected %q where an expression is expected", token)
+	}
+
+	if p._peek() != "(" {
+		return p._node("YulIdentifier", &YulIdentifier{Name: token}), nil
+	}
+	p.pos++
+	call := &YulFunctionCall{FunctionName: YulIdentifier{Name: token}}
+	for p._peek() != ")" {
+		if len(call.Arguments) > 0 {
+			if err := p._expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p._expression()
+		if err != nil {
+			return nil, err
+		}
+		call.Arguments = append(call.Arguments, arg)
+	}
+	p.pos++
+	return p._node("YulFunctionCall", call), nil
+}
+
+// _literal reads the type of a typed literal, e.g. 1:u256
+func (p *yulParser) _literal(literal *YulLiteral) (*Common, error) {
+	if p._peek() == ":" {
+		p.pos++
+		literal.Type = p._next()
+	}
+	return p._node("YulLiteral", literal), nil
+}
+
+// isYulName reports whether the token is an identifier and not a keyword
+func isYulName(token string) bool {
+	if token == "" || token[0] >= '0' && token[0] <= '9' || !isYulIdentifierChar(token[0]) {
+		return false
+	}
+	switch token {
+	case "let", "if", "switch", "case", "default", "for", "function", "break", "continue", "leave", "true", "false":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,288 @@
+package callgraph
+
+import (
+	"strings"
+	AST "txtracker/internal/ast"
+	"txtracker/internal/logger"
+	ST "txtracker/internal/symbol_table"
+)
+
+// LowLevelMembers are the members of address performing a message call
+var LowLevelMembers = map[string]bool{
+	"call":         true,
+	"send":         true,
+	"transfer":     true,
+	"delegatecall": true,
+	"callcode":     true,
+	"staticcall":   true,
+}
+
+func NewCallGraph(root *AST.Common, symbolTable *ST.GlobalSymbolTable) *CallGraph {
+	cg := &CallGraph{
+		nodes:       make(map[int]*Node),
+		symbolTable: symbolTable,
+	}
+
+	cg._collectNodes(root)
+	// the functions of the imported units added as callees have no edges of
+	// their own
+	n := len(cg.Nodes)
+	for _, node := range cg.Nodes[:n] {
+		cg._collectEdges(node)
+	}
+
+	logger.Info.Println("Call graph constructed")
+	return cg
+}
+
+// LookupNode returns the node of the FunctionDefinition or ModifierDefinition with the given AST ID
+func (cg *CallGraph) LookupNode(id int) *Node {
+	return cg.nodes[id]
+}
+
+// Reachable returns every node reachable from the given one through call
+// edges of the given kinds, the node itself excluded
+func (cg *CallGraph) Reachable(from *Node, kinds ...CallKind) []*Node {
+	var res []*Node
+	visited := map[*Node]bool{from: true}
+	stack := []*Node{from}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, edge := range node.Callees {
+			if edge.Callee == nil || visited[edge.Callee] || !hasKind(kinds, edge.Kind) {
+				continue
+			}
+			visited[edge.Callee] = true
+			res = append(res, edge.Callee)
+			stack = append(stack, edge.Callee)
+		}
+	}
+	return res
+}
+
+func hasKind(kinds []CallKind, kind CallKind) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (cg *CallGraph) _collectNodes(root *AST.Common) {
+	for _, child := range root.Children {
+		switch child.NodeType {
+		case "ContractDefinition":
+			cg._collectNodes(child)
+		case "FunctionDefinition", "ModifierDefinition":
+			cg._addNode(child)
+		}
+	}
+}
+
+func (cg *CallGraph) _addNode(def *AST.Common) *Node {
+	node := &Node{
+		ID:         def.ID,
+		Definition: def,
+	}
+	if contract := cg.symbolTable.LookupContract(def); contract != nil {
+		node.Contract = contract.ASTNode.(*AST.ContractDefinition).Name
+	}
+
+	switch n := def.ASTNode.(type) {
+	case *AST.FunctionDefinition:
+		node.Name = n.DisplayName()
+	case *AST.ModifierDefinition:
+		node.Name = n.Name
+	}
+
+	cg.Nodes = append(cg.Nodes, node)
+	cg.nodes[def.ID] = node
+	return node
+}
+
+func (cg *CallGraph) _addEdge(edge *Edge) {
+	cg.Edges = append(cg.Edges, edge)
+	edge.Caller.Callees = append(edge.Caller.Callees, edge)
+	if edge.Callee != nil {
+		edge.Callee.Callers = append(edge.Callee.Callers, edge)
+	}
+}
+
+func (cg *CallGraph) _collectEdges(node *Node) {
+	var body *AST.Block
+	switch def := node.Definition.ASTNode.(type) {
+	case *AST.FunctionDefinition:
+		for _, mi := range def.Modifiers {
+			cg._addModifierEdge(node, &mi)
+		}
+		body = &def.Body
+	case *AST.ModifierDefinition:
+		body = &def.Body
+	}
+
+	AST.InspectBlock(body, func(n *AST.Common) bool {
+		if n.NodeType == "FunctionCall" {
+			if edge := cg._resolveCall(node, n); edge != nil {
+				cg._addEdge(edge)
+			}
+		}
+		return true
+	})
+}
+
+func (cg *CallGraph) _addModifierEdge(caller *Node, mi *AST.ModifierInvocation) {
+	id, ok := mi.ReferencedDeclaration()
+	if !ok {
+		return
+	}
+	// base constructor specifiers reference a ContractDefinition instead
+	callee := cg._lookupNode(id)
+	if callee == nil || callee.Definition.NodeType != "ModifierDefinition" {
+		return
+	}
+	cg._addEdge(&Edge{
+		Caller: caller,
+		Callee: callee,
+		Kind:   Modifier,
+		Name:   callee.Name,
+		Src:    mi.ModifierName.Src,
+	})
+}
+
+// _resolveCall classifies a FunctionCall node, it returns nil for builtins,
+// type conversions, struct constructors and events
+func (cg *CallGraph) _resolveCall(caller *Node, call *AST.Common) *Edge {
+	funCall := call.ASTNode.(*AST.FunctionCall)
+	if funCall.Kind == AST.FunctionCallKind_TypeConversion || funCall.Kind == AST.FunctionCallKind_StructConstructorCall {
+		return nil
+	}
+
+	callee := UnwrapCallee(funCall.Expression)
+	if callee == nil {
+		return nil
+	}
+
+	edge := &Edge{Caller: caller, Src: call.Src}
+	switch callee.NodeType {
+	case "Identifier":
+		ident := callee.ASTNode.(*AST.Identifier)
+		edge.Name = ident.Name
+		edge.Callee = cg._lookupFunction(ident.ReferencedDeclaration)
+		if edge.Callee == nil {
+			return nil
+		}
+		edge.Kind = Internal
+		if cg._isLibrary(edge.Callee) {
+			edge.Kind = Library
+		}
+
+	case "MemberAccess":
+		member := callee.ASTNode.(*AST.MemberAccess)
+		edge.Name = member.MemberName
+		edge.Callee = cg._lookupFunction(member.ReferencedDeclaration)
+		baseType := member.Expression.GetTypeDescriptions().TypeIdentifier
+
+		switch {
+		case edge.Callee != nil && cg._isLibrary(edge.Callee):
+			edge.Kind = Library
+		case edge.Callee != nil && isSuper(member.Expression):
+			edge.Kind = Super
+		case edge.Callee != nil:
+			edge.Kind = External
+		case cg._isStateVariable(member.ReferencedDeclaration):
+			// getter of a public state variable of another contract
+			edge.Kind = External
+		case LowLevelMembers[member.MemberName] && strings.HasPrefix(baseType, "t_address"):
+			edge.Kind = LowLevel
+		case strings.HasPrefix(baseType, "t_contract"):
+			edge.Kind = External
+		case member.ReferencedDeclaration > 0 && strings.HasPrefix(member.TypeDescriptions.TypeIdentifier, "t_function_internal"):
+			// a library function of a unit out of the symbol table, e.g.
+			// a.add(b) through `using SafeMath for uint`
+			edge.Kind = Library
+		case member.ReferencedDeclaration > 0 && strings.HasPrefix(member.TypeDescriptions.TypeIdentifier, "t_function_external"):
+			edge.Kind = External
+		default:
+			// builtin members like push, encode or keccak256
+			return nil
+		}
+
+	default:
+		return nil
+	}
+	return edge
+}
+
+func (cg *CallGraph) _lookupFunction(id int) *Node {
+	node := cg._lookupNode(id)
+	if node == nil || node.Definition.NodeType != "FunctionDefinition" {
+		return nil
+	}
+	return node
+}
+
+// _lookupNode returns the node of a function or a modifier, that of an imported
+// source unit is added on its first call, e.g. a library or a base contract
+func (cg *CallGraph) _lookupNode(id int) *Node {
+	if node, ok := cg.nodes[id]; ok {
+		return node
+	}
+	decl := cg.symbolTable.LookupDeclaration(id)
+	if decl == nil || (decl.NodeType != "FunctionDefinition" && decl.NodeType != "ModifierDefinition") {
+		return nil
+	}
+	return cg._addNode(decl)
+}
+
+func (cg *CallGraph) _isLibrary(node *Node) bool {
+	contract := cg.symbolTable.LookupContract(node.Definition)
+	return contract != nil && contract.ASTNode.(*AST.ContractDefinition).ContractKind == AST.ContractKind_Library
+}
+
+func (cg *CallGraph) _isStateVariable(id int) bool {
+	decl := cg.symbolTable.LookupDeclaration(id)
+	if decl == nil || decl.NodeType != "VariableDeclaration" {
+		return false
+	}
+	return decl.ASTNode.(*AST.VariableDeclaration).StateVariable
+}
+
+// isSuper reports whether expr is `super` or the name of a base contract, as in Base.f()
+func isSuper(expr *AST.Common) bool {
+	ident, ok := expr.ASTNode.(*AST.Identifier)
+	if !ok {
+		return false
+	}
+	if ident.Name == "super" {
+		return true
+	}
+	return strings.HasPrefix(ident.TypeDescriptions.TypeIdentifier, "t_type$_t_contract")
+}
+
+// UnwrapCallee strips the call options of a callee expression, e.g.
+// addr.call.value(1)(...) of solc < 0.7 and addr.call{value: 1}(...) are
+// unwrapped to addr.call
+func UnwrapCallee(expr *AST.Common) *AST.Common {
+	for expr != nil {
+		// f{value: v}(...) of solc >= 0.6.2
+		if options, ok := expr.ASTNode.(*AST.FunctionCallOptions); ok {
+			expr = options.Expression
+			continue
+		}
+		funCall, ok := expr.ASTNode.(*AST.FunctionCall)
+		if !ok {
+			return expr
+		}
+		member, ok := funCall.Expression.ASTNode.(*AST.MemberAccess)
+		if !ok || (member.MemberName != "value" && member.MemberName != "gas") {
+			return expr
+		}
+		expr = member.Expression
+	}
+	return expr
+}
@@ -0,0 +1,73 @@
+package callgraph
+
+import (
+	AST "txtracker/internal/ast"
+	ST "txtracker/internal/symbol_table"
+)
+
+type CallKind int
+
+const (
+	// Internal: f(...) resolved to a function of the same contract or a base
+	Internal CallKind = iota
+	// Library: SafeMath.add(a, b) or a.add(b) through `using SafeMath for uint`
+	Library
+	// Super: super.f(...) or Base.f(...)
+	Super
+	// External: token.transfer(...) or this.f(...), a message call to a contract
+	External
+	// LowLevel: address.call / send / transfer / delegatecall / callcode / staticcall
+	LowLevel
+	// Modifier: a modifier invoked by a function
+	Modifier
+)
+
+func (k CallKind) String() string {
+	return [...]string{
+		"internal",
+		"library",
+		"super",
+		"external",
+		"low-level",
+		"modifier",
+	}[k]
+}
+
+type CallGraph struct {
+	Nodes       []*Node
+	Edges       []*Edge
+	nodes       map[int]*Node
+	symbolTable *ST.GlobalSymbolTable
+}
+
+// Node is a FunctionDefinition or a ModifierDefinition
+type Node struct {
+	ID         int
+	Contract   string
+	Name       string
+	Definition *AST.Common
+	Callees    []*Edge
+	Callers    []*Edge
+}
+
+func (n *Node) String() string {
+	return n.Contract + "::" + n.Name
+}
+
+// Edge is a call site. Callee is nil when the call cannot be resolved to a
+// definition, e.g. low-level calls, getters of public state variables or the
+// functions of a source unit out of the symbol table.
+type Edge struct {
+	Caller *Node
+	Callee *Node
+	Kind   CallKind
+	Name   string // name of the called member, set for every edge
+	Src    string // location of the call site
+}
+
+func (e *Edge) CalleeName() string {
+	if e.Callee != nil {
+		return e.Callee.String()
+	}
+	return e.Name
+}
@@ -0,0 +1,357 @@
+package cfg
+
+import (
+	"strings"
+	AST "txtracker/internal/ast"
+)
+
+// _isAuthorize reports whether the condition of a require or an if checks the
+// caller, the statement is then an Authorize guard: msg.sender or tx.origin
+// compared to a state variable, looked up in a role mapping, or passed to an
+// internal or a view function returning such a check, e.g.
+//
+//	require(msg.sender == owner)
+//	require(admins[msg.sender])
+//	require(hasRole(MINTER_ROLE, msg.sender))
+//	if (!isOwner()) revert();
+//
+// An if is only a guard when it stops the caller failing the check, see
+// _isAuthorizeIf. A call that only takes the caller as an argument, e.g.
+// require(token.transferFrom(msg.sender, address(this), amount)), is no check.
+func (cfg *CFG) _isAuthorize(condition *AST.Common) bool {
+	return cfg._checksCaller(condition, nil, make(map[*AST.FunctionDefinition]bool))
+}
+
+// _checksCaller reports whether an expression checks the caller, callers are the
+// parameters of the function being followed that hold the caller, e.g. account
+// in hasRole(role, account) called as hasRole(MINTER_ROLE, msg.sender)
+func (cfg *CFG) _checksCaller(expr *AST.Common, callers map[int]bool, visited map[*AST.FunctionDefinition]bool) bool {
+	if expr == nil {
+		return false
+	}
+
+	switch n := expr.ASTNode.(type) {
+	case *AST.TupleExpression:
+		// (msg.sender == owner)
+		return len(n.Components) == 1 && cfg._checksCaller(n.Components[0], callers, visited)
+	case *AST.UnaryOperation:
+		return n.Operator == AST.UnaryOperator_LogicalNot && cfg._checksCaller(n.SubExpression, callers, visited)
+	case *AST.BinaryOperation:
+		switch n.Operator {
+		case AST.Operator_And, AST.Operator_Or:
+			return cfg._checksCaller(n.LeftExpression, callers, visited) || cfg._checksCaller(n.RightExpression, callers, visited)
+		case AST.Operator_StrictEqual, AST.Operator_StrictNotEqual:
+			return (isCaller(n.LeftExpression, callers) && cfg._readsState(n.RightExpression)) ||
+				(isCaller(n.RightExpression, callers) && cfg._readsState(n.LeftExpression))
+		}
+	case *AST.IndexAccess:
+		// roles[role][msg.sender], the balance in balances[msg.sender] is not a permission
+		if n.TypeDescriptions.TypeString != "bool" {
+			return false
+		}
+		for expr != nil {
+			index, ok := expr.ASTNode.(*AST.IndexAccess)
+			if !ok {
+				break
+			}
+			if isCaller(index.IndexExpression, callers) {
+				return cfg._readsState(index.BaseExpression)
+			}
+			expr = index.BaseExpression
+		}
+	case *AST.FunctionCall:
+		if n.Kind != AST.FunctionCallKind_FunctionCall || n.TypeDescriptions.TypeString != "bool" {
+			return false
+		}
+		// isOwner() { return msg.sender == owner; }
+		return cfg._calleeChecksCaller(n, callers, visited)
+	}
+	return false
+}
+
+// _isAuthorizeIf reports whether an if statement checks the caller and stops the
+// caller that fails the check, e.g. if (msg.sender != owner) revert(); an if
+// whose branches both go on, as if (!admins[msg.sender]) { fee = 0; }, guards
+// nothing past it
+func (cfg *CFG) _isAuthorizeIf(stmt *AST.IfStatement) bool {
+	return stops(stmt) && cfg._isAuthorize(stmt.Condition)
+}
+
+// stops reports whether a branch of an if statement ends in a revert or a
+// return, or whether its true branch holds the body of the function as in the
+// modifier if (msg.sender == owner) _;
+func stops(stmt *AST.IfStatement) bool {
+	if exits(stmt.TrueBody) || exits(stmt.FalseBody) {
+		return true
+	}
+	return stmt.FalseBody == nil && holdsPlaceholder(stmt.TrueBody)
+}
+
+// exits reports whether a statement never goes on to the next one: it ends in a
+// return, a revert or a throw, or it is an if whose branches both exit
+func exits(stmt AST.Statement) bool {
+	if stmt == nil {
+		return false
+	}
+	switch n := (*AST.Common)(stmt).ASTNode.(type) {
+	case *AST.Return, *AST.RevertStatement, *AST.Throw:
+		return true
+	case *AST.Block:
+		return len(n.Statements) > 0 && exits(n.Statements[len(n.Statements)-1])
+	case *AST.UncheckedBlock:
+		return len(n.Statements) > 0 && exits(n.Statements[len(n.Statements)-1])
+	case *AST.IfStatement:
+		return exits(n.TrueBody) && exits(n.FalseBody)
+	case *AST.ExpressionStatement:
+		call, ok := n.Expression.ASTNode.(*AST.FunctionCall)
+		if !ok {
+			return false
+		}
+		ident, ok := call.Expression.ASTNode.(*AST.Identifier)
+		return ok && ident.Name == "revert"
+	}
+	return false
+}
+
+// holdsPlaceholder reports whether a statement is or holds the placeholder _ of
+// a modifier
+func holdsPlaceholder(stmt AST.Statement) bool {
+	if stmt == nil {
+		return false
+	}
+	res := false
+	AST.Inspect((*AST.Common)(stmt), func(node *AST.Common) bool {
+		if _, ok := node.ASTNode.(*AST.PlaceholderStatement); ok {
+			res = true
+		}
+		return !res
+	})
+	return res
+}
+
+// _isAuthorizeCall reports whether a call statement checks the caller in the
+// called function, e.g. _checkOwner() in the onlyOwner modifier of OpenZeppelin:
+// a require or an if of its body is an authorization
+func (cfg *CFG) _isAuthorizeCall(stmt *AST.ExpressionStatement) bool {
+	call, ok := stmt.Expression.ASTNode.(*AST.FunctionCall)
+	if !ok {
+		return false
+	}
+	return cfg._callChecksCaller(call, nil, make(map[*AST.FunctionDefinition]bool))
+}
+
+func (cfg *CFG) _callChecksCaller(call *AST.FunctionCall, callers map[int]bool, visited map[*AST.FunctionDefinition]bool) bool {
+	funcDef := cfg._callee(call, visited)
+	if funcDef == nil {
+		return false
+	}
+	callers = _boundCallers(funcDef, call, callers)
+
+	for _, stmt := range funcDef.Body.Statements {
+		switch n := stmt.ASTNode.(type) {
+		case *AST.IfStatement:
+			if stops(n) && cfg._checksCaller(n.Condition, callers, visited) {
+				return true
+			}
+		case *AST.ExpressionStatement:
+			inner, ok := n.Expression.ASTNode.(*AST.FunctionCall)
+			if !ok {
+				continue
+			}
+			if cfg._isRequire(n) {
+				if len(inner.Arguments) > 0 && cfg._checksCaller(inner.Arguments[0], callers, visited) {
+					return true
+				}
+			} else if cfg._callChecksCaller(inner, callers, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// _callee returns the definition of a function called without a message call,
+// e.g. isOwner() or super.isOwner(), or of a view function of another contract,
+// nil if it is not implemented or was already visited. A call that may modify
+// the state of another contract, e.g. token.transfer(msg.sender, amount), is not
+// followed.
+func (cfg *CFG) _callee(call *AST.FunctionCall, visited map[*AST.FunctionDefinition]bool) *AST.FunctionDefinition {
+	member, ok := call.Expression.ASTNode.(*AST.MemberAccess)
+	if !ok {
+		return cfg._internalCallee(call, visited)
+	}
+	if !isInternalMember(member) && !isViewMember(member) {
+		return nil
+	}
+	decl := cfg.symbolTable.LookupDeclaration(member.ReferencedDeclaration)
+	if decl == nil {
+		return nil
+	}
+	funcDef, ok := decl.ASTNode.(*AST.FunctionDefinition)
+	if !ok || !funcDef.Implemented || visited[funcDef] {
+		return nil
+	}
+	visited[funcDef] = true
+	return funcDef
+}
+
+// _internalCallee returns the definition of a function called by its name, nil
+// if it is not a function or was already visited
+func (cfg *CFG) _internalCallee(call *AST.FunctionCall, visited map[*AST.FunctionDefinition]bool) *AST.FunctionDefinition {
+	ident, ok := call.Expression.ASTNode.(*AST.Identifier)
+	if !ok {
+		return nil
+	}
+	decl := cfg.symbolTable.LookupDeclaration(ident.ReferencedDeclaration)
+	if decl == nil {
+		return nil
+	}
+	funcDef, ok := decl.ASTNode.(*AST.FunctionDefinition)
+	if !ok || visited[funcDef] {
+		return nil
+	}
+	visited[funcDef] = true
+	return funcDef
+}
+
+// _calleeChecksCaller reports whether an internal or a view function returns a check of the caller
+func (cfg *CFG) _calleeChecksCaller(call *AST.FunctionCall, callers map[int]bool, visited map[*AST.FunctionDefinition]bool) bool {
+	funcDef := cfg._callee(call, visited)
+	if funcDef == nil {
+		return false
+	}
+	callers = _boundCallers(funcDef, call, callers)
+
+	res := false
+	AST.InspectBlock(&funcDef.Body, func(node *AST.Common) bool {
+		if ret, ok := node.ASTNode.(*AST.Return); ok && cfg._checksCaller(ret.Expression, callers, visited) {
+			res = true
+		}
+		return !res
+	})
+	return res
+}
+
+// _boundCallers returns the parameters of the called function that receive the
+// caller, e.g. account for hasRole(MINTER_ROLE, msg.sender)
+func _boundCallers(funcDef *AST.FunctionDefinition, call *AST.FunctionCall, callers map[int]bool) map[int]bool {
+	params := funcDef.Parameters.Parameters
+	// the first parameter of a library function attached with using for is
+	// the receiver of the call, e.g. roles in roles.has(msg.sender)
+	offset := len(params) - len(call.Arguments)
+	if offset < 0 {
+		return nil
+	}
+	res := make(map[int]bool)
+	for i, arg := range call.Arguments {
+		if isCaller(arg, callers) {
+			res[params[offset+i].ID] = true
+		}
+	}
+	if offset == 1 {
+		if member, ok := call.Expression.ASTNode.(*AST.MemberAccess); ok && isCaller(member.Expression, callers) {
+			res[params[0].ID] = true
+		}
+	}
+	return res
+}
+
+// _readsState reports whether an expression reads a state variable, directly,
+// through the getter of a public one or through an internal or a view function
+// reading one, e.g. owner()
+func (cfg *CFG) _readsState(expr *AST.Common) bool {
+	visited := make(map[*AST.FunctionDefinition]bool)
+	res := false
+	AST.Inspect(expr, func(node *AST.Common) bool {
+		if !res && cfg._isStateRead(node, visited) {
+			res = true
+		}
+		return !res
+	})
+	return res
+}
+
+func (cfg *CFG) _isStateRead(node *AST.Common, visited map[*AST.FunctionDefinition]bool) bool {
+	switch n := node.ASTNode.(type) {
+	case *AST.Identifier:
+		return cfg._isStateDeclaration(n.ReferencedDeclaration)
+	case *AST.FunctionCall:
+		if n.Kind != AST.FunctionCallKind_FunctionCall {
+			return false
+		}
+		member, ok := n.Expression.ASTNode.(*AST.MemberAccess)
+		if ok && cfg._isStateDeclaration(member.ReferencedDeclaration) {
+			// token.owner() of a public state variable
+			return true
+		}
+		funcDef := cfg._callee(n, visited)
+		if funcDef == nil {
+			// a view function of an interface, e.g. registry.owner()
+			return ok && isViewMember(member) && !strings.Contains(member.TypeDescriptions.TypeString, " pure")
+		}
+		res := false
+		AST.InspectBlock(&funcDef.Body, func(node *AST.Common) bool {
+			if !res && cfg._isStateRead(node, visited) {
+				res = true
+			}
+			return !res
+		})
+		return res
+	}
+	return false
+}
+
+func (cfg *CFG) _isStateDeclaration(id int) bool {
+	decl := cfg.symbolTable.LookupDeclaration(id)
+	if decl == nil {
+		return false
+	}
+	vd, ok := decl.ASTNode.(*AST.VariableDeclaration)
+	return ok && vd.StateVariable
+}
+
+// isInternalMember reports whether a member names a function called without a
+// message call, e.g. super.isOwner() or a library function
+func isInternalMember(member *AST.MemberAccess) bool {
+	return strings.HasPrefix(member.TypeDescriptions.TypeIdentifier, "t_function_internal")
+}
+
+// isViewMember reports whether a member names a function that cannot modify the
+// state, e.g. function (address) view external returns (bool)
+func isViewMember(member *AST.MemberAccess) bool {
+	for _, mutability := range strings.Fields(member.TypeDescriptions.TypeString) {
+		if mutability == string(AST.StateMutability_View) || mutability == string(AST.StateMutability_Pure) || mutability == "constant" {
+			return true
+		}
+	}
+	return false
+}
+
+// isCaller reports whether the expression is msg.sender, tx.origin, _msgSender()
+// or one of the callers parameters, possibly converted to another type as in
+// address(msg.sender)
+func isCaller(expr *AST.Common, callers map[int]bool) bool {
+	if expr == nil {
+		return false
+	}
+
+	switch n := expr.ASTNode.(type) {
+	case *AST.Identifier:
+		return callers[n.ReferencedDeclaration]
+	case *AST.MemberAccess:
+		ident, ok := n.Expression.ASTNode.(*AST.Identifier)
+		if !ok {
+			return false
+		}
+		return (ident.Name == "msg" && n.MemberName == "sender") || (ident.Name == "tx" && n.MemberName == "origin")
+	case *AST.FunctionCall:
+		if n.Kind == AST.FunctionCallKind_TypeConversion && len(n.Arguments) == 1 {
+			return isCaller(n.Arguments[0], callers)
+		}
+		ident, ok := n.Expression.ASTNode.(*AST.Identifier)
+		return ok && ident.Name == "_msgSender"
+	case *AST.TupleExpression:
+		return len(n.Components) == 1 && isCaller(n.Components[0], callers)
+	}
+	return false
+}
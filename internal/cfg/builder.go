@@ -0,0 +1,403 @@
+package cfg
+
+import (
+	AST "txtracker/internal/ast"
+)
+
+// builder holds the state of the construction of a single function CFG
+type builder struct {
+	cfg      *CFG
+	function *Function
+	// innermost loop last
+	loops []loopTargets
+	// where `return` jumps to
+	returnTarget *Block
+	// the modifier chain wrapping the function body, outermost first
+	modifiers []*modifier
+	body      *AST.Block
+	// index in modifiers of the modifier being built, len(modifiers) for the body
+	depth int
+}
+
+// modifier is a ModifierInvocation resolved to its ModifierDefinition
+type modifier struct {
+	invocation *AST.ModifierInvocation
+	definition *AST.ModifierDefinition
+}
+
+type loopTargets struct {
+	breakTarget    *Block
+	continueTarget *Block
+}
+
+func (cfg *CFG) _newBuilder(f *Function) *builder {
+	b := &builder{
+		cfg:      cfg,
+		function: f,
+	}
+	f.Entry = b._newBlock("entry")
+	f.Exit = b._newBlock("exit")
+	b.returnTarget = f.Exit
+	return b
+}
+
+// _build lays out the modifiers and the body of the function between its entry
+// and exit blocks. The body is spliced in at every placeholder of the innermost modifier.
+func (b *builder) _build(modifiers []*modifier, body *AST.Block) {
+	b.modifiers = modifiers
+	b.body = body
+
+	first := b._newBlock(b._chainLabel(0))
+	b._connect(b.function.Entry, first, Unconditional)
+
+	if end := b._buildChain(0, first); end != nil {
+		b._connect(end, b.function.Exit, Unconditional)
+	}
+	b._keepExitLast()
+}
+
+// _buildGetter lays out the statement of a getter between the entry and exit blocks
+func (b *builder) _buildGetter(stmt *Statement) {
+	body := b._newBlock("body")
+	b._connect(b.function.Entry, body, Unconditional)
+	body.Collect(stmt)
+	b._connect(body, b.function.Exit, Unconditional)
+	b._keepExitLast()
+}
+
+func (b *builder) _keepExitLast() {
+	for i, block := range b.function.Blocks {
+		if block == b.function.Exit {
+			b.function.Blocks = append(b.function.Blocks[:i], b.function.Blocks[i+1:]...)
+			b.function.Blocks = append(b.function.Blocks, block)
+			break
+		}
+	}
+}
+
+// _buildChain builds the modifier at the given depth of the chain,
+// or the function body once every modifier is expanded
+func (b *builder) _buildChain(depth int, current *Block) *Block {
+	saved := b.depth
+	b.depth = depth
+	defer func() { b.depth = saved }()
+
+	if depth == len(b.modifiers) {
+		return b._buildStatements(b.body.Statements, current)
+	}
+
+	m := b.modifiers[depth]
+	for _, binding := range m.bindings() {
+		current.Collect(b.cfg._constructStatement(binding))
+	}
+	return b._buildStatements(m.definition.Body.Statements, current)
+}
+
+// _buildPlaceholder splices the rest of the chain in place of `_;`.
+// A `return` in the inner code resumes the current modifier after the placeholder.
+func (b *builder) _buildPlaceholder(stmt *AST.Common, current *Block) *Block {
+	current.Collect(b.cfg._constructStatement(stmt))
+	if b.depth == len(b.modifiers) {
+		b.cfg._warn("Placeholder outside of a modifier", stmt.Src)
+		return current
+	}
+
+	inner := b._newBlock(b._chainLabel(b.depth + 1))
+	inner.Modifier = b._modifierName(b.depth + 1)
+	b._connect(current, inner, Unconditional)
+	resume := b._newBlock("placeholder.end")
+
+	returnTarget, loops := b.returnTarget, b.loops
+	b.returnTarget, b.loops = resume, nil
+	if end := b._buildChain(b.depth+1, inner); end != nil {
+		b._connect(end, resume, Unconditional)
+	}
+	b.returnTarget, b.loops = returnTarget, loops
+
+	return b._reachable(resume)
+}
+
+func (b *builder) _chainLabel(depth int) string {
+	if depth == len(b.modifiers) {
+		return "body"
+	}
+	return "modifier." + b._modifierName(depth)
+}
+
+// _modifierName returns the name of the modifier at the given depth of the chain, empty for the body
+func (b *builder) _modifierName(depth int) string {
+	if depth >= len(b.modifiers) {
+		return ""
+	}
+	return b.modifiers[depth].definition.Name
+}
+
+// bindings returns one declaration statement per modifier parameter,
+// initialized with the corresponding argument of the invocation
+func (m *modifier) bindings() []*AST.Common {
+	var res []*AST.Common
+	for i := range m.definition.Parameters.Parameters {
+		if i >= len(m.invocation.Arguments) {
+			break
+		}
+		arg := m.invocation.Arguments[i]
+		res = append(res, &AST.Common{
+			NodeType: "VariableDeclarationStatement",
+			Src:      arg.Src,
+			ID:       arg.ID,
+			ASTNode: &AST.VariableDeclarationStatement{
+				Declarations: []*AST.VariableDeclaration{&m.definition.Parameters.Parameters[i]},
+				InitialValue: arg,
+			},
+		})
+	}
+	return res
+}
+
+func (b *builder) _newBlock(label string) *Block {
+	block := &Block{
+		ID:        b.cfg.nextBlockID,
+		Label:     label,
+		Namespace: b.cfg.Visitor.CurrentNamespace.Copy(),
+		Modifier:  b._modifierName(b.depth),
+	}
+	b.cfg.nextBlockID++
+	b.cfg.Blocks = append(b.cfg.Blocks, block)
+	b.function.Blocks = append(b.function.Blocks, block)
+	return block
+}
+
+func (b *builder) _connect(src, dst *Block, t EdgeType) {
+	edge := &Edge{
+		Source:      src,
+		Destination: dst,
+		Type:        t,
+	}
+	src.AddSuccessorEdge(edge)
+	dst.AddPredecessorEdge(edge)
+	b.cfg.Edges = append(b.cfg.Edges, edge)
+}
+
+// _buildStatements appends the statements to current, splitting it where the
+// control flow branches. It returns the block where the control flow continues,
+// or nil if the end of the statements is unreachable.
+func (b *builder) _buildStatements(stmts []*AST.Common, current *Block) *Block {
+	for _, stmt := range stmts {
+		if current == nil {
+			// dead code after return, break, ...
+			b.cfg._warn("Unreachable statement "+stmt.NodeType, stmt.Src)
+			current = b._newBlock("unreachable")
+		}
+		current = b._buildStatement(stmt, current)
+	}
+	return current
+}
+
+func (b *builder) _buildStatement(stmt *AST.Common, current *Block) *Block {
+	if stmt == nil {
+		return current
+	}
+	if _, ok := stmt.ASTNode.(*AST.GenericNode); ok {
+		b.cfg._warn("Skipped unknown statement "+stmt.NodeType, stmt.Src)
+		return current
+	}
+
+	switch stmt.NodeType {
+	case "Block":
+		return b._buildStatements(stmt.ASTNode.(*AST.Block).Statements, current)
+	case "UncheckedBlock":
+		return b._buildStatements(stmt.ASTNode.(*AST.UncheckedBlock).Statements, current)
+	case "IfStatement":
+		return b._buildIf(stmt, current)
+	case "ForStatement":
+		return b._buildFor(stmt, current)
+	case "WhileStatement":
+		return b._buildWhile(stmt, current)
+	case "DoWhileStatement":
+		return b._buildDoWhile(stmt, current)
+	case "TryStatement":
+		return b._buildTry(stmt, current)
+	case "PlaceholderStatement":
+		return b._buildPlaceholder(stmt, current)
+	case "Break":
+		current.Collect(b.cfg._constructStatement(stmt))
+		if len(b.loops) == 0 {
+			b.cfg._warn("Break outside of a loop", stmt.Src)
+			return current
+		}
+		b._connect(current, b.loops[len(b.loops)-1].breakTarget, Unconditional)
+		return nil
+	case "Continue":
+		current.Collect(b.cfg._constructStatement(stmt))
+		if len(b.loops) == 0 {
+			b.cfg._warn("Continue outside of a loop", stmt.Src)
+			return current
+		}
+		b._connect(current, b.loops[len(b.loops)-1].continueTarget, Unconditional)
+		return nil
+	}
+
+	s := b.cfg._constructStatement(stmt)
+	current.Collect(s)
+
+	switch s.Type {
+	case Return:
+		b._connect(current, b.returnTarget, Unconditional)
+		return nil
+	case Revert:
+		b._connect(current, b.function.Exit, Unconditional)
+		return nil
+	}
+	return current
+}
+
+func (b *builder) _buildIf(stmt *AST.Common, current *Block) *Block {
+	ifStmt := stmt.ASTNode.(*AST.IfStatement)
+	current.Collect(b.cfg._constructStatement(stmt))
+
+	trueBlock := b._newBlock("if.true")
+	b._connect(current, trueBlock, ConditionalTrue)
+	trueEnd := b._buildStatement(ifStmt.TrueBody, trueBlock)
+
+	var falseEnd *Block
+	if ifStmt.FalseBody != nil {
+		falseBlock := b._newBlock("if.false")
+		b._connect(current, falseBlock, ConditionalFalse)
+		falseEnd = b._buildStatement(ifStmt.FalseBody, falseBlock)
+	}
+
+	if trueEnd == nil && falseEnd == nil && ifStmt.FalseBody != nil {
+		return nil
+	}
+
+	join := b._newBlock("if.end")
+	if trueEnd != nil {
+		b._connect(trueEnd, join, Unconditional)
+	}
+	if ifStmt.FalseBody == nil {
+		b._connect(current, join, ConditionalFalse)
+	} else if falseEnd != nil {
+		b._connect(falseEnd, join, Unconditional)
+	}
+	return join
+}
+
+func (b *builder) _buildFor(stmt *AST.Common, current *Block) *Block {
+	forStmt := stmt.ASTNode.(*AST.ForStatement)
+	if forStmt.InitializationExpression != nil {
+		current.Collect(b.cfg._constructStatement(forStmt.InitializationExpression))
+	}
+
+	header := b._newBlock("for.header")
+	b._connect(current, header, Unconditional)
+	header.Collect(b.cfg._constructStatement(stmt))
+
+	body := b._newBlock("for.body")
+	next := b._newBlock("for.next")
+	exit := b._newBlock("for.end")
+	if forStmt.Condition != nil {
+		b._connect(header, body, ConditionalTrue)
+		b._connect(header, exit, ConditionalFalse)
+	} else {
+		b._connect(header, body, Unconditional)
+	}
+
+	b.loops = append(b.loops, loopTargets{breakTarget: exit, continueTarget: next})
+	if bodyEnd := b._buildStatement(forStmt.Body, body); bodyEnd != nil {
+		b._connect(bodyEnd, next, Unconditional)
+	}
+	b.loops = b.loops[:len(b.loops)-1]
+
+	if forStmt.LoopExpression != nil {
+		next.Collect(b.cfg._constructStatement(forStmt.LoopExpression))
+	}
+	b._connect(next, header, Unconditional)
+
+	return b._reachable(exit)
+}
+
+func (b *builder) _buildWhile(stmt *AST.Common, current *Block) *Block {
+	whileStmt := stmt.ASTNode.(*AST.WhileStatement)
+
+	header := b._newBlock("while.header")
+	b._connect(current, header, Unconditional)
+	header.Collect(b.cfg._constructStatement(stmt))
+
+	body := b._newBlock("while.body")
+	exit := b._newBlock("while.end")
+	// while (true) only exits through a break, like for (;;)
+	if literal, ok := whileStmt.Condition.ASTNode.(*AST.Literal); ok && literal.Value == "true" {
+		b._connect(header, body, Unconditional)
+	} else {
+		b._connect(header, body, ConditionalTrue)
+		b._connect(header, exit, ConditionalFalse)
+	}
+
+	b.loops = append(b.loops, loopTargets{breakTarget: exit, continueTarget: header})
+	if bodyEnd := b._buildStatement(whileStmt.Body, body); bodyEnd != nil {
+		b._connect(bodyEnd, header, Unconditional)
+	}
+	b.loops = b.loops[:len(b.loops)-1]
+
+	return b._reachable(exit)
+}
+
+func (b *builder) _buildDoWhile(stmt *AST.Common, current *Block) *Block {
+	doWhileStmt := stmt.ASTNode.(*AST.DoWhileStatement)
+
+	body := b._newBlock("do.body")
+	b._connect(current, body, Unconditional)
+
+	cond := b._newBlock("do.cond")
+	exit := b._newBlock("do.end")
+
+	b.loops = append(b.loops, loopTargets{breakTarget: exit, continueTarget: cond})
+	if bodyEnd := b._buildStatement(doWhileStmt.Body, body); bodyEnd != nil {
+		b._connect(bodyEnd, cond, Unconditional)
+	}
+	b.loops = b.loops[:len(b.loops)-1]
+
+	cond.Collect(b.cfg._constructStatement(stmt))
+	b._connect(cond, body, ConditionalTrue)
+	b._connect(cond, exit, ConditionalFalse)
+
+	return b._reachable(exit)
+}
+
+// _buildTry branches to the success clause on ConditionalTrue
+// and to every catch clause on ConditionalFalse
+func (b *builder) _buildTry(stmt *AST.Common, current *Block) *Block {
+	tryStmt := stmt.ASTNode.(*AST.TryStatement)
+	current.Collect(b.cfg._constructStatement(stmt))
+
+	var ends []*Block
+	for i, clause := range tryStmt.Clauses {
+		label, edgeType := "try.success", ConditionalTrue
+		if i > 0 {
+			label, edgeType = "try.catch", ConditionalFalse
+		}
+		clauseBlock := b._newBlock(label)
+		b._connect(current, clauseBlock, edgeType)
+
+		if end := b._buildStatements(clause.ASTNode.(*AST.TryCatchClause).Block.Statements, clauseBlock); end != nil {
+			ends = append(ends, end)
+		}
+	}
+
+	if len(ends) == 0 {
+		return nil
+	}
+	join := b._newBlock("try.end")
+	for _, end := range ends {
+		b._connect(end, join, Unconditional)
+	}
+	return join
+}
+
+// _reachable returns nil for a block without predecessors, e.g. the exit of `for (;;)`
+func (b *builder) _reachable(block *Block) *Block {
+	if len(block.PredecessorsEdges) == 0 {
+		return nil
+	}
+	return block
+}
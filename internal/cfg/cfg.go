@@ -8,8 +8,9 @@ import (
 
 func NewCFG(root *AST.Common, symbolTable *ST.GlobalSymbolTable) *CFG {
 	cfg := &CFG{
-		symbolTable: symbolTable,
-		Visitor:     NewVisitor(),
+		symbolTable:   symbolTable,
+		Visitor:       NewVisitor(),
+		initialValues: make(map[int]*AST.Common),
 	}
 	cfg.EntryPoints = cfg._constructEntryFuncs(root)
 
@@ -18,9 +19,11 @@ func NewCFG(root *AST.Common, symbolTable *ST.GlobalSymbolTable) *CFG {
 }
 
 func (cfg *CFG) _constructEntryFuncs(root *AST.Common) []*Function {
-	contractDefs := cfg._findContractDefinition(root)
 	var entryFuncs []*Function
-	for _, contractDef := range contractDefs {
+	for _, contractDef := range cfg._findContractDefinition(root) {
+		if !cfg._isDeployed(contractDef) {
+			continue
+		}
 		entryFuncs = append(entryFuncs, cfg._findEntryFunc(contractDef)...)
 	}
 
@@ -39,34 +42,65 @@ func (cfg *CFG) _findContractDefinition(root *AST.Common) []*AST.Common {
 	return contractDefs
 }
 
+// _isDeployed reports whether the contract is a concrete contract. A base contract
+// that is fully implemented can be deployed on its own, it has its entry points too.
+func (cfg *CFG) _isDeployed(contractDef *AST.Common) bool {
+	contract := contractDef.ASTNode.(*AST.ContractDefinition)
+	return contract.ContractKind == AST.ContractKind_Contract && !contract.Abstract && contract.FullyImplemented
+}
+
+// _findEntryFunc returns the public surface of the contract: its own public and
+// external functions and getters, and the inherited ones that are not overridden,
+// walking the linearized bases from the most derived one. An inherited function
+// is built in the namespace of the contract defining it, the state variables it
+// accesses are those of the deployed contract.
 func (cfg *CFG) _findEntryFunc(contractDef *AST.Common) []*Function {
 	var entryFuncs []*Function
 	contractName := contractDef.ASTNode.(*AST.ContractDefinition).Name
-	cfg.Visitor.EnterNamespace(contractName)
-	for _, node := range contractDef.Children {
+	bases := cfg.symbolTable.LinearizedBaseContracts(contractDef)
+	signatures := make(map[string]bool)
+
+	cfg.Visitor.Contract = contractName
+	for i, base := range bases {
+		cfg.Visitor.EnterNamespace(base.ASTNode.(*AST.ContractDefinition).Name)
+		for _, node := range base.Children {
+
+			switch def := node.ASTNode.(type) {
+			case *AST.FunctionDefinition:
+				if !def.IsPublic() && !def.IsExternal() || !def.IsImplemented() {
+					continue
+				}
+				// constructors of the bases are not part of the deployed code
+				if i > 0 && def.Kind == AST.FunctionKind_Constructor {
+					continue
+				}
+				if signatures[def.Signature()] {
+					logger.Info.Println("Overridden function:", def.Signature(), "in", base.ASTNode.(*AST.ContractDefinition).Name)
+					continue
+				}
+				signatures[def.Signature()] = true
 
-		if node.NodeType == "FunctionDefinition" {
-			funcDef := node.ASTNode.(*AST.FunctionDefinition)
+				cfg.Visitor.EnterNamespace(def.Name)
+				// BREAKPOINT usage:: def.Name == "configurationCrowdsale"
+				entryFuncs = append(entryFuncs, cfg._constructFunction(contractName+"::"+def.DisplayName(), node, def, bases))
+				cfg.Visitor.ExitNamespace()
 
-			if funcDef.IsPublic() || funcDef.IsExternal() {
-				if !funcDef.IsImplemented() {
+			case *AST.VariableDeclaration:
+				// a public state variable overrides an external function of a base
+				if !def.IsPublic() || signatures[def.GetterSignature()] {
 					continue
 				}
-				cfg.Visitor.EnterNamespace(funcDef.Name)
-				// BREAKPOINT usage:: funcDef.Name == "configurationCrowdsale"
-				entryFuncs = append(entryFuncs, &Function{
-					Name:       contractName + "::" + funcDef.Name,
-					Block:      cfg._constructFuncLevelBlock(funcDef),
-					Parameters: cfg._findFuncLevelParameters(funcDef),
-					SrcID:      node.ID,
-				})
+				signatures[def.GetterSignature()] = true
+
+				cfg.Visitor.EnterNamespace(def.Name)
+				entryFuncs = append(entryFuncs, cfg._constructGetter(contractName+"::"+def.Name, node, def))
 				cfg.Visitor.ExitNamespace()
 			}
 
 		}
-
+		cfg.Visitor.ExitNamespace()
 	}
-	cfg.Visitor.ExitNamespace()
+	cfg.Visitor.Contract = ""
 
 	return entryFuncs
 }
@@ -83,15 +117,77 @@ func (cfg *CFG) _findFuncLevelParameters(funcDef *AST.FunctionDefinition) []*ST.
 	return parameters
 }
 
-func (cfg *CFG) _constructFuncLevelBlock(funcDef *AST.FunctionDefinition) *Block {
-	block := &Block{
-		ID:        funcDef.ID,
-		Namespace: *cfg.Visitor.CurrentNamespace,
+func (cfg *CFG) _constructFunction(name string, node *AST.Common, funcDef *AST.FunctionDefinition, bases []*AST.Common) *Function {
+	modifiers := cfg._findModifiers(funcDef, bases)
+	f := &Function{
+		Name:       name,
+		Signature:  funcDef.Signature(),
+		SrcID:      node.ID,
+		Src:        node.Src,
+		Kind:       funcDef.Kind,
+		Parameters: cfg._findFuncLevelParameters(funcDef),
 	}
+	for _, m := range modifiers {
+		f.Modifiers = append(f.Modifiers, m.definition.Name)
+	}
+
+	cfg._newBuilder(f)._build(modifiers, &funcDef.Body)
 
-	for _, stmt := range funcDef.Body.Statements {
-		block.Statements = append(block.Statements, cfg._constructStatement(stmt))
+	return f
+}
+
+// _constructGetter builds the getter generated by solc for a public state variable:
+// a single statement returning the variable
+func (cfg *CFG) _constructGetter(name string, node *AST.Common, varDecl *AST.VariableDeclaration) *Function {
+	f := &Function{
+		Name:      name,
+		Signature: varDecl.GetterSignature(),
+		SrcID:     node.ID,
+		Src:       node.Src,
+		Kind:      AST.FunctionKind_Function,
 	}
+	stmt := &Statement{
+		ASTNode: *node,
+		Type:    Return,
+		Depends: []ST.Symbol{{Identifier: varDecl.Name, DeclarationID: node.ID, Type: ST.Unknown}},
+	}
+	cfg._resolveStateVariables(stmt.Depends)
+
+	cfg._newBuilder(f)._buildGetter(stmt)
 
-	return block
+	return f
+}
+
+// _findModifiers resolves the modifier invocations of the function in declaration order.
+// A modifier overridden in the linearized bases resolves to the most derived definition.
+// Base constructor specifiers are skipped.
+func (cfg *CFG) _findModifiers(funcDef *AST.FunctionDefinition, bases []*AST.Common) []*modifier {
+	var modifiers []*modifier
+	for i := range funcDef.Modifiers {
+		mi := &funcDef.Modifiers[i]
+		id, ok := mi.ReferencedDeclaration()
+		if !ok {
+			continue
+		}
+		def := cfg.symbolTable.LookupDeclaration(id)
+		if def == nil {
+			cfg._warn("Unresolved modifier", mi.Src)
+			continue
+		}
+		if modDef, ok := def.ASTNode.(*AST.ModifierDefinition); ok {
+			modifiers = append(modifiers, &modifier{invocation: mi, definition: _overridingModifier(modDef, bases)})
+		}
+	}
+	return modifiers
+}
+
+func _overridingModifier(modDef *AST.ModifierDefinition, bases []*AST.Common) *AST.ModifierDefinition {
+	for _, base := range bases {
+		for _, node := range base.Children {
+			if m, ok := node.ASTNode.(*AST.ModifierDefinition); ok && m.Name == modDef.Name {
+				return m
+			}
+		}
+	}
+	return modDef
 }
@@ -0,0 +1,339 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	AST "txtracker/internal/ast"
+	ST "txtracker/internal/symbol_table"
+)
+
+const (
+	// Schema names the JSON export of a CFG
+	Schema = "txtracker/cfg"
+	// SchemaVersion is bumped on every change a reader of the export must know of
+	SchemaVersion = 1
+)
+
+// Document is the JSON export of the CFG of a source unit. The blocks, the edges
+// and the entry points refer to each other by block ID, and the statements to
+// their AST node by ID and src attribute, so that the export holds no cycle.
+type Document struct {
+	Schema      string         `json:"schema"`
+	Version     int            `json:"version"`
+	Source      string         `json:"source,omitempty"` // path of the source unit
+	EntryPoints []FunctionJSON `json:"entryPoints"`
+	Blocks      []BlockJSON    `json:"blocks"`
+	Edges       []EdgeJSON     `json:"edges"`
+	Warnings    []Warning      `json:"warnings"`
+}
+
+type FunctionJSON struct {
+	Name       string           `json:"name"`
+	ID         int              `json:"id"`  // AST ID of the function definition
+	Src        string           `json:"src"` // "start:length:fileIndex"
+	Kind       AST.FunctionKind `json:"kind"`
+	Entry      int              `json:"entry"`
+	Exit       int              `json:"exit"`
+	Blocks     []int            `json:"blocks"` // in the order of Function.Blocks, the exit last
+	Parameters []SymbolJSON     `json:"parameters"`
+	Modifiers  []string         `json:"modifiers"`
+}
+
+type BlockJSON struct {
+	ID         int             `json:"id"`
+	Label      string          `json:"label"`
+	Namespace  []string        `json:"namespace"`
+	Modifier   string          `json:"modifier,omitempty"`
+	Statements []StatementJSON `json:"statements"`
+}
+
+// EdgeJSON is an edge between two blocks, listed in the order of the successors
+// of its source
+type EdgeJSON struct {
+	Source      int    `json:"source"`
+	Destination int    `json:"destination"`
+	Type        string `json:"type"` // ConditionalTrue, ConditionalFalse or Unconditional
+}
+
+type StatementJSON struct {
+	Node    NodeJSON     `json:"node"`
+	Type    string       `json:"type"` // StatementType.String(), e.g. Require
+	Modify  []SymbolJSON `json:"modify"`
+	Depends []SymbolJSON `json:"depends"`
+	Declare []SymbolJSON `json:"declare"`
+}
+
+// NodeJSON identifies the AST node of a statement
+type NodeJSON struct {
+	ID       int    `json:"id"`
+	NodeType string `json:"nodeType"`
+	Src      string `json:"src"`
+}
+
+// SymbolJSON is a symbol without the attributes of its declaration
+type SymbolJSON struct {
+	Identifier     string   `json:"identifier"`
+	Type           string   `json:"type"` // SymbolType.String(), e.g. StateVariable
+	Namespace      []string `json:"namespace"`
+	DeclarationID  int      `json:"declarationId,omitempty"`
+	IsFunctionCall bool     `json:"isFunctionCall,omitempty"`
+	FunctionCalls  []string `json:"functionCalls,omitempty"`
+}
+
+// Export returns the JSON document of the CFG, source being the path of its source unit
+func (cfg *CFG) Export(source string) *Document {
+	doc := &Document{
+		Schema:      Schema,
+		Version:     SchemaVersion,
+		Source:      source,
+		EntryPoints: []FunctionJSON{},
+		Blocks:      []BlockJSON{},
+		Edges:       []EdgeJSON{},
+		Warnings:    append([]Warning{}, cfg.Warnings...),
+	}
+	for _, f := range cfg.EntryPoints {
+		function := FunctionJSON{
+			Name:       f.Name,
+			ID:         f.SrcID,
+			Src:        f.Src,
+			Kind:       f.Kind,
+			Entry:      f.Entry.ID,
+			Exit:       f.Exit.ID,
+			Blocks:     []int{},
+			Parameters: []SymbolJSON{},
+			Modifiers:  append([]string{}, f.Modifiers...),
+		}
+		for _, b := range f.Blocks {
+			function.Blocks = append(function.Blocks, b.ID)
+		}
+		for _, p := range f.Parameters {
+			function.Parameters = append(function.Parameters, exportSymbol(*p))
+		}
+		doc.EntryPoints = append(doc.EntryPoints, function)
+	}
+	for _, b := range cfg.Blocks {
+		block := BlockJSON{
+			ID:         b.ID,
+			Label:      b.Label,
+			Namespace:  append([]string{}, b.Namespace...),
+			Modifier:   b.Modifier,
+			Statements: []StatementJSON{},
+		}
+		for _, s := range b.Statements {
+			block.Statements = append(block.Statements, StatementJSON{
+				Node:    NodeJSON{ID: s.ASTNode.ID, NodeType: s.ASTNode.NodeType, Src: s.ASTNode.Src},
+				Type:    s.Type.String(),
+				Modify:  exportSymbols(s.Modify),
+				Depends: exportSymbols(s.Depends),
+				Declare: exportSymbols(s.Declare),
+			})
+		}
+		doc.Blocks = append(doc.Blocks, block)
+	}
+	for _, e := range cfg.Edges {
+		doc.Edges = append(doc.Edges, EdgeJSON{Source: e.Source.ID, Destination: e.Destination.ID, Type: e.Type.String()})
+	}
+	return doc
+}
+
+func exportSymbols(symbols []ST.Symbol) []SymbolJSON {
+	res := []SymbolJSON{}
+	for _, s := range symbols {
+		res = append(res, exportSymbol(s))
+	}
+	return res
+}
+
+func exportSymbol(s ST.Symbol) SymbolJSON {
+	return SymbolJSON{
+		Identifier:     s.Identifier,
+		Type:           s.Type.String(),
+		Namespace:      append([]string{}, s.Namespace...),
+		DeclarationID:  s.DeclarationID,
+		IsFunctionCall: s.IsFunctionCall,
+		FunctionCalls:  s.FunctionCalls,
+	}
+}
+
+// ReadDocuments decodes the documents of a stream, e.g. the output of `cfg -f json`
+// holding one document per source unit
+func ReadDocuments(in io.Reader) ([]*Document, error) {
+	var docs []*Document
+	decoder := json.NewDecoder(in)
+	for {
+		doc := &Document{}
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// Import rebuilds the CFG of a document. The statements only know the ID, the
+// node type and the src attribute of their AST node, ASTNode.ASTNode is nil, and
+// the CFG has no symbol table.
+func Import(doc *Document) (*CFG, error) {
+	if doc.Schema != Schema {
+		return nil, fmt.Errorf("not a CFG export: schema %q", doc.Schema)
+	}
+	if doc.Version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported CFG export version %d, expected %d", doc.Version, SchemaVersion)
+	}
+
+	cfg := &CFG{
+		Warnings: append([]Warning{}, doc.Warnings...),
+		Visitor:  NewVisitor(),
+	}
+	blocks := make(map[int]*Block)
+	for _, b := range doc.Blocks {
+		if _, ok := blocks[b.ID]; ok {
+			return nil, fmt.Errorf("duplicate block %d", b.ID)
+		}
+		block := &Block{
+			ID:        b.ID,
+			Label:     b.Label,
+			Namespace: append(ST.NewNamespace(), b.Namespace...),
+			Modifier:  b.Modifier,
+		}
+		for _, s := range b.Statements {
+			stmt, err := importStatement(s)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %v", b.ID, err)
+			}
+			block.Collect(stmt)
+		}
+		blocks[b.ID] = block
+		cfg.Blocks = append(cfg.Blocks, block)
+		cfg.nextBlockID = max(cfg.nextBlockID, b.ID+1)
+	}
+	lookup := func(id int) (*Block, error) {
+		if block, ok := blocks[id]; ok {
+			return block, nil
+		}
+		return nil, fmt.Errorf("unknown block %d", id)
+	}
+
+	for i, e := range doc.Edges {
+		source, err := lookup(e.Source)
+		if err != nil {
+			return nil, fmt.Errorf("edge %d: %v", i, err)
+		}
+		destination, err := lookup(e.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("edge %d: %v", i, err)
+		}
+		edgeType, err := parseEdgeType(e.Type)
+		if err != nil {
+			return nil, fmt.Errorf("edge %d: %v", i, err)
+		}
+		edge := &Edge{Source: source, Destination: destination, Type: edgeType}
+		source.AddSuccessorEdge(edge)
+		destination.AddPredecessorEdge(edge)
+		cfg.Edges = append(cfg.Edges, edge)
+	}
+
+	for _, f := range doc.EntryPoints {
+		function := &Function{
+			Name:      f.Name,
+			SrcID:     f.ID,
+			Src:       f.Src,
+			Kind:      f.Kind,
+			Modifiers: f.Modifiers,
+		}
+		var err error
+		if function.Entry, err = lookup(f.Entry); err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		if function.Exit, err = lookup(f.Exit); err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		for _, id := range f.Blocks {
+			block, err := lookup(id)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", f.Name, err)
+			}
+			function.Blocks = append(function.Blocks, block)
+		}
+		for _, p := range f.Parameters {
+			symbol, err := importSymbol(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", f.Name, err)
+			}
+			function.Parameters = append(function.Parameters, &symbol)
+		}
+		cfg.EntryPoints = append(cfg.EntryPoints, function)
+	}
+	return cfg, nil
+}
+
+func importStatement(s StatementJSON) (*Statement, error) {
+	stmtType, err := parseStatementType(s.Type)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &Statement{
+		ASTNode: AST.Common{ID: s.Node.ID, NodeType: s.Node.NodeType, Src: s.Node.Src},
+		Type:    stmtType,
+	}
+	for _, symbols := range []struct {
+		from []SymbolJSON
+		to   *[]ST.Symbol
+	}{{s.Modify, &stmt.Modify}, {s.Depends, &stmt.Depends}, {s.Declare, &stmt.Declare}} {
+		for _, sym := range symbols.from {
+			symbol, err := importSymbol(sym)
+			if err != nil {
+				return nil, fmt.Errorf("node %d: %v", s.Node.ID, err)
+			}
+			*symbols.to = append(*symbols.to, symbol)
+		}
+	}
+	return stmt, nil
+}
+
+func importSymbol(s SymbolJSON) (ST.Symbol, error) {
+	symbolType, err := parseSymbolType(s.Type)
+	if err != nil {
+		return ST.Symbol{}, err
+	}
+	return ST.Symbol{
+		Namespace:      append(ST.NewNamespace(), s.Namespace...),
+		Type:           symbolType,
+		Identifier:     s.Identifier,
+		IsFunctionCall: s.IsFunctionCall,
+		FunctionCalls:  s.FunctionCalls,
+		DeclarationID:  s.DeclarationID,
+	}, nil
+}
+
+func parseStatementType(name string) (StatementType, error) {
+	for t := Break; t <= Assignment; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown statement type %q", name)
+}
+
+func parseEdgeType(name string) (EdgeType, error) {
+	for t := ConditionalTrue; t <= Unconditional; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown edge type %q", name)
+}
+
+func parseSymbolType(name string) (ST.SymbolType, error) {
+	for t := ST.StateVariable; t <= ST.Unknown; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown symbol type %q", name)
+}
@@ -4,12 +4,15 @@ import (
 	"strings"
 	AST "txtracker/internal/ast"
 	"txtracker/internal/logger"
+	ST "txtracker/internal/symbol_table"
 )
 
 func (cfg *CFG) _constructStatement(stmt *AST.Common) *Statement {
 
 	_type := cfg._getStatementType(stmt)
 	modify, depends, declare := cfg._getModifyAndDependsSymbols(stmt, _type)
+	cfg._resolveStateVariables(modify)
+	cfg._resolveStateVariables(depends)
 	return &Statement{
 		ASTNode: *stmt,
 		Type:    _type,
@@ -19,9 +22,33 @@ func (cfg *CFG) _constructStatement(stmt *AST.Common) *Statement {
 	}
 }
 
+// _resolveStateVariables marks the symbols referring to a state variable, declared
+// by the contract or inherited, and places them in the namespace of the deployed
+// contract, as well as the storage slots accessed by assembly
+func (cfg *CFG) _resolveStateVariables(symbols []ST.Symbol) {
+	for i := range symbols {
+		switch symbols[i].Type {
+		case ST.StateVariable:
+			symbols[i].Namespace = ST.Namespace{cfg.Visitor.Contract, symbols[i].Identifier}
+		case ST.Unknown:
+			decl := cfg.symbolTable.LookupDeclaration(symbols[i].DeclarationID)
+			if decl == nil {
+				continue
+			}
+			if vd, ok := decl.ASTNode.(*AST.VariableDeclaration); ok && vd.StateVariable {
+				symbols[i].Type = ST.StateVariable
+				symbols[i].Namespace = ST.Namespace{cfg.Visitor.Contract, vd.Name}
+			}
+		}
+	}
+}
+
 func (cfg *CFG) _getStatementType(stmt *AST.Common) StatementType {
 	switch stmt.NodeType {
 	case "IfStatement":
+		if cfg._isAuthorizeIf(stmt.ASTNode.(*AST.IfStatement)) {
+			return Authorize
+		}
 		return If
 	case "ForStatement":
 		return For
@@ -38,7 +65,12 @@ func (cfg *CFG) _getStatementType(stmt *AST.Common) StatementType {
 	case "ExpressionStatement":
 		// whether the expression is a require?
 		if cfg._isRequire(stmt.ASTNode.(*AST.ExpressionStatement)) {
+			if arguments := stmt.ASTNode.(*AST.ExpressionStatement).Expression.ASTNode.(*AST.FunctionCall).Arguments; len(arguments) > 0 && cfg._isAuthorize(arguments[0]) {
+				return Authorize
+			}
 			return Require
+		} else if cfg._isRevert(stmt.ASTNode.(*AST.ExpressionStatement)) {
+			return Revert
 		} else if cfg._isAssert(stmt.ASTNode.(*AST.ExpressionStatement)) {
 			return Assert
 		} else if cfg._isModify(stmt.ASTNode.(*AST.ExpressionStatement)) {
@@ -47,28 +79,31 @@ func (cfg *CFG) _getStatementType(stmt *AST.Common) StatementType {
 			if cfg._isEvent(stmt.ASTNode.(*AST.ExpressionStatement)) {
 				return Emit
 			}
+			if cfg._isAuthorizeCall(stmt.ASTNode.(*AST.ExpressionStatement)) {
+				return Authorize
+			}
 			return FunctionCall
 		} else {
 			logger.Warning.Println("Unknown handled expression statement type:", stmt.NodeType)
 			return Expression
 		}
-	case "BreakStatement":
+	case "Break":
 		return Break
-	case "ContinueStatement":
+	case "Continue":
 		return Continue
 	case "PlaceholderStatement":
 		return Placeholder
-	case "RevertStatement":
+	case "RevertStatement", "Throw":
 		return Revert
 	case "TryStatement":
 		return Try
 	case "UncheckedBlock":
 		return UncheckedBlock
-	case "InlineAssemblyStatement":
+	case "InlineAssembly":
 		return InlineAssembly
 	default:
-		logger.Warning.Println("Unknown statement type:", stmt.NodeType)
-		return -1
+		cfg._warn("Unknown statement type "+stmt.NodeType, stmt.Src)
+		return Unknown
 	}
 }
 
@@ -85,6 +120,19 @@ func (cfg *CFG) _isRequire(stmt *AST.ExpressionStatement) bool {
 	return true
 }
 
+func (cfg *CFG) _isRevert(stmt *AST.ExpressionStatement) bool {
+
+	if funCall, ok := stmt.Expression.ASTNode.(*AST.FunctionCall); !ok {
+		return false
+	} else if funCall.Expression.NodeType != "Identifier" {
+		return false
+	} else if funCall.Expression.ASTNode.(*AST.Identifier).Name != "revert" {
+		return false
+	}
+
+	return true
+}
+
 func (cfg *CFG) _isAssert(stmt *AST.ExpressionStatement) bool {
 
 	if funCall, ok := stmt.Expression.ASTNode.(*AST.FunctionCall); !ok {
@@ -107,6 +155,14 @@ func (cfg *CFG) _isModify(stmt *AST.ExpressionStatement) bool {
 		return true
 	}
 
+	// x++, x-- and delete x
+	if unary, ok := stmt.Expression.ASTNode.(*AST.UnaryOperation); ok {
+		switch unary.Operator {
+		case AST.UnaryOperator_Increment, AST.UnaryOperator_Decrement, AST.UnaryOperator_Delete:
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -136,3 +192,23 @@ func (cfg *CFG) _isEvent(stmt *AST.ExpressionStatement) bool {
 
 	return false
 }
+
+// InternalCalls returns the functions of the contracts called by name where the
+// statement stands in the CFG, in the order of the calls. The bodies of these
+// functions are not part of the CFG. Imported CFGs have no AST: the result is nil.
+func (cfg *CFG) InternalCalls(stmt *Statement) []*AST.FunctionDefinition {
+	if cfg.symbolTable == nil {
+		return nil
+	}
+	var res []*AST.FunctionDefinition
+	visited := make(map[*AST.FunctionDefinition]bool)
+	AST.Inspect(EvaluatedExpression(&stmt.ASTNode), func(node *AST.Common) bool {
+		if call, ok := node.ASTNode.(*AST.FunctionCall); ok {
+			if funcDef := cfg._internalCallee(call, visited); funcDef != nil {
+				res = append(res, funcDef)
+			}
+		}
+		return true
+	})
+	return res
+}
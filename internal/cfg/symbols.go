@@ -18,8 +18,21 @@ func (cfg *CFG) _getModifyAndDependsSymbols(stmt *AST.Common, _type StatementTyp
 		Assignment:          &AssignmentHandler{},
 		Assert:              &AssertHandler{},
 		Require:             &RequireHandler{},
+		Authorize:           &AuthorizeHandler{},
 		FunctionCall:        &FunctionCallHandler{},
 		If:                  &IfHandler{},
+		For:                 &LoopHandler{},
+		While:               &LoopHandler{},
+		DoWhile:             &LoopHandler{},
+		Try:                 &TryHandler{},
+		Revert:              &RevertHandler{},
+		Break:               &JumpHandler{},
+		Continue:            &JumpHandler{},
+		Placeholder:         &JumpHandler{},
+		InlineAssembly:      &InlineAssemblyHandler{slotOf: cfg._slotOf},
+	}
+	if _type == VariableDeclaration {
+		cfg._recordInitialValues(stmt)
 	}
 	if handler, ok := handlers[_type]; ok {
 		handler.GetSymbols(*cfg.Visitor.CurrentNamespace, stmt, &modify, &depends, &declare)
@@ -65,11 +78,25 @@ type EmitHandler struct {
 }
 
 func (h *EmitHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends, declare *[]ST.Symbol) {
-	// stmt.NodeType() == "EmitStatement"
-	emit := stmt.ASTNode.(*AST.ExpressionStatement).Expression.ASTNode.(*AST.FunctionCall)
+	// an EmitStatement, or the call of an event before solc 0.4.21
+	var emit *AST.FunctionCall
+	switch n := stmt.ASTNode.(type) {
+	case *AST.EmitStatement:
+		emit = n.EventCall.ASTNode.(*AST.FunctionCall)
+	case *AST.ExpressionStatement:
+		emit = n.Expression.ASTNode.(*AST.FunctionCall)
+	}
+	var name string
+	switch event := emit.Expression.ASTNode.(type) {
+	case *AST.Identifier:
+		name = event.Name
+	case *AST.MemberAccess:
+		// emit I.Transfer(...) of solc >= 0.8.20
+		name = event.MemberName
+	}
 	*depends = append(*depends, ST.Symbol{
 		Namespace:  namespace,
-		Identifier: emit.Expression.ASTNode.(*AST.Identifier).Name + "()",
+		Identifier: name + "()",
 		Type:       ST.Event,
 	})
 }
@@ -79,6 +106,9 @@ type ReturnHandler struct {
 
 func (h *ReturnHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends *[]ST.Symbol, declare *[]ST.Symbol) {
 	expr := stmt.ASTNode.(*AST.Return).Expression
+	if expr == nil {
+		return
+	}
 	if expr.NodeType == "FunctionCall" {
 		extractFuncSymbols(namespace, expr, depends)
 	} else {
@@ -91,9 +121,17 @@ type AssignmentHandler struct {
 
 func (h *AssignmentHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends *[]ST.Symbol, declare *[]ST.Symbol) {
 	// stmt.NodeType() == "ExpressionStatement"
-	assignment := stmt.ASTNode.(*AST.ExpressionStatement).Expression.ASTNode.(*AST.Assignment)
-	extractSymbolsFromExpression(assignment.LeftHandSide, modify)
-	extractSymbolsFromExpression(assignment.RightHandSide, depends)
+	switch expr := stmt.ASTNode.(*AST.ExpressionStatement).Expression.ASTNode.(type) {
+	case *AST.Assignment:
+		extractSymbolsFromExpression(expr.LeftHandSide, modify)
+		extractSymbolsFromExpression(expr.RightHandSide, depends)
+	case *AST.UnaryOperation:
+		// x++ reads x as well, delete x does not
+		extractSymbolsFromExpression(expr.SubExpression, modify)
+		if expr.Operator != AST.UnaryOperator_Delete {
+			extractSymbolsFromExpression(expr.SubExpression, depends)
+		}
+	}
 }
 
 type AssertHandler struct {
@@ -113,6 +151,24 @@ func (h *RequireHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, mo
 	// extractSymbolsFromExpression(stmt.ASTNode.(*AST.ExpressionStatement).Expression.ASTNode.(*AST.FunctionCall).Expression, depends)
 }
 
+// AuthorizeHandler handles the guards checking the caller: an if, a require or
+// a call to a function checking the caller such as _checkOwner()
+type AuthorizeHandler struct {
+}
+
+func (h *AuthorizeHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends *[]ST.Symbol, declare *[]ST.Symbol) {
+	if stmt.NodeType == "IfStatement" {
+		(&IfHandler{}).GetSymbols(namespace, stmt, modify, depends, declare)
+		return
+	}
+	funCall := stmt.ASTNode.(*AST.ExpressionStatement).Expression.ASTNode.(*AST.FunctionCall)
+	if ident, ok := funCall.Expression.ASTNode.(*AST.Identifier); ok && ident.Name == "require" {
+		(&RequireHandler{}).GetSymbols(namespace, stmt, modify, depends, declare)
+	} else {
+		(&FunctionCallHandler{}).GetSymbols(namespace, stmt, modify, depends, declare)
+	}
+}
+
 type FunctionCallHandler struct {
 }
 
@@ -139,6 +195,190 @@ func (h *IfHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify,
 	extractSymbolsFromExpression(condition, depends)
 }
 
+type LoopHandler struct {
+}
+
+func (h *LoopHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends, declare *[]ST.Symbol) {
+	var condition *AST.Common
+	switch loop := stmt.ASTNode.(type) {
+	case *AST.ForStatement:
+		condition = loop.Condition
+	case *AST.WhileStatement:
+		condition = loop.Condition
+	case *AST.DoWhileStatement:
+		condition = loop.Condition
+	}
+	extractSymbolsFromExpression(condition, depends)
+}
+
+type TryHandler struct {
+}
+
+func (h *TryHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends, declare *[]ST.Symbol) {
+	externalCall := stmt.ASTNode.(*AST.TryStatement).ExternalCall
+	if funCall, ok := externalCall.ASTNode.(*AST.FunctionCall); ok {
+		for _, arg := range funCall.Arguments {
+			extractSymbolsFromExpression(arg, depends)
+		}
+		extractFuncSymbols(namespace, funCall.Expression, declare)
+	}
+}
+
+type RevertHandler struct {
+}
+
+func (h *RevertHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends, declare *[]ST.Symbol) {
+	var call *AST.Common
+	switch revert := stmt.ASTNode.(type) {
+	case *AST.RevertStatement:
+		// revert CustomError(...)
+		call = revert.ErrorCall
+	case *AST.ExpressionStatement:
+		// revert(...)
+		call = revert.Expression
+	case *AST.Throw:
+		return
+	}
+	if funCall, ok := call.ASTNode.(*AST.FunctionCall); ok {
+		for _, arg := range funCall.Arguments {
+			extractSymbolsFromExpression(arg, depends)
+		}
+	}
+}
+
+// Break, Continue and Placeholder only affect the control flow
+type JumpHandler struct {
+}
+
+func (h *JumpHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends, declare *[]ST.Symbol) {
+}
+
+// InlineAssemblyHandler maps the storage accesses of an assembly block onto the
+// symbols of the Solidity statements: sstore modifies the state variable of its
+// slot, sload depends on it, e.g. x for sstore(add(x.slot, i), v). A slot that
+// is not computed from the slot of a variable, e.g. the implementation slot of a
+// proxy, is a state variable named after it: storage[IMPLEMENTATION_SLOT] for
+// sstore(slot, impl) after bytes32 slot = IMPLEMENTATION_SLOT. The Solidity
+// variables assigned in the block are modified, the others read.
+type InlineAssemblyHandler struct {
+	slotOf func(declaration int) (string, bool) // the slot held by a Solidity variable
+}
+
+func (h *InlineAssemblyHandler) GetSymbols(namespace ST.Namespace, stmt *AST.Common, modify, depends, declare *[]ST.Symbol) {
+	assigned := make(map[*AST.YulIdentifier]bool)
+	AST.Inspect(stmt.ASTNode.(*AST.InlineAssembly).AST, func(node *AST.Common) bool {
+		switch n := node.ASTNode.(type) {
+		case *AST.YulFunctionCall:
+			switch n.FunctionName.Name {
+			case "sstore":
+				if len(n.Arguments) == 2 {
+					h._storageSymbols(n.Arguments[0], modify)
+				}
+			case "sload":
+				if len(n.Arguments) == 1 {
+					h._storageSymbols(n.Arguments[0], depends)
+				}
+			}
+		case *AST.YulAssignment:
+			for _, name := range n.VariableNames {
+				if ident, ok := name.ASTNode.(*AST.YulIdentifier); ok && ident.Declaration != 0 {
+					assigned[ident] = true
+					*modify = append(*modify, yulSymbol(ident))
+				}
+			}
+		case *AST.YulIdentifier:
+			// x.slot and x.offset are constants, not a read of x
+			if n.Declaration != 0 && !assigned[n] && n.Suffix != "slot" && n.Suffix != "offset" {
+				*depends = append(*depends, yulSymbol(n))
+			}
+		}
+		return true
+	})
+}
+
+// _storageSymbols extracts the state variables of the storage slot of an sstore
+// or an sload
+func (h *InlineAssemblyHandler) _storageSymbols(slot *AST.Common, symbols *[]ST.Symbol) {
+	slots := AST.YulSlots(slot)
+	for _, ident := range slots {
+		*symbols = append(*symbols, yulSymbol(ident.ASTNode.(*AST.YulIdentifier)))
+	}
+	if len(slots) > 0 {
+		return
+	}
+	*symbols = append(*symbols, ST.Symbol{
+		Identifier: "storage[" + h._slotSource(slot) + "]",
+		Type:       ST.StateVariable,
+	})
+}
+
+// _slotSource renders a slot with the Solidity variables replaced by the slot
+// they hold, so that a slot is named the same whatever the variable holding it
+func (h *InlineAssemblyHandler) _slotSource(expr *AST.Common) string {
+	switch n := expr.ASTNode.(type) {
+	case *AST.YulIdentifier:
+		if n.Declaration != 0 && n.Suffix == "" {
+			if slot, ok := h.slotOf(n.Declaration); ok {
+				return slot
+			}
+		}
+	case *AST.YulFunctionCall:
+		var args []string
+		for _, arg := range n.Arguments {
+			args = append(args, h._slotSource(arg))
+		}
+		return n.FunctionName.Name + "(" + strings.Join(args, ", ") + ")"
+	}
+	return AST.YulSource(expr)
+}
+
+// _recordInitialValues records the initial value of the local variable of a
+// declaration statement
+func (cfg *CFG) _recordInitialValues(stmt *AST.Common) {
+	declaration := stmt.ASTNode.(*AST.VariableDeclarationStatement)
+	if len(declaration.Declarations) == 1 && declaration.Declarations[0] != nil && declaration.InitialValue != nil {
+		cfg.initialValues[declaration.Declarations[0].ID] = declaration.InitialValue
+	}
+}
+
+// _slotOf returns the slot held by a Solidity variable: a local variable is
+// resolved to its initial value, until a state variable, e.g. the constant
+// IMPLEMENTATION_SLOT, or a literal
+func (cfg *CFG) _slotOf(id int) (string, bool) {
+	seen := make(map[int]bool)
+	for !seen[id] {
+		seen[id] = true
+		value, ok := cfg.initialValues[id]
+		if !ok {
+			if decl := cfg.symbolTable.LookupDeclaration(id); decl != nil {
+				if vd, ok := decl.ASTNode.(*AST.VariableDeclaration); ok && vd.StateVariable {
+					return vd.Name, true
+				}
+			}
+			return "", false
+		}
+		switch n := value.ASTNode.(type) {
+		case *AST.Identifier:
+			id = n.ReferencedDeclaration
+		case *AST.Literal:
+			return n.Value, true
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// the symbol of a Solidity variable referenced in an assembly block, the state
+// variables are resolved with the symbols of the statements
+func yulSymbol(ident *AST.YulIdentifier) ST.Symbol {
+	return ST.Symbol{
+		Identifier:    ident.VariableName(),
+		DeclarationID: ident.Declaration,
+		Type:          ST.Unknown,
+	}
+}
+
 // helper function:
 // recrusively extract symbols from the given function reference
 func extractFuncSymbols(namespace ST.Namespace, expr *AST.Common, symbols *[]ST.Symbol) {
@@ -177,8 +417,9 @@ func extractSymbolsFromExpression(expr *AST.Common, symbols *[]ST.Symbol) {
 	switch expr.NodeType {
 	case "Identifier":
 		*symbols = append(*symbols, ST.Symbol{
-			Namespace:  nil,
-			Identifier: expr.ASTNode.(*AST.Identifier).Name,
+			Namespace:     nil,
+			Identifier:    expr.ASTNode.(*AST.Identifier).Name,
+			DeclarationID: expr.ASTNode.(*AST.Identifier).ReferencedDeclaration,
 			Type: func() ST.SymbolType {
 				ts := expr.ASTNode.(*AST.Identifier).TypeDescriptions.TypeString
 				// ts start with "function" means it is a function
@@ -192,8 +433,17 @@ func extractSymbolsFromExpression(expr *AST.Common, symbols *[]ST.Symbol) {
 	case "IndexAccess":
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.IndexAccess).BaseExpression, symbols)
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.IndexAccess).IndexExpression, symbols)
+	case "IndexRangeAccess":
+		extractSymbolsFromExpression(expr.ASTNode.(*AST.IndexRangeAccess).BaseExpression, symbols)
+		extractSymbolsFromExpression(expr.ASTNode.(*AST.IndexRangeAccess).StartExpression, symbols)
+		extractSymbolsFromExpression(expr.ASTNode.(*AST.IndexRangeAccess).EndExpression, symbols)
 	case "MemberAccess":
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.MemberAccess).Expression, symbols)
+	case "FunctionCallOptions":
+		for _, option := range expr.ASTNode.(*AST.FunctionCallOptions).Options {
+			extractSymbolsFromExpression(option, symbols)
+		}
+		extractSymbolsFromExpression(expr.ASTNode.(*AST.FunctionCallOptions).Expression, symbols)
 	case "BinaryOperation":
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.BinaryOperation).LeftExpression, symbols)
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.BinaryOperation).RightExpression, symbols)
@@ -204,6 +454,10 @@ func extractSymbolsFromExpression(expr *AST.Common, symbols *[]ST.Symbol) {
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.FunctionCall).Expression, symbols)
 	case "UnaryOperation":
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.UnaryOperation).SubExpression, symbols)
+	case "TupleExpression":
+		for _, component := range expr.ASTNode.(*AST.TupleExpression).Components {
+			extractSymbolsFromExpression(component, symbols)
+		}
 	case "Assignment":
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.Assignment).LeftHandSide, symbols)
 		extractSymbolsFromExpression(expr.ASTNode.(*AST.Assignment).RightHandSide, symbols)
@@ -28,10 +28,13 @@ const (
 	Require
 	Assert
 	FunctionCall
-	// If statement's condition includes StateVariable
+	// require or if statement checking the caller against a state variable,
+	// e.g. require(msg.sender == owner)
 	Authorize
 	// Modify: Write to state variable
 	Assignment // Assignment
+	// statement the CFG does not model, reported as a warning
+	Unknown
 )
 
 func (s StatementType) String() string {
@@ -56,6 +59,7 @@ func (s StatementType) String() string {
 		"FunctionCall",
 		"Authorize",
 		"Assign",
+		"Unknown",
 	}[s]
 }
 
@@ -63,12 +67,33 @@ type CFG struct {
 	EntryPoints []*Function `json:"entryPoints"`
 	Blocks      []*Block    `json:"blocks"`
 	Edges       []*Edge     `json:"edges"`
+	Warnings    []Warning   `json:"warnings"` // the constructs skipped or approximated while building
 	symbolTable *ST.GlobalSymbolTable
 	Visitor     *Visitor
+	nextBlockID int
+	// the initial values of the local variables by declaration ID, e.g. of the
+	// slot a variable holds for an assembly block
+	initialValues map[int]*AST.Common
+}
+
+// Warning is a construct of the source the CFG does not model faithfully, e.g.
+// an unreachable statement or an unresolved modifier
+type Warning struct {
+	Message string `json:"message"`
+	Src     string `json:"src"` // src attribute of the node, "start:length:fileIndex"
+}
+
+// _warn logs a warning about a node and keeps it in Warnings
+func (cfg *CFG) _warn(message string, src string) {
+	logger.Warning.Println(message+":", src)
+	cfg.Warnings = append(cfg.Warnings, Warning{Message: message, Src: src})
 }
 
 type Visitor struct {
 	CurrentNamespace *ST.Namespace
+	// the deployed contract of the entry points being built, whose storage holds
+	// the state variables
+	Contract string
 }
 
 func NewVisitor() *Visitor {
@@ -86,16 +111,23 @@ func (v *Visitor) ExitNamespace() {
 }
 
 type Function struct {
-	Name       string `json:"name"`
-	SrcID      int    `json:"src"`
-	Block      *Block
+	Name       string           `json:"name"`
+	Signature  string           `json:"signature"` // name and parameter types, e.g. withdraw(uint256)
+	SrcID      int              `json:"src"`
+	Src        string           `json:"srcRange"` // src attribute of the function definition, "start:length:fileIndex"
+	Kind       AST.FunctionKind `json:"kind"`
+	Entry      *Block           // empty block without predecessors
+	Exit       *Block           // empty block reached by every return, revert and the end of the body
+	Blocks     []*Block
 	Parameters []*ST.Symbol
-	// TODO add modifiers
+	Modifiers  []string // names of the inlined modifiers, outermost first
 }
 
 type Block struct {
 	ID                int          `json:"id"`
+	Label             string       `json:"label"` // entry, exit, if.true, for.header, ...
 	Namespace         ST.Namespace `json:"namespace"`
+	Modifier          string       `json:"modifier,omitempty"` // modifier holding the statements, empty in the function body
 	Statements        []*Statement `json:"statements"`
 	SuccessorsEdges   []*Edge      `json:"successors"`
 	PredecessorsEdges []*Edge      `json:"predecessors"`
@@ -109,6 +141,19 @@ func (b *Block) AddSuccessorEdge(e *Edge) {
 	b.SuccessorsEdges = append(b.SuccessorsEdges, e)
 }
 
+func (b *Block) AddPredecessorEdge(e *Edge) {
+	b.PredecessorsEdges = append(b.PredecessorsEdges, e)
+}
+
+// Successors returns the destination blocks of the outgoing edges
+func (b *Block) Successors() []*Block {
+	var res []*Block
+	for _, e := range b.SuccessorsEdges {
+		res = append(res, e.Destination)
+	}
+	return res
+}
+
 type Edge struct {
 	Source      *Block   `json:"source"`
 	Destination *Block   `json:"destination"`
@@ -123,6 +168,14 @@ const (
 	Unconditional
 )
 
+func (e EdgeType) String() string {
+	return [...]string{
+		"ConditionalTrue",
+		"ConditionalFalse",
+		"Unconditional",
+	}[e]
+}
+
 type Statement struct {
 	ASTNode AST.Common `json:"astNode"`
 	Type    StatementType
@@ -131,6 +184,81 @@ type Statement struct {
 	Declare []ST.Symbol
 }
 
+// Writes returns the symbols assigned by the statement: for each assigned expression,
+// its first symbol is the assigned variable and the others are index expressions,
+// e.g. msg.sender in balances[msg.sender] = 0. A tuple assigns each of its components.
+// An assembly block assigns every modified symbol.
+func (s *Statement) Writes() []ST.Symbol {
+	writes, _ := s._splitModify()
+	return writes
+}
+
+// IndexReads returns the modified symbols that are read to find the assigned
+// variables, the index expressions
+func (s *Statement) IndexReads() []ST.Symbol {
+	_, reads := s._splitModify()
+	return reads
+}
+
+func (s *Statement) _splitModify() (writes, reads []ST.Symbol) {
+	if s.Type == InlineAssembly || len(s.Modify) == 0 {
+		return s.Modify, nil
+	}
+	// an imported CFG has no AST, a statement assigns a single variable
+	if s.ASTNode.ASTNode == nil {
+		return s.Modify[:1], s.Modify[1:]
+	}
+	offset := 0
+	for _, lvalue := range AssignedExpressions(&s.ASTNode) {
+		var symbols []ST.Symbol
+		extractSymbolsFromExpression(lvalue, &symbols)
+		if len(symbols) == 0 || offset+len(symbols) > len(s.Modify) {
+			continue
+		}
+		writes = append(writes, s.Modify[offset])
+		reads = append(reads, s.Modify[offset+1:offset+len(symbols)]...)
+		offset += len(symbols)
+	}
+	return writes, reads
+}
+
+// AssignedExpressions returns the expressions assigned by an assignment, x++, x--
+// or delete x statement, the components of a tuple one by one
+func AssignedExpressions(stmt *AST.Common) []*AST.Common {
+	exprStmt, ok := stmt.ASTNode.(*AST.ExpressionStatement)
+	if !ok {
+		return nil
+	}
+	switch expr := exprStmt.Expression.ASTNode.(type) {
+	case *AST.Assignment:
+		return AST.LValues(expr.LeftHandSide)
+	case *AST.UnaryOperation:
+		switch expr.Operator {
+		case AST.UnaryOperator_Increment, AST.UnaryOperator_Decrement, AST.UnaryOperator_Delete:
+			return []*AST.Common{expr.SubExpression}
+		}
+	}
+	return nil
+}
+
+// EvaluatedExpression returns the part of a statement evaluated where the statement
+// stands in the CFG: the condition of a branch or a loop, the body is in other blocks
+func EvaluatedExpression(stmt *AST.Common) *AST.Common {
+	switch n := stmt.ASTNode.(type) {
+	case *AST.IfStatement:
+		return n.Condition
+	case *AST.ForStatement:
+		return n.Condition
+	case *AST.WhileStatement:
+		return n.Condition
+	case *AST.DoWhileStatement:
+		return n.Condition
+	case *AST.TryStatement:
+		return n.ExternalCall
+	}
+	return stmt
+}
+
 func StatementToString(s *Statement) string {
 	switch s.Type {
 	case VariableDeclaration:
@@ -143,10 +271,18 @@ func StatementToString(s *Statement) string {
 		return assignmentToString(s)
 	case FunctionCall:
 		return functionCallToString(s)
-	case Require:
+	case Require, Authorize:
 		return requireToString(s)
-	case If:
+	case If, For, While, DoWhile:
 		return ifToString(s)
+	case Revert:
+		return printDepends(s.Depends)
+	case InlineAssembly:
+		return inlineAssemblyToString(s)
+	case Try:
+		return functionCallToString(s)
+	case Break, Continue, Placeholder:
+		return ""
 	}
 	logger.Warning.Println("Unhandled statement to string:", s.Type)
 	return ""
@@ -168,24 +304,34 @@ func assignmentToString(s *Statement) string {
 	return printModify(s.Modify) + " <- " + printDepends(s.Depends)
 }
 
+func inlineAssemblyToString(s *Statement) string {
+	var res string
+	for _, m := range s.Modify {
+		res += "[" + m.Identifier + "]* "
+	}
+	return res + "<- " + printDepends(s.Depends)
+}
+
 func functionCallToString(s *Statement) string {
-	funcString := func(funcs *[]ST.Symbol) string {
-		// reverse the order of the funcs
-		for i, j := 0, len(*funcs)-1; i < j; i, j = i+1, j-1 {
-			(*funcs)[i], (*funcs)[j] = (*funcs)[j], (*funcs)[i]
+	funcString := func(declare []ST.Symbol) string {
+		// reverse the order of the funcs on a copy, the statement is printed
+		// by several printers
+		funcs := make([]ST.Symbol, len(declare))
+		for i, f := range declare {
+			funcs[len(declare)-1-i] = f
 		}
 		// funcs[0].funcs[1].funcs[2]()
 		var res string
-		for i, f := range *funcs {
+		for i, f := range funcs {
 			res += f.Identifier
-			if i != len(*funcs)-1 {
+			if i != len(funcs)-1 {
 				res += "."
 			} else {
 				res += "()"
 			}
 		}
 		return res
-	}(&s.Declare)
+	}(s.Declare)
 	return funcString + printDepends(s.Depends)
 }
 
@@ -1,12 +1,18 @@
 package models
 
+import "encoding/json"
+
 type SoliditySourceCode = string
 type EVMByteCode = string
 
 type Contract struct {
 	ContractName string       `json:"contractName"`
+	SourcePath   string       `json:"sourcePath"` // path of the .sol file, or source unit name for the compiler output
 	SolidityCode SolidityCode `json:"solidityCode"`
 	EVMCode      EVMCode      `json:"evmCode"`
+	// compiler outputs, empty when the contract is read from files
+	ABI           json.RawMessage `json:"abi,omitempty"`
+	StorageLayout json.RawMessage `json:"storageLayout,omitempty"`
 }
 
 type SolidityCode struct {
@@ -14,5 +20,8 @@ type SolidityCode struct {
 }
 
 type EVMCode struct {
-	ByteCode EVMByteCode
+	ByteCode          EVMByteCode
+	DeployedByteCode  EVMByteCode
+	SourceMap         string
+	DeployedSourceMap string
 }
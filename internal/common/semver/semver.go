@@ -0,0 +1,272 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a MAJOR.MINOR.PATCH version, solc builds carry no pre-release tag
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or greater than o
+func (v Version) Compare(o Version) int {
+	for _, d := range [...]int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		} else if d > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v Version) Less(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// Parse parses a complete version, a leading "v" is accepted
+func Parse(s string) (Version, error) {
+	p, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if p.parts < 3 {
+		return Version{}, fmt.Errorf("incomplete version %q", s)
+	}
+	return p.Version, nil
+}
+
+// partial is a version where the trailing components may be missing or
+// wildcards, e.g. "0.8" or "0.x"
+type partial struct {
+	Version
+	parts int // number of numeric components
+}
+
+var partialRegexp = regexp.MustCompile(`^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?$`)
+
+func parsePartial(s string) (partial, error) {
+	m := partialRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return partial{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	var p partial
+	components := [...]*int{&p.Major, &p.Minor, &p.Patch}
+	for i, c := range m[1:] {
+		if c == "" || c == "x" || c == "X" || c == "*" {
+			break
+		}
+		n, err := strconv.Atoi(c)
+		if err != nil {
+			return partial{}, fmt.Errorf("invalid version %q: %v", s, err)
+		}
+		*components[i] = n
+		p.parts++
+	}
+	return p, nil
+}
+
+// next returns the lowest version above every version matching p, e.g. 0.9.0 for "0.8"
+func (p partial) next() Version {
+	switch p.parts {
+	case 0:
+		return Version{Major: 1 << 30}
+	case 1:
+		return Version{Major: p.Major + 1}
+	case 2:
+		return Version{Major: p.Major, Minor: p.Minor + 1}
+	}
+	return Version{Major: p.Major, Minor: p.Minor, Patch: p.Patch + 1}
+}
+
+type comparator struct {
+	op      string // "<", "<=", ">", ">=" or "="
+	version Version
+}
+
+func (c comparator) match(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return cmp == 0
+}
+
+// Constraint is a version range as written in a `pragma solidity` directive,
+// e.g. "^0.4.18", ">=0.4.22 <0.9.0" or "0.5.0 - 0.6.0 || ^0.8.0"
+type Constraint struct {
+	raw string
+	// alternatives separated by ||, each one is a conjunction of comparators
+	sets [][]comparator
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Check reports whether v satisfies the constraint
+func (c *Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if matchAll(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAll(set []comparator, v Version) bool {
+	for _, comp := range set {
+		if !comp.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Min returns the lowest version satisfying the constraint, ok is false if
+// no version does, e.g. ">=0.6.0 <0.5.0"
+func (c *Constraint) Min() (min Version, ok bool) {
+	for _, set := range c.sets {
+		var lower Version
+		for _, comp := range set {
+			v := comp.version
+			switch comp.op {
+			case ">":
+				v.Patch++
+			case "<", "<=":
+				continue
+			}
+			if lower.Less(v) {
+				lower = v
+			}
+		}
+		if !matchAll(set, lower) {
+			continue
+		}
+		if !ok || lower.Less(min) {
+			min, ok = lower, true
+		}
+	}
+	return min, ok
+}
+
+var operatorRegexp = regexp.MustCompile(`(\^|~|>=|<=|>|<|=)\s+`)
+
+// ParseConstraint parses a version range in the npm semver syntax used by solc
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+
+	for _, alternative := range strings.Split(c.raw, "||") {
+		// ">= 0.4.22" is valid, glue the operators to their version
+		alternative = operatorRegexp.ReplaceAllString(strings.TrimSpace(alternative), "$1")
+		fields := strings.Fields(alternative)
+
+		var set []comparator
+		for i := 0; i < len(fields); i++ {
+			// hyphen range "0.4.0 - 0.5.0"
+			if i+2 < len(fields) && fields[i+1] == "-" {
+				comps, err := hyphenRange(fields[i], fields[i+2])
+				if err != nil {
+					return nil, err
+				}
+				set = append(set, comps...)
+				i += 2
+				continue
+			}
+			comps, err := parseComparator(fields[i])
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, comps...)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+func hyphenRange(from, to string) ([]comparator, error) {
+	lower, err := parsePartial(from)
+	if err != nil {
+		return nil, err
+	}
+	upper, err := parsePartial(to)
+	if err != nil {
+		return nil, err
+	}
+	comps := []comparator{{">=", lower.Version}}
+	if upper.parts == 3 {
+		return append(comps, comparator{"<=", upper.Version}), nil
+	}
+	return append(comps, comparator{"<", upper.next()}), nil
+}
+
+func parseComparator(s string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(s, prefix) {
+			op = prefix
+			break
+		}
+	}
+	p, err := parsePartial(s[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "^":
+		// the first non-zero component may not change
+		upper := p.next()
+		switch {
+		case p.Major > 0 || p.parts == 1:
+			upper = Version{Major: p.Major + 1}
+		case p.Minor > 0 || p.parts == 2:
+			upper = Version{Minor: p.Minor + 1}
+		}
+		return []comparator{{">=", p.Version}, {"<", upper}}, nil
+	case "~":
+		upper := Version{Major: p.Major, Minor: p.Minor + 1}
+		if p.parts == 1 {
+			upper = Version{Major: p.Major + 1}
+		}
+		return []comparator{{">=", p.Version}, {"<", upper}}, nil
+	case ">", "<=":
+		// >0.4 excludes every 0.4.x, <=0.4 includes them
+		if p.parts < 3 {
+			if op == ">" {
+				return []comparator{{">=", p.next()}}, nil
+			}
+			return []comparator{{"<", p.next()}}, nil
+		}
+		return []comparator{{op, p.Version}}, nil
+	case ">=", "<":
+		return []comparator{{op, p.Version}}, nil
+	}
+
+	// "0.8.19", "=0.8.19", "0.8" or "*"
+	if p.parts == 3 {
+		return []comparator{{"=", p.Version}}, nil
+	}
+	return []comparator{{">=", p.Version}, {"<", p.next()}}, nil
+}
@@ -1,10 +1,12 @@
 package compiler
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
-	"runtime"
+	"strings"
+	"txtracker/internal/common/semver"
 	"txtracker/internal/logger"
 )
 
@@ -14,35 +16,45 @@ type Compiler interface {
 }
 
 type SolidityCompiler struct {
+	solcManager *SolcManager
 }
 
-// NewSolidityCompiler creates a new SolidityCompiler.
-
-func NewSolidityCompiler() Compiler {
-	return &SolidityCompiler{}
+// NewSolidityCompiler creates a new SolidityCompiler using the solc builds of solcDir.
+func NewSolidityCompiler(solcDir string) Compiler {
+	return &SolidityCompiler{
+		solcManager: NewSolcManager(solcDir),
+	}
 }
 
+// compactASTVersion is the first solc version writing the compact JSON AST
+var compactASTVersion = semver.Version{Major: 0, Minor: 4, Patch: 12}
+
+// SolidityToAST_JSON compiles the file with a solc version matching its pragma
+// and writes its JSON AST next to it, in SolidityPath + ".ast.json"
 func (s *SolidityCompiler) SolidityToAST_JSON(SolidityPath string) error {
-	fmt.Println("SolidityToAST_JSON called with path:", SolidityPath)
-
-	// source virtual environment at ../../venv/bin/activate
-	var cmdString string
-	//fmt.Println(runtime.GOOS)
-	if runtime.GOOS == "darwin" {
-		fmt.Println("macOS detected")
-		cmdString = "solc --ast-compact-json " + SolidityPath
-	} else if runtime.GOOS == "linux" {
-		fmt.Println("Linux detected")
-		cmdString = "source ../../venv/bin/activate && solc --ast-compact-json " + SolidityPath
-	} else {
-		fmt.Println("OS not detected")
-		logger.Fatal.Println("OS not detected. ")
-		panic("OS not detected.")
-	}
-
-	cmd := exec.Command("zsh", "-c", cmdString)
-
-	// remove .sol and add .ast.json
+	logger.Info.Println("SolidityToAST_JSON called with path:", SolidityPath)
+
+	source, err := os.ReadFile(SolidityPath)
+	if err != nil {
+		return fmt.Errorf("error reading solidity file: %v", err)
+	}
+	constraints, err := PragmaConstraints(string(source))
+	if err != nil {
+		return err
+	}
+	solc, err := s.solcManager.Select(constraints)
+	if err != nil {
+		return err
+	}
+	logger.Info.Println("Using solc", solc.Version.String(), "at", solc.Path)
+
+	// solc < 0.4.12 only writes the legacy JSON AST, which the AST parser reads too
+	astFlag := "--ast-compact-json"
+	if solc.Version.Less(compactASTVersion) {
+		astFlag = "--ast-json"
+	}
+	cmd := exec.Command(solc.Path, astFlag, SolidityPath)
+
 	astFileName := SolidityPath + ".ast.json"
 	astFile, err := os.Create(astFileName)
 	if err != nil {
@@ -50,12 +62,16 @@ func (s *SolidityCompiler) SolidityToAST_JSON(SolidityPath string) error {
 	}
 	defer astFile.Close()
 
+	var stderr bytes.Buffer
 	cmd.Stdout = astFile
-
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error running solc: %v", err)
+		os.Remove(astFileName)
+		return fmt.Errorf("error running solc %s: %v\n%s", solc.Version.String(), err, strings.TrimSpace(stderr.String()))
+	}
+	if stderr.Len() > 0 {
+		logger.Warning.Println(stderr.String())
 	}
 
 	return nil
@@ -0,0 +1,163 @@
+package compiler
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"txtracker/internal/common/semver"
+	"txtracker/internal/logger"
+)
+
+// DefaultSolcDir returns the directory where solc-select installs its builds
+func DefaultSolcDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".solc-select/artifacts"
+	}
+	return filepath.Join(home, ".solc-select", "artifacts")
+}
+
+// SolcBinary is a solc executable found in the solc directory
+type SolcBinary struct {
+	Version semver.Version
+	Path    string
+}
+
+// SolcManager selects a solc binary matching the pragma of a source file from a
+// directory of solc builds. Binaries are found up to one sub-directory deep and
+// recognized by their name, e.g. solc-0.8.19, solc-v0.4.26 or
+// solc-linux-amd64-v0.8.19+commit.7dd6d404, which covers the layouts of
+// solc-select (artifacts/solc-0.8.19/solc-0.8.19) and svm (0.8.19/solc-0.8.19).
+type SolcManager struct {
+	Dir      string
+	binaries []SolcBinary
+}
+
+func NewSolcManager(dir string) *SolcManager {
+	return &SolcManager{
+		Dir: dir,
+	}
+}
+
+var solcNameRegexp = regexp.MustCompile(`^solc.*?v?(\d+\.\d+\.\d+)`)
+
+// Installed returns the solc binaries of the directory, from the lowest version to the highest
+func (m *SolcManager) Installed() ([]SolcBinary, error) {
+	if m.binaries != nil {
+		return m.binaries, nil
+	}
+
+	if _, err := os.Stat(m.Dir); err != nil {
+		return nil, fmt.Errorf("solc directory not found: %v", err)
+	}
+
+	found := make(map[semver.Version]string)
+	err := filepath.WalkDir(m.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel, _ := filepath.Rel(m.Dir, path); strings.Count(rel, string(filepath.Separator)) > 0 {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		match := solcNameRegexp.FindStringSubmatch(d.Name())
+		if match == nil {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.Mode()&0111 == 0 {
+			// not executable, e.g. a downloaded list.json
+			return nil
+		}
+		version, err := semver.Parse(match[1])
+		if err != nil {
+			return nil
+		}
+		if _, ok := found[version]; !ok {
+			found[version] = path
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing solc directory: %v", err)
+	}
+
+	binaries := make([]SolcBinary, 0, len(found))
+	for version, path := range found {
+		binaries = append(binaries, SolcBinary{Version: version, Path: path})
+	}
+	sort.Slice(binaries, func(i, j int) bool {
+		return binaries[i].Version.Less(binaries[j].Version)
+	})
+	m.binaries = binaries
+	return binaries, nil
+}
+
+// Select returns the highest installed version satisfying every constraint.
+// Without constraints the highest installed version is returned.
+func (m *SolcManager) Select(constraints []*semver.Constraint) (SolcBinary, error) {
+	binaries, err := m.Installed()
+	if err != nil {
+		return SolcBinary{}, err
+	}
+	if len(binaries) == 0 {
+		return SolcBinary{}, fmt.Errorf("no solc binary installed in %s", m.Dir)
+	}
+
+	for i := len(binaries) - 1; i >= 0; i-- {
+		ok := true
+		for _, c := range constraints {
+			if !c.Check(binaries[i].Version) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return binaries[i], nil
+		}
+	}
+
+	var pragmas, installed []string
+	for _, c := range constraints {
+		pragmas = append(pragmas, c.String())
+	}
+	for _, b := range binaries {
+		installed = append(installed, b.Version.String())
+	}
+	return SolcBinary{}, fmt.Errorf("no installed solc version satisfies pragma solidity %s (installed in %s: %s)",
+		strings.Join(pragmas, " and "), m.Dir, strings.Join(installed, ", "))
+}
+
+var (
+	pragmaRegexp  = regexp.MustCompile(`pragma\s+solidity\s+([^;]+);`)
+	commentRegexp = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+)
+
+// PragmaConstraints returns the version ranges of every `pragma solidity`
+// directive of the source. A flattened file has one per original file.
+func PragmaConstraints(source string) ([]*semver.Constraint, error) {
+	var res []*semver.Constraint
+	seen := make(map[string]bool)
+	for _, match := range pragmaRegexp.FindAllStringSubmatch(commentRegexp.ReplaceAllString(source, ""), -1) {
+		if seen[strings.TrimSpace(match[1])] {
+			continue
+		}
+		seen[strings.TrimSpace(match[1])] = true
+		c, err := semver.ParseConstraint(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pragma solidity %q: %v", match[1], err)
+		}
+		res = append(res, c)
+	}
+	if len(res) == 0 {
+		logger.Warning.Println("No pragma solidity found")
+	}
+	return res, nil
+}
@@ -0,0 +1,418 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"txtracker/internal/common/models"
+	"txtracker/internal/common/semver"
+	"txtracker/internal/logger"
+	"txtracker/internal/srcmap"
+)
+
+// StandardJSONInput is the input of `solc --standard-json`
+type StandardJSONInput struct {
+	Language string                 `json:"language"`
+	Sources  map[string]SourceInput `json:"sources"`
+	Settings StandardJSONSettings   `json:"settings"`
+}
+
+type SourceInput struct {
+	Content string `json:"content"`
+}
+
+type StandardJSONSettings struct {
+	Remappings      []string                       `json:"remappings,omitempty"`
+	Optimizer       OptimizerSettings              `json:"optimizer"`
+	OutputSelection map[string]map[string][]string `json:"outputSelection"`
+}
+
+type OptimizerSettings struct {
+	Enabled bool `json:"enabled"`
+	Runs    int  `json:"runs"`
+}
+
+// StandardJSONOutput is the output of `solc --standard-json`
+type StandardJSONOutput struct {
+	Errors    []CompilerError                      `json:"errors"`
+	Sources   map[string]SourceOutput              `json:"sources"`
+	Contracts map[string]map[string]ContractOutput `json:"contracts"`
+}
+
+type SourceOutput struct {
+	ID        int             `json:"id"`
+	AST       json.RawMessage `json:"ast"`
+	LegacyAST json.RawMessage `json:"legacyAST"` // the only AST of solc 0.4.11
+}
+
+type ContractOutput struct {
+	ABI           json.RawMessage `json:"abi"`
+	StorageLayout json.RawMessage `json:"storageLayout"`
+	EVM           struct {
+		Bytecode         BytecodeOutput `json:"bytecode"`
+		DeployedBytecode BytecodeOutput `json:"deployedBytecode"`
+	} `json:"evm"`
+}
+
+type BytecodeOutput struct {
+	Object    string `json:"object"`
+	SourceMap string `json:"sourceMap"`
+}
+
+// CompilerError is an error or a warning reported by solc
+type CompilerError struct {
+	Severity         string `json:"severity"` // error | warning | info
+	Type             string `json:"type"`
+	Component        string `json:"component"`
+	Message          string `json:"message"`
+	FormattedMessage string `json:"formattedMessage"`
+	SourceLocation   struct {
+		File  string `json:"file"`
+		Start int    `json:"start"`
+		End   int    `json:"end"`
+	} `json:"sourceLocation"`
+}
+
+func (e CompilerError) String() string {
+	if e.FormattedMessage != "" {
+		return strings.TrimSpace(e.FormattedMessage)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.SourceLocation.File, e.Type, e.Message)
+}
+
+// StandardJSONCompiler compiles a file together with its imports through
+// `solc --standard-json`. Imports are resolved like solc does: relative imports
+// from the name of the importing source unit, the others as they are written,
+// then remappings are applied. The resulting source unit names are looked up
+// in the base path first, then in the include paths.
+type StandardJSONCompiler struct {
+	solcManager  *SolcManager
+	BasePath     string
+	IncludePaths []string
+	Remappings   []string // [context:]prefix=target
+	Optimizer    OptimizerSettings
+	// result of the last compilation
+	Input  *StandardJSONInput
+	Output *StandardJSONOutput
+}
+
+// NewStandardJSONCompiler creates a StandardJSONCompiler using the solc builds of solcDir.
+// Without remappings, the remappings.txt of the base path is used if it exists.
+func NewStandardJSONCompiler(solcDir string, basePath string, remappings []string) *StandardJSONCompiler {
+	if len(remappings) == 0 {
+		remappings = readRemappings(filepath.Join(basePath, "remappings.txt"))
+	}
+	return &StandardJSONCompiler{
+		solcManager:  NewSolcManager(solcDir),
+		BasePath:     basePath,
+		IncludePaths: []string{"lib", "node_modules"},
+		Remappings:   remappings,
+		Optimizer:    OptimizerSettings{Enabled: false, Runs: 200},
+	}
+}
+
+func readRemappings(path string) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var res []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// SolidityToAST_JSON compiles the file and writes the compact JSON AST of every
+// source unit of the compilation in SolidityPath + ".ast.json", by source index,
+// each after a header line as solc --ast-compact-json prints them. Errors reported
+// by solc are returned, the warnings stay in Output.
+func (s *StandardJSONCompiler) SolidityToAST_JSON(SolidityPath string) error {
+	logger.Info.Println("SolidityToAST_JSON called with path:", SolidityPath)
+
+	output, err := s.Compile(SolidityPath)
+	if err != nil {
+		return err
+	}
+
+	name, err := s.sourceUnitName(SolidityPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := output.Sources[name]; !ok {
+		return fmt.Errorf("no AST for %s in the solc output", name)
+	}
+
+	names := sortedKeys(output.Sources)
+	sort.SliceStable(names, func(i, j int) bool {
+		return output.Sources[names[i]].ID < output.Sources[names[j]].ID
+	})
+	var ast bytes.Buffer
+	ast.WriteString("JSON AST (compact format):\n\n")
+	for _, name := range names {
+		source := output.Sources[name]
+		if len(source.AST) == 0 {
+			source.AST = source.LegacyAST
+		}
+		if len(source.AST) == 0 {
+			return fmt.Errorf("no AST for %s in the solc output", name)
+		}
+		// indented like the output of solc --ast-compact-json
+		fmt.Fprintf(&ast, "\n======= %s =======\n", name)
+		if err := json.Indent(&ast, source.AST, "", "  "); err != nil {
+			return fmt.Errorf("error formatting ast: %v", err)
+		}
+		ast.WriteString("\n")
+	}
+	if err := os.WriteFile(SolidityPath+".ast.json", ast.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error creating ast file: %v", err)
+	}
+	return nil
+}
+
+// Compile builds the input from the file and its imports, runs solc with a version
+// matching the pragmas of every source, and returns its output. An error is returned
+// if solc fails or reports errors. The warnings are left to the caller, in Output;
+// after a failure before solc answers, Output is nil.
+func (s *StandardJSONCompiler) Compile(SolidityPath string) (*StandardJSONOutput, error) {
+	s.Input, s.Output = nil, nil
+	input, err := s.BuildInput(SolidityPath)
+	if err != nil {
+		return nil, err
+	}
+	s.Input = input
+
+	var constraints []*semver.Constraint
+	for _, name := range sortedKeys(input.Sources) {
+		c, err := PragmaConstraints(input.Sources[name].Content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		constraints = append(constraints, c...)
+	}
+	solc, err := s.solcManager.Select(constraints)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info.Println("Using solc", solc.Version.String(), "at", solc.Path)
+
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding standard json input: %v", err)
+	}
+
+	cmd := exec.Command(solc.Path, "--standard-json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running solc %s: %v\n%s", solc.Version.String(), err, strings.TrimSpace(stderr.String()))
+	}
+
+	output, err := ParseStandardJSONOutput(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	s.Output = output
+
+	if errs := output.ErrorMessages(); len(errs) > 0 {
+		return output, fmt.Errorf("solc %s reported %d error(s):\n%s", solc.Version.String(), len(errs), strings.Join(errs, "\n"))
+	}
+	return output, nil
+}
+
+func ParseStandardJSONOutput(data []byte) (*StandardJSONOutput, error) {
+	var output StandardJSONOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("error decoding standard json output: %v", err)
+	}
+	return &output, nil
+}
+
+// ErrorMessages returns the messages of the errors, warnings excluded
+func (o *StandardJSONOutput) ErrorMessages() []string {
+	var res []string
+	for _, e := range o.Errors {
+		if e.Severity == "error" {
+			res = append(res, e.String())
+		}
+	}
+	return res
+}
+
+func (o *StandardJSONOutput) Warnings() []CompilerError {
+	var res []CompilerError
+	for _, e := range o.Errors {
+		if e.Severity == "warning" {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// Contracts returns the compiled contracts of the last compilation, sorted by
+// source unit and contract name
+func (s *StandardJSONCompiler) Contracts() []models.Contract {
+	var res []models.Contract
+	if s.Input == nil || s.Output == nil {
+		return res
+	}
+	for _, source := range sortedKeys(s.Output.Contracts) {
+		for _, name := range sortedKeys(s.Output.Contracts[source]) {
+			out := s.Output.Contracts[source][name]
+			res = append(res, models.Contract{
+				ContractName:  name,
+				SourcePath:    source,
+				SolidityCode:  models.SolidityCode{SourceCode: s.Input.Sources[source].Content},
+				ABI:           out.ABI,
+				StorageLayout: out.StorageLayout,
+				EVMCode: models.EVMCode{
+					ByteCode:          out.EVM.Bytecode.Object,
+					DeployedByteCode:  out.EVM.DeployedBytecode.Object,
+					SourceMap:         out.EVM.Bytecode.SourceMap,
+					DeployedSourceMap: out.EVM.DeployedBytecode.SourceMap,
+				},
+			})
+		}
+	}
+	return res
+}
+
+// Sources returns the source files of the last compilation by file index, named
+// by their source unit names
+func (s *StandardJSONCompiler) Sources() *srcmap.SourceMap {
+	res := srcmap.NewSourceMap()
+	if s.Input == nil || s.Output == nil {
+		return res
+	}
+	for name, source := range s.Output.Sources {
+		if input, ok := s.Input.Sources[name]; ok {
+			res.Add(source.ID, name, []byte(input.Content))
+		}
+	}
+	return res
+}
+
+var importRegexp = regexp.MustCompile(`(?m)^\s*import\s+(?:[^"';]*?\bfrom\s+)?["']([^"']+)["']`)
+
+// BuildInput returns the standard JSON input with the file and every source it imports
+func (s *StandardJSONCompiler) BuildInput(SolidityPath string) (*StandardJSONInput, error) {
+	input := &StandardJSONInput{
+		Language: "Solidity",
+		Sources:  make(map[string]SourceInput),
+		Settings: StandardJSONSettings{
+			Remappings: s.Remappings,
+			Optimizer:  s.Optimizer,
+			OutputSelection: map[string]map[string][]string{
+				"*": {
+					"":  {"ast"},
+					"*": {"abi", "storageLayout", "evm.bytecode.object", "evm.bytecode.sourceMap", "evm.deployedBytecode.object", "evm.deployedBytecode.sourceMap"},
+				},
+			},
+		},
+	}
+
+	entry, err := s.sourceUnitName(SolidityPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := []string{entry}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := input.Sources[name]; ok {
+			continue
+		}
+
+		content, err := s._readSource(name)
+		if err != nil {
+			return nil, err
+		}
+		input.Sources[name] = SourceInput{Content: content}
+
+		for _, match := range importRegexp.FindAllStringSubmatch(commentRegexp.ReplaceAllString(content, ""), -1) {
+			queue = append(queue, s._resolveImport(name, match[1]))
+		}
+	}
+	return input, nil
+}
+
+// sourceUnitName returns the path of the file relative to the base path
+func (s *StandardJSONCompiler) sourceUnitName(SolidityPath string) (string, error) {
+	base, err := filepath.Abs(s.BasePath)
+	if err != nil {
+		return "", err
+	}
+	file, err := filepath.Abs(SolidityPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(base, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside of the base path %s", SolidityPath, s.BasePath)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// _resolveImport returns the source unit name of an import of the given source unit
+func (s *StandardJSONCompiler) _resolveImport(importer, importPath string) string {
+	name := importPath
+	if strings.HasPrefix(importPath, "./") || strings.HasPrefix(importPath, "../") {
+		name = path.Join(path.Dir(importer), importPath)
+	}
+
+	// the longest matching prefix wins, context-specific remappings first
+	best, bestContext := -1, -1
+	var target string
+	for _, remapping := range s.Remappings {
+		context, rest := "", remapping
+		if i := strings.Index(remapping, ":"); i >= 0 && i < strings.Index(remapping, "=") {
+			context, rest = remapping[:i], remapping[i+1:]
+		}
+		prefix, to, ok := strings.Cut(rest, "=")
+		if !ok || !strings.HasPrefix(importer, context) || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if len(context) > bestContext || (len(context) == bestContext && len(prefix) > best) {
+			best, bestContext, target = len(prefix), len(context), to+name[len(prefix):]
+		}
+	}
+	if best >= 0 {
+		name = target
+	}
+	return name
+}
+
+func (s *StandardJSONCompiler) _readSource(name string) (string, error) {
+	dirs := append([]string{s.BasePath}, s.IncludePaths...)
+	for i, dir := range dirs {
+		if i > 0 && !filepath.IsAbs(dir) {
+			dir = filepath.Join(s.BasePath, dir)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err == nil {
+			return string(content), nil
+		}
+	}
+	return "", fmt.Errorf("source %q not found in %s", name, strings.Join(dirs, ", "))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,475 @@
+package detectors
+
+import (
+	"sort"
+	"strings"
+	AST "txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+	ST "txtracker/internal/symbol_table"
+)
+
+// Guard is an Authorize statement of an entry point: a require, an if or a call
+// checking the caller
+type Guard struct {
+	Node      *AST.Common
+	Modifier  string // modifier holding the guard, empty in the function body
+	Condition string // the check as written, e.g. msg.sender == owner
+}
+
+// String returns the modifier holding the guard, or its condition
+func (g *Guard) String() string {
+	if g.Modifier != "" {
+		return g.Modifier
+	}
+	return g.Condition
+}
+
+// StateWrite is a write to a state variable reachable from an entry point
+type StateWrite struct {
+	Variable string
+	ID       int // declaration ID of the state variable
+	Node     *AST.Common
+	Via      string   // internal function holding the write, empty in the entry point
+	Guards   []*Guard // guards on every path from the entry to the write
+}
+
+// Permission lists the state variables an entry point can write
+type Permission struct {
+	Function *CFG.Function
+	Writes   []*StateWrite
+}
+
+// Unguarded returns the writes reached without passing any guard
+func (p *Permission) Unguarded() []*StateWrite {
+	var res []*StateWrite
+	for _, w := range p.Writes {
+		if len(w.Guards) == 0 {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+// Guards returns the distinct guards of the writes to a variable, "" standing
+// for an unguarded write. ok is false if the entry point never writes it.
+func (p *Permission) Guards(variable string) (guards []string, ok bool) {
+	seen := make(map[string]bool)
+	for _, w := range p.Writes {
+		if w.Variable != variable {
+			continue
+		}
+		ok = true
+		names := []string{""}
+		if len(w.Guards) > 0 {
+			names = nil
+			for _, g := range w.Guards {
+				names = append(names, g.String())
+			}
+		}
+		name := strings.Join(names, ", ")
+		if !seen[name] {
+			seen[name] = true
+			guards = append(guards, name)
+		}
+	}
+	return guards, ok
+}
+
+// PermissionMatrix tells which entry points of a deployed contract write which
+// state variables, and under which guard. Constructors and the entry points
+// writing nothing are left out.
+type PermissionMatrix struct {
+	Contract    string
+	Variables   []string // the state variables written by an entry point, sorted
+	Permissions []*Permission
+	// state variables compared to the caller by a guard, e.g. owner
+	Checked map[string]bool
+}
+
+// NewPermissionMatrices returns the permission matrix of every deployed contract of
+// the context. A write is guarded when an Authorize statement of the CFG stands on
+// every path from the entry to the write; the writes of the internal functions
+// called share the guards of the call site.
+func NewPermissionMatrices(ctx *Context) []*PermissionMatrix {
+	a := newAccessAnalysis(ctx)
+
+	var res []*PermissionMatrix
+	matrices := make(map[string]*PermissionMatrix)
+	for _, entry := range ctx.CFG.EntryPoints {
+		contract := strings.Split(entry.Name, "::")[0]
+		m, ok := matrices[contract]
+		if !ok {
+			m = &PermissionMatrix{Contract: contract, Checked: make(map[string]bool)}
+			matrices[contract] = m
+			res = append(res, m)
+		}
+		for _, block := range entry.Blocks {
+			for _, stmt := range block.Statements {
+				if stmt.Type != CFG.Authorize {
+					continue
+				}
+				for _, symbol := range stmt.Depends {
+					if symbol.Type == ST.StateVariable {
+						m.Checked[symbol.Identifier] = true
+					}
+				}
+			}
+		}
+		if entry.Kind == AST.FunctionKind_Constructor {
+			continue
+		}
+		if writes := a._writes(entry); len(writes) > 0 {
+			m.Permissions = append(m.Permissions, &Permission{Function: entry, Writes: writes})
+		}
+	}
+
+	for _, m := range res {
+		variables := make(map[string]bool)
+		for _, p := range m.Permissions {
+			for _, w := range p.Writes {
+				variables[w.Variable] = true
+			}
+		}
+		for v := range variables {
+			m.Variables = append(m.Variables, v)
+		}
+		sort.Strings(m.Variables)
+	}
+	return res
+}
+
+// StateAccess is the state an entry point reads and writes. The state variables
+// are named after their symbols, e.g. Bank::balances.
+type StateAccess struct {
+	Function *CFG.Function
+	Reads    []string // state variables read, in guards included
+	// state variables read before the entry point assigns them as a whole, on
+	// some path: the values left by the previous transactions
+	Inputs []string
+	Writes []string // state variables written, by the internal functions called included
+}
+
+// NewStateAccesses returns the state access of every entry point of the context,
+// in the order of the entry points. Each list holds distinct values in the order
+// they appear in the blocks.
+func NewStateAccesses(ctx *Context) []*StateAccess {
+	a := newAccessAnalysis(ctx)
+	var res []*StateAccess
+	for _, entry := range ctx.CFG.EntryPoints {
+		res = append(res, a._access(entry))
+	}
+	return res
+}
+
+type accessAnalysis struct {
+	ctx       *Context
+	guards    map[*CFG.Statement]*Guard
+	summaries map[*AST.FunctionDefinition]*internalEffects
+}
+
+// internalEffects are the writes and the external calls of an internal function
+// and of the functions it calls, whatever the path
+type internalEffects struct {
+	writes []*StateWrite
+	calls  []string // named as in the summary, e.g. msg.sender.call
+}
+
+func newAccessAnalysis(ctx *Context) *accessAnalysis {
+	return &accessAnalysis{
+		ctx:       ctx,
+		guards:    make(map[*CFG.Statement]*Guard),
+		summaries: make(map[*AST.FunctionDefinition]*internalEffects),
+	}
+}
+
+// _access propagates along the CFG of an entry point the state variables assigned
+// as a whole on every path until a fixpoint, then collects the accesses. A read
+// is an input unless the variable was assigned before on every path.
+func (a *accessAnalysis) _access(entry *CFG.Function) *StateAccess {
+	access := &StateAccess{Function: entry, Reads: []string{}, Inputs: []string{}, Writes: []string{}}
+	if entry.Entry == nil {
+		return access
+	}
+
+	in := map[*CFG.Block]map[string]bool{entry.Entry: {}}
+	worklist := []*CFG.Block{entry.Entry}
+	for len(worklist) > 0 {
+		block := worklist[0]
+		worklist = worklist[1:]
+
+		out := a._assigned(entry, block, in[block], nil)
+		for _, succ := range block.Successors() {
+			if assigned, ok := in[succ]; !ok {
+				in[succ] = out
+				worklist = append(worklist, succ)
+			} else if common := intersectSets(assigned, out); len(common) < len(assigned) {
+				in[succ] = common
+				worklist = append(worklist, succ)
+			}
+		}
+	}
+
+	for _, block := range entry.Blocks {
+		if assigned, ok := in[block]; ok {
+			a._assigned(entry, block, assigned, access)
+		}
+	}
+	return access
+}
+
+// _assigned returns the state variables assigned as a whole at the end of the
+// block, given those assigned at its start. With an access, it records the reads
+// and writes of the statements as well.
+func (a *accessAnalysis) _assigned(entry *CFG.Function, block *CFG.Block, assigned map[string]bool, access *StateAccess) map[string]bool {
+	contract := strings.Split(entry.Name, "::")[0]
+	res := make(map[string]bool, len(assigned))
+	for name := range assigned {
+		res[name] = true
+	}
+
+	for _, stmt := range block.Statements {
+		if access != nil {
+			reads := append(append([]ST.Symbol{}, stmt.Depends...), stmt.IndexReads()...)
+			if readsAssigned(stmt) {
+				reads = append(reads, stmt.Writes()...)
+			}
+			for _, symbol := range reads {
+				if symbol.Type != ST.StateVariable {
+					continue
+				}
+				access.Reads = appendNew(access.Reads, symbol.Namespace.String())
+				if !res[symbol.Namespace.String()] {
+					access.Inputs = appendNew(access.Inputs, symbol.Namespace.String())
+				}
+			}
+			for _, symbol := range stmt.Writes() {
+				if symbol.Type == ST.StateVariable {
+					access.Writes = appendNew(access.Writes, symbol.Namespace.String())
+				}
+			}
+			for _, w := range a._calledWrites(CFG.EvaluatedExpression(&stmt.ASTNode)) {
+				access.Writes = appendNew(access.Writes, contract+"::"+w.Variable)
+			}
+		}
+
+		// writing an element of a mapping or an array leaves the others to read
+		for _, lvalue := range CFG.AssignedExpressions(&stmt.ASTNode) {
+			if _, ok := lvalue.ASTNode.(*AST.Identifier); !ok {
+				continue
+			}
+			if _, vd := stateVariableOf(a.ctx.SymbolTable, lvalue); vd != nil {
+				res[contract+"::"+vd.Name] = true
+			}
+		}
+	}
+	return res
+}
+
+// readsAssigned reports whether the statement reads the variables it assigns,
+// e.g. x += 1 or x++
+func readsAssigned(stmt *CFG.Statement) bool {
+	exprStmt, ok := stmt.ASTNode.ASTNode.(*AST.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	switch expr := exprStmt.Expression.ASTNode.(type) {
+	case *AST.Assignment:
+		return expr.Operator != AST.AssignmentOperator_Assignment
+	case *AST.UnaryOperation:
+		return expr.Operator != AST.UnaryOperator_Delete
+	}
+	return false
+}
+
+// _writes propagates the guards along the CFG of an entry point until a fixpoint,
+// keeping at each block the guards common to every incoming path, then collects
+// the writes
+func (a *accessAnalysis) _writes(entry *CFG.Function) []*StateWrite {
+	if entry.Entry == nil {
+		return nil
+	}
+
+	in := map[*CFG.Block][]*Guard{entry.Entry: nil}
+	worklist := []*CFG.Block{entry.Entry}
+	for len(worklist) > 0 {
+		block := worklist[0]
+		worklist = worklist[1:]
+
+		out := a._transfer(block, in[block], nil)
+		for _, succ := range block.Successors() {
+			if guards, ok := in[succ]; !ok {
+				in[succ] = out
+				worklist = append(worklist, succ)
+			} else if common := intersect(guards, out); len(common) < len(guards) {
+				in[succ] = common
+				worklist = append(worklist, succ)
+			}
+		}
+	}
+
+	var writes []*StateWrite
+	for _, block := range entry.Blocks {
+		if guards, ok := in[block]; ok {
+			a._transfer(block, guards, &writes)
+		}
+	}
+	return writes
+}
+
+func (a *accessAnalysis) _transfer(block *CFG.Block, guards []*Guard, writes *[]*StateWrite) []*Guard {
+	guards = append([]*Guard{}, guards...)
+	for _, stmt := range block.Statements {
+		if stmt.Type == CFG.Authorize {
+			guards = append(guards, a._guard(block, stmt))
+			continue
+		}
+		if writes == nil {
+			continue
+		}
+
+		for _, symbol := range stmt.Writes() {
+			if symbol.Type != ST.StateVariable {
+				continue
+			}
+			if decl := a.ctx.SymbolTable.LookupDeclaration(symbol.DeclarationID); decl != nil {
+				if vd, ok := decl.ASTNode.(*AST.VariableDeclaration); ok {
+					*writes = append(*writes, &StateWrite{
+						Variable: vd.Name,
+						ID:       symbol.DeclarationID,
+						Node:     &stmt.ASTNode,
+						Guards:   guards,
+					})
+				}
+			}
+		}
+		for _, w := range a._calledWrites(CFG.EvaluatedExpression(&stmt.ASTNode)) {
+			w.Guards = guards
+			*writes = append(*writes, w)
+		}
+	}
+	return guards
+}
+
+// _guard returns the single Guard of an Authorize statement
+func (a *accessAnalysis) _guard(block *CFG.Block, stmt *CFG.Statement) *Guard {
+	if g, ok := a.guards[stmt]; ok {
+		return g
+	}
+
+	g := &Guard{Node: &stmt.ASTNode, Modifier: block.Modifier}
+	switch n := stmt.ASTNode.ASTNode.(type) {
+	case *AST.IfStatement:
+		g.Condition = render(n.Condition)
+	case *AST.ExpressionStatement:
+		call := n.Expression.ASTNode.(*AST.FunctionCall)
+		if ident, ok := call.Expression.ASTNode.(*AST.Identifier); ok && ident.Name == "require" && len(call.Arguments) > 0 {
+			g.Condition = render(call.Arguments[0])
+		} else {
+			g.Condition = render(n.Expression)
+		}
+	}
+	a.guards[stmt] = g
+	return g
+}
+
+// _calledWrites returns the writes of the internal functions called by an expression
+func (a *accessAnalysis) _calledWrites(expr *AST.Common) []*StateWrite {
+	var res []*StateWrite
+	AST.Inspect(expr, func(node *AST.Common) bool {
+		call, ok := node.ASTNode.(*AST.FunctionCall)
+		if !ok {
+			return true
+		}
+		if callee := internalCallee(a.ctx.SymbolTable, call); callee != nil {
+			for _, w := range a._summary(callee).writes {
+				copied := *w
+				if copied.Via == "" {
+					copied.Via = callee.Name
+				}
+				res = append(res, &copied)
+			}
+		}
+		return true
+	})
+	return res
+}
+
+// _summary returns the writes and the external calls of an internal function
+// and of the functions it calls, whatever the path. Recursive calls contribute
+// nothing.
+func (a *accessAnalysis) _summary(funcDef *AST.FunctionDefinition) *internalEffects {
+	if summary, ok := a.summaries[funcDef]; ok {
+		return summary
+	}
+	summary := &internalEffects{}
+	a.summaries[funcDef] = summary
+
+	addWrite := func(lvalue *AST.Common) {
+		if id, vd := stateVariableOf(a.ctx.SymbolTable, lvalue); vd != nil {
+			summary.writes = append(summary.writes, &StateWrite{Variable: vd.Name, ID: id, Node: lvalue})
+		}
+	}
+	AST.InspectBlock(&funcDef.Body, func(node *AST.Common) bool {
+		if name, ok := externalCall(node); ok {
+			summary.calls = appendNew(summary.calls, name)
+		}
+		switch n := node.ASTNode.(type) {
+		case *AST.Assignment:
+			for _, lvalue := range AST.LValues(n.LeftHandSide) {
+				addWrite(lvalue)
+			}
+		case *AST.UnaryOperation:
+			switch n.Operator {
+			case AST.UnaryOperator_Increment, AST.UnaryOperator_Decrement, AST.UnaryOperator_Delete:
+				addWrite(n.SubExpression)
+			}
+		case *AST.YulFunctionCall:
+			if n.FunctionName.Name == "sstore" && len(n.Arguments) == 2 {
+				for _, slot := range AST.YulSlots(n.Arguments[0]) {
+					addWrite(slot)
+				}
+			}
+		case *AST.FunctionCall:
+			if callee := internalCallee(a.ctx.SymbolTable, n); callee != nil {
+				called := a._summary(callee)
+				for _, w := range called.writes {
+					copied := *w
+					if copied.Via == "" {
+						copied.Via = callee.Name
+					}
+					summary.writes = append(summary.writes, &copied)
+				}
+				for _, name := range called.calls {
+					summary.calls = appendNew(summary.calls, name)
+				}
+			}
+		}
+		return true
+	})
+	return summary
+}
+
+// intersect returns the guards of a also in b, in the order of a
+func intersect(a, b []*Guard) []*Guard {
+	var res []*Guard
+	for _, g := range a {
+		for _, other := range b {
+			if g == other {
+				res = append(res, g)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// intersectSets returns the names in both a and b
+func intersectSets(a, b map[string]bool) map[string]bool {
+	res := make(map[string]bool)
+	for name := range a {
+		if b[name] {
+			res[name] = true
+		}
+	}
+	return res
+}
@@ -0,0 +1,237 @@
+package detectors
+
+import (
+	"strings"
+	AST "txtracker/internal/ast"
+	"txtracker/internal/callgraph"
+	ST "txtracker/internal/symbol_table"
+)
+
+// lowLevelCall returns the member used by a call on an address, e.g. "call" or "transfer"
+func lowLevelCall(call *AST.FunctionCall) (string, bool) {
+	if call.Kind != AST.FunctionCallKind_FunctionCall {
+		return "", false
+	}
+	member, ok := callgraph.UnwrapCallee(call.Expression).ASTNode.(*AST.MemberAccess)
+	if !ok || !callgraph.LowLevelMembers[member.MemberName] {
+		return "", false
+	}
+	if !strings.HasPrefix(member.Expression.GetTypeDescriptions().TypeIdentifier, "t_address") {
+		return "", false
+	}
+	return member.MemberName, true
+}
+
+// isContractCall reports whether the call is a message call to a function of another
+// contract that may modify the state, e.g. token.transfer(to, amount) or this.f()
+func isContractCall(call *AST.FunctionCall) bool {
+	if call.Kind != AST.FunctionCallKind_FunctionCall {
+		return false
+	}
+	member, ok := callgraph.UnwrapCallee(call.Expression).ASTNode.(*AST.MemberAccess)
+	if !ok || !strings.HasPrefix(member.Expression.GetTypeDescriptions().TypeIdentifier, "t_contract") {
+		return false
+	}
+	// function (address) view external returns (uint256)
+	for _, mutability := range strings.Fields(member.TypeDescriptions.TypeString) {
+		if mutability == string(AST.StateMutability_View) || mutability == string(AST.StateMutability_Pure) || mutability == "constant" {
+			return false
+		}
+	}
+	return true
+}
+
+// isExternalCall reports whether the call hands the control to another contract
+func isExternalCall(call *AST.FunctionCall) bool {
+	if member, ok := lowLevelCall(call); ok {
+		return member != "staticcall"
+	}
+	return isContractCall(call)
+}
+
+// yulExternalCalls are the builtins of assembly calling another contract, by the
+// index of the address among their arguments
+var yulExternalCalls = map[string]int{
+	"call":         1,
+	"callcode":     1,
+	"delegatecall": 1,
+	"selfdestruct": 0,
+	"suicide":      0, // selfdestruct of solc < 0.5
+}
+
+// isYulExternalCall reports whether an assembly call hands the control or the
+// balance to another contract, e.g. delegatecall(gas(), impl, 0, calldatasize(), 0, 0)
+func isYulExternalCall(call *AST.YulFunctionCall) bool {
+	i, ok := yulExternalCalls[call.FunctionName.Name]
+	return ok && len(call.Arguments) > i
+}
+
+// runsYulCallee reports whether an assembly call runs the code of the contract it
+// calls; selfdestruct only sends the balance, so it cannot reenter
+func runsYulCallee(call *AST.YulFunctionCall) bool {
+	switch call.FunctionName.Name {
+	case "selfdestruct", "suicide":
+		return false
+	}
+	return isYulExternalCall(call)
+}
+
+// internalCallee returns the definition of a function called without a message
+// call: a function of the contract or its bases, a library function or super.f()
+func internalCallee(symbolTable *ST.GlobalSymbolTable, call *AST.FunctionCall) *AST.FunctionDefinition {
+	if call.Kind != AST.FunctionCallKind_FunctionCall || isExternalCall(call) {
+		return nil
+	}
+
+	var id int
+	switch callee := callgraph.UnwrapCallee(call.Expression).ASTNode.(type) {
+	case *AST.Identifier:
+		id = callee.ReferencedDeclaration
+	case *AST.MemberAccess:
+		if _, ok := lowLevelCall(call); ok {
+			return nil
+		}
+		id = callee.ReferencedDeclaration
+	default:
+		return nil
+	}
+
+	decl := symbolTable.LookupDeclaration(id)
+	if decl == nil {
+		return nil
+	}
+	if funcDef, ok := decl.ASTNode.(*AST.FunctionDefinition); ok && funcDef.Implemented {
+		return funcDef
+	}
+	return nil
+}
+
+// callName names the function of a call site, e.g. msg.sender.call() for
+// msg.sender.call{value: amount}("") and delegatecall(impl) for
+// delegatecall(gas(), impl, 0, calldatasize(), 0, 0)
+func callName(node *AST.Common) string {
+	switch n := node.ASTNode.(type) {
+	case *AST.FunctionCall:
+		return render(callgraph.UnwrapCallee(n.Expression)) + "()"
+	case *AST.YulFunctionCall:
+		if i, ok := yulExternalCalls[n.FunctionName.Name]; ok && len(n.Arguments) > i {
+			return n.FunctionName.Name + "(" + AST.YulSource(n.Arguments[i]) + ")"
+		}
+	}
+	return render(node)
+}
+
+// externalCall names the call of a node handing the control to another contract,
+// as the summary lists it, e.g. msg.sender.call or delegatecall(impl)
+func externalCall(node *AST.Common) (string, bool) {
+	switch call := node.ASTNode.(type) {
+	case *AST.FunctionCall:
+		if isExternalCall(call) {
+			return render(callgraph.UnwrapCallee(call.Expression)), true
+		}
+	case *AST.YulFunctionCall:
+		if isYulExternalCall(call) {
+			return callName(node), true
+		}
+	}
+	return "", false
+}
+
+// render writes an expression back as Solidity, e.g. roles[ADMIN][msg.sender]
+func render(expr *AST.Common) string {
+	if expr == nil {
+		return ""
+	}
+	switch n := expr.ASTNode.(type) {
+	case *AST.Identifier:
+		return n.Name
+	case *AST.Literal:
+		if n.Kind == AST.LiteralKind_String {
+			return "\"" + n.Value + "\""
+		}
+		return n.Value
+	case *AST.MemberAccess:
+		return render(n.Expression) + "." + n.MemberName
+	case *AST.IndexAccess:
+		return render(n.BaseExpression) + "[" + render(n.IndexExpression) + "]"
+	case *AST.BinaryOperation:
+		return render(n.LeftExpression) + " " + string(n.Operator) + " " + render(n.RightExpression)
+	case *AST.Assignment:
+		return render(n.LeftHandSide) + " " + string(n.Operator) + " " + render(n.RightHandSide)
+	case *AST.UnaryOperation:
+		if !n.Prefix {
+			return render(n.SubExpression) + string(n.Operator)
+		}
+		if n.Operator == AST.UnaryOperator_Delete {
+			return "delete " + render(n.SubExpression)
+		}
+		return string(n.Operator) + render(n.SubExpression)
+	case *AST.TupleExpression:
+		var components []string
+		for _, c := range n.Components {
+			components = append(components, render(c))
+		}
+		return "(" + strings.Join(components, ", ") + ")"
+	case *AST.FunctionCall:
+		var arguments []string
+		for _, arg := range n.Arguments {
+			arguments = append(arguments, render(arg))
+		}
+		return render(n.Expression) + "(" + strings.Join(arguments, ", ") + ")"
+	case *AST.FunctionCallOptions:
+		var options []string
+		for i, name := range n.Names {
+			if i < len(n.Options) {
+				options = append(options, name+": "+render(n.Options[i]))
+			}
+		}
+		return render(n.Expression) + "{" + strings.Join(options, ", ") + "}"
+	case *AST.ElementaryTypeNameExpression:
+		// payable(msg.sender) of solc >= 0.6 converts to address payable
+		if n.TypeName.StateMutability == AST.StateMutability_Payable {
+			return "payable"
+		}
+		if n.TypeName.Name != "" {
+			return n.TypeName.Name
+		}
+		return strings.TrimSuffix(strings.TrimPrefix(n.TypeDescriptions.TypeString, "type("), ")")
+	case *AST.YulFunctionCall:
+		return AST.YulSource(expr)
+	}
+	return expr.NodeType
+}
+
+// stateVariableOf returns the state variable at the root of an lvalue and its
+// declaration ID, e.g. balances for balances[msg.sender].amount
+func stateVariableOf(symbolTable *ST.GlobalSymbolTable, expr *AST.Common) (int, *AST.VariableDeclaration) {
+	for expr != nil {
+		switch n := expr.ASTNode.(type) {
+		case *AST.Identifier:
+			decl := symbolTable.LookupDeclaration(n.ReferencedDeclaration)
+			if decl == nil {
+				return 0, nil
+			}
+			if vd, ok := decl.ASTNode.(*AST.VariableDeclaration); ok && vd.StateVariable {
+				return n.ReferencedDeclaration, vd
+			}
+			return 0, nil
+		case *AST.IndexAccess:
+			expr = n.BaseExpression
+		case *AST.MemberAccess:
+			expr = n.Expression
+		case *AST.YulIdentifier:
+			// x.slot of an sstore
+			decl := symbolTable.LookupDeclaration(n.Declaration)
+			if decl == nil {
+				return 0, nil
+			}
+			if vd, ok := decl.ASTNode.(*AST.VariableDeclaration); ok && vd.StateVariable {
+				return n.Declaration, vd
+			}
+			return 0, nil
+		default:
+			return 0, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,299 @@
+package detectors
+
+import (
+	"fmt"
+	"strings"
+	AST "txtracker/internal/ast"
+	"txtracker/internal/common/semver"
+)
+
+// checkedSince is the first solc version reverting on arithmetic overflow
+var checkedSince = semver.Version{Major: 0, Minor: 8, Patch: 0}
+
+// overflowOperators maps the arithmetic operators that may wrap around to the
+// way they do
+var overflowOperators = map[string]string{
+	"+":  "overflow",
+	"-":  "underflow",
+	"*":  "overflow",
+	"**": "overflow",
+	"+=": "overflow",
+	"-=": "underflow",
+	"*=": "overflow",
+}
+
+// IntegerOverflow reports the integer arithmetic that wraps around silently: a
+// +, -, * or ** operation, or a +=, -= or *= assignment, compiled without
+// overflow check, in the functions and modifiers of the contracts and in the
+// free functions. The arithmetic is checked when every version allowed by the
+// `pragma solidity` of the source unit is at least 0.8.0, except in an
+// `unchecked` block.
+//
+// An operation whose result is checked by a following require or assert against
+// one of its operands, e.g. c = a + b; assert(c >= a), or whose operands are
+// compared by a preceding one, e.g. require(balance >= value) before
+// balance -= value, is not reported. A library function checking its arithmetic
+// this way is an overflow guard, e.g. SafeMath.sub, and is skipped as a whole:
+// the calls a.sub(b) replacing the operators are the fix.
+type IntegerOverflow struct {
+	ctx *Context
+}
+
+func NewIntegerOverflow() Detector {
+	return &IntegerOverflow{}
+}
+
+func (d *IntegerOverflow) ID() string {
+	return "integer-overflow"
+}
+
+func (d *IntegerOverflow) Description() string {
+	return "integer arithmetic without overflow check"
+}
+
+func (d *IntegerOverflow) Severity() Severity {
+	return High
+}
+
+func (d *IntegerOverflow) Confidence() Confidence {
+	return MediumConfidence
+}
+
+func (d *IntegerOverflow) Detect(ctx *Context) []Finding {
+	d.ctx = ctx
+	// why the arithmetic outside of unchecked blocks wraps around, empty if it reverts
+	reason := "without pragma solidity"
+	if pragma, checked := checkedArithmetic(ctx.Root); checked {
+		reason = ""
+	} else if pragma != "" {
+		reason = "under pragma solidity " + pragma
+	}
+
+	var findings []Finding
+	for _, node := range ctx.Root.Children {
+		switch def := node.ASTNode.(type) {
+		case *AST.FunctionDefinition:
+			// a free function, declared outside of the contracts
+			findings = append(findings, d._detectBody(def.DisplayName(), &def.Body, reason, false)...)
+		case *AST.ContractDefinition:
+			library := def.ContractKind == AST.ContractKind_Library
+			for _, member := range node.Children {
+				switch m := member.ASTNode.(type) {
+				case *AST.FunctionDefinition:
+					findings = append(findings, d._detectBody(def.Name+"::"+m.DisplayName(), &m.Body, reason, library)...)
+				case *AST.ModifierDefinition:
+					findings = append(findings, d._detectBody(def.Name+"::"+m.Name, &m.Body, reason, library)...)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// _detectBody reports the arithmetic of a function or modifier body, unless it
+// is a library function guarding its arithmetic
+func (d *IntegerOverflow) _detectBody(function string, body *AST.Block, reason string, library bool) []Finding {
+	checks := newArithmeticChecks(body)
+	if library && checks.guards(body) {
+		return nil
+	}
+	var findings []Finding
+	for _, stmt := range body.Statements {
+		findings = append(findings, d._detect(function, stmt, reason, checks)...)
+	}
+	return findings
+}
+
+// _detect reports the arithmetic below node that wraps around for the given
+// reason, or only the arithmetic of unchecked blocks if reason is empty
+func (d *IntegerOverflow) _detect(function string, node *AST.Common, reason string, checks *arithmeticChecks) []Finding {
+	var findings []Finding
+	AST.Inspect(node, func(node *AST.Common) bool {
+		var left, right *AST.Common
+		var operator, result string
+		switch n := node.ASTNode.(type) {
+		case *AST.UncheckedBlock:
+			for _, stmt := range n.Statements {
+				findings = append(findings, d._detect(function, stmt, "in an unchecked block", checks)...)
+			}
+			return false
+		case *AST.BinaryOperation:
+			left, right, operator = n.LeftExpression, n.RightExpression, string(n.Operator)
+			result = n.TypeDescriptions.TypeIdentifier
+		case *AST.Assignment:
+			left, right, operator = n.LeftHandSide, n.RightHandSide, string(n.Operator)
+			result = n.TypeDescriptions.TypeIdentifier
+		default:
+			return true
+		}
+
+		kind, ok := overflowOperators[operator]
+		if reason == "" || !ok || !isInteger(result) || checks.checked[node] || checks.compares(node, left, right) {
+			return true
+		}
+		// the operand types as solc describes them, e.g. uint256 + uint8
+		message := fmt.Sprintf("%s may %s (%s %s %s) %s", render(node), kind,
+			left.GetTypeDescriptions().TypeString, operator, right.GetTypeDescriptions().TypeString, reason)
+		finding := NewFinding(d, d.ctx, node, message)
+		finding.Function = function
+		findings = append(findings, finding)
+		return true
+	})
+	return findings
+}
+
+// checkedArithmetic returns the `pragma solidity` ranges of a source unit, and
+// whether they only allow compilers checking the arithmetic
+func checkedArithmetic(root *AST.Common) (pragma string, checked bool) {
+	var ranges []string
+	seen := make(map[string]bool)
+	for _, node := range root.Children {
+		directive, ok := node.ASTNode.(*AST.PragmaDirective)
+		if !ok {
+			continue
+		}
+		r, ok := directive.VersionRange()
+		if !ok || seen[r] {
+			continue
+		}
+		// the same pragma repeated by flattened sources is listed once
+		seen[r] = true
+		ranges = append(ranges, r)
+		// every pragma must hold, one of them is enough to exclude solc < 0.8
+		if c, err := semver.ParseConstraint(r); err == nil {
+			if min, ok := c.Min(); ok && !min.Less(checkedSince) {
+				checked = true
+			}
+		}
+	}
+	return strings.Join(ranges, " and "), checked
+}
+
+// isInteger reports whether a type identifier is a uintN or an intN, literals
+// such as 2**256 - 1 being rationals computed by the compiler
+func isInteger(typeIdentifier string) bool {
+	return strings.HasPrefix(typeIdentifier, "t_uint") || strings.HasPrefix(typeIdentifier, "t_int")
+}
+
+// arithmeticChecks are the require and assert calls of a function body that
+// check its arithmetic
+type arithmeticChecks struct {
+	// checked are the operations whose result is checked against one of their
+	// operands, as in uint256 c = a + b; assert(c >= a);
+	checked map[*AST.Common]bool
+	// comparisons are the operands of the comparisons of the checks, e.g.
+	// balance and value for require(balance >= value)
+	comparisons []comparison
+}
+
+type comparison struct {
+	start    int
+	operands [2]string
+}
+
+func newArithmeticChecks(body *AST.Block) *arithmeticChecks {
+	res := &arithmeticChecks{checked: make(map[*AST.Common]bool)}
+	// the operations stored in a local variable, by the ID of the variable
+	results := make(map[int]*AST.Common)
+	AST.InspectBlock(body, func(node *AST.Common) bool {
+		switch n := node.ASTNode.(type) {
+		case *AST.VariableDeclarationStatement:
+			if len(n.Assignments) == 1 && isArithmetic(n.InitialValue) {
+				results[n.Assignments[0]] = n.InitialValue
+			}
+		case *AST.Assignment:
+			if ident, ok := n.LeftHandSide.ASTNode.(*AST.Identifier); ok && n.Operator == "=" && isArithmetic(n.RightHandSide) {
+				results[ident.ReferencedDeclaration] = n.RightHandSide
+			}
+		case *AST.FunctionCall:
+			if ident, ok := n.Expression.ASTNode.(*AST.Identifier); ok && (ident.Name == "require" || ident.Name == "assert") && len(n.Arguments) > 0 {
+				res._addCheck(node, n.Arguments[0], results)
+			}
+		}
+		return true
+	})
+	return res
+}
+
+// _addCheck records the comparisons of the condition of a check, and the
+// operations it checks the result of
+func (c *arithmeticChecks) _addCheck(call, condition *AST.Common, results map[int]*AST.Common) {
+	start := 0
+	if location, err := ParseSrc(call.Src); err == nil {
+		start = location.Start
+	}
+
+	rendered := make(map[string]bool)
+	var stored []*AST.Common
+	AST.Inspect(condition, func(node *AST.Common) bool {
+		rendered[render(node)] = true
+		switch n := node.ASTNode.(type) {
+		case *AST.Identifier:
+			if op, ok := results[n.ReferencedDeclaration]; ok {
+				stored = append(stored, op)
+			}
+		case *AST.BinaryOperation:
+			switch n.Operator {
+			case "<", "<=", ">", ">=":
+				c.comparisons = append(c.comparisons, comparison{start, [2]string{render(n.LeftExpression), render(n.RightExpression)}})
+			}
+		}
+		return true
+	})
+
+	// c >= a for c = a + b, c / a == b for c = a * b
+	for _, op := range stored {
+		n := op.ASTNode.(*AST.BinaryOperation)
+		if rendered[render(n.LeftExpression)] || rendered[render(n.RightExpression)] {
+			c.checked[op] = true
+		}
+	}
+}
+
+// compares reports whether a check preceding the operation compares its operands
+func (c *arithmeticChecks) compares(op, left, right *AST.Common) bool {
+	location, err := ParseSrc(op.Src)
+	if err != nil {
+		return false
+	}
+	l, r := render(left), render(right)
+	for _, cmp := range c.comparisons {
+		if cmp.start >= location.Start {
+			continue
+		}
+		if (cmp.operands[0] == l && cmp.operands[1] == r) || (cmp.operands[0] == r && cmp.operands[1] == l) {
+			return true
+		}
+	}
+	return false
+}
+
+// guards reports whether the checks of a body cover one of its operations, the
+// shape of a SafeMath function
+func (c *arithmeticChecks) guards(body *AST.Block) bool {
+	res := false
+	AST.InspectBlock(body, func(node *AST.Common) bool {
+		switch n := node.ASTNode.(type) {
+		case *AST.BinaryOperation:
+			res = res || c.checked[node] || (isArithmetic(node) && c.compares(node, n.LeftExpression, n.RightExpression))
+		case *AST.Assignment:
+			_, ok := overflowOperators[string(n.Operator)]
+			res = res || (ok && c.compares(node, n.LeftHandSide, n.RightHandSide))
+		}
+		return !res
+	})
+	return res
+}
+
+// isArithmetic reports whether expr is an operation that may wrap around, e.g. a + b
+func isArithmetic(expr *AST.Common) bool {
+	if expr == nil {
+		return false
+	}
+	op, ok := expr.ASTNode.(*AST.BinaryOperation)
+	if !ok {
+		return false
+	}
+	_, ok = overflowOperators[string(op.Operator)]
+	return ok
+}
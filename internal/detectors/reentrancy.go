@@ -0,0 +1,507 @@
+package detectors
+
+import (
+	"fmt"
+	"strings"
+	AST "txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+	ST "txtracker/internal/symbol_table"
+)
+
+// Reentrancy reports the state variables written after an external call on a
+// path of an entry point: the callee may call back into the contract while the
+// variable still holds its old value, e.g. a balance sent before being reset.
+//
+// The entry points are walked on their CFG, modifiers included. The writes are
+// taken from Statement.Modify; the internal functions they call are summarized
+// from their AST, in source order. Entry points protected by a mutex are skipped:
+// a bool state variable checked then set before any external call, and reset
+// after one, e.g. require(!locked); locked = true; _; locked = false.
+type Reentrancy struct {
+	ctx       *Context
+	summaries map[*AST.FunctionDefinition]*effects
+	effects   map[*CFG.Statement]*effects
+	// bool state variables the entry point being walked resets after a call
+	resets map[int]bool
+}
+
+func NewReentrancy() Detector {
+	return &Reentrancy{}
+}
+
+func (d *Reentrancy) ID() string {
+	return "reentrancy"
+}
+
+func (d *Reentrancy) Description() string {
+	return "state variable written after an external call"
+}
+
+func (d *Reentrancy) Severity() Severity {
+	return High
+}
+
+func (d *Reentrancy) Confidence() Confidence {
+	return MediumConfidence
+}
+
+// callSite is an external call, via is the internal function containing it
+type callSite struct {
+	node *AST.Common
+	via  string
+}
+
+// write is a write to a state variable, via is the internal function containing it
+type write struct {
+	id       int // declaration ID of the state variable
+	variable *AST.VariableDeclaration
+	node     *AST.Common
+	via      string
+}
+
+// lateWrite is a write following external calls inside an internal function
+type lateWrite struct {
+	write write
+	calls []callSite
+}
+
+// effects of a statement or of an internal function
+type effects struct {
+	calls  []callSite
+	writes []write
+	reads  map[int]bool // state variables read, by declaration ID
+	guards map[int]bool // state variables read by a require, an assert or a condition
+	// bool state variables checked then set before any call, the locks taken if
+	// the entry point resets them after a call
+	locks map[int]bool
+	late  []lateWrite
+}
+
+func newEffects() *effects {
+	return &effects{
+		reads:  make(map[int]bool),
+		guards: make(map[int]bool),
+		locks:  make(map[int]bool),
+	}
+}
+
+// isLock reports whether a write may take a lock: it sets a bool state variable
+func isLock(w write) bool {
+	return w.variable.TypeDescriptions.TypeString == "bool"
+}
+
+// apply adds the effects of the next statement in execution order
+func (e *effects) apply(next *effects) {
+	for id := range next.guards {
+		e.guards[id] = true
+	}
+	for _, w := range next.writes {
+		if len(e.calls) > 0 {
+			e.late = append(e.late, lateWrite{write: w, calls: append([]callSite{}, e.calls...)})
+		} else if e.guards[w.id] && isLock(w) {
+			e.locks[w.id] = true
+		}
+	}
+	if len(e.calls) == 0 {
+		for id := range next.locks {
+			e.locks[id] = true
+		}
+	}
+	e.writes = append(e.writes, next.writes...)
+	e.calls = append(e.calls, next.calls...)
+	e.late = append(e.late, next.late...)
+	for id := range next.reads {
+		e.reads[id] = true
+	}
+}
+
+func (d *Reentrancy) Detect(ctx *Context) []Finding {
+	d.ctx = ctx
+	d.summaries = make(map[*AST.FunctionDefinition]*effects)
+	d.effects = make(map[*CFG.Statement]*effects)
+
+	// state variables a reentrant call could observe, per deployed contract
+	observed := make(map[string]map[int]bool)
+	for _, entry := range ctx.CFG.EntryPoints {
+		contract := strings.Split(entry.Name, "::")[0]
+		if observed[contract] == nil {
+			observed[contract] = make(map[int]bool)
+		}
+		for _, block := range entry.Blocks {
+			for _, stmt := range block.Statements {
+				eff := d._statementEffects(stmt)
+				d.effects[stmt] = eff
+				for id := range eff.reads {
+					observed[contract][id] = true
+				}
+			}
+		}
+	}
+
+	var findings []Finding
+	reported := make(map[string]bool)
+	for _, entry := range ctx.CFG.EntryPoints {
+		contract := strings.Split(entry.Name, "::")[0]
+		report := func(w write, calls []callSite) {
+			if !observed[contract][w.id] && w.variable.Visibility != AST.Visibility_Public {
+				return
+			}
+			key := w.node.Src + ":" + w.variable.Name
+			if reported[key] {
+				return
+			}
+			reported[key] = true
+			findings = append(findings, d._newFinding(entry, w, calls))
+		}
+		d._detectFunction(entry, report)
+	}
+	return findings
+}
+
+// flowState is the state at the beginning of a block
+type flowState struct {
+	calls   []callSite
+	checked map[int]bool
+	mutex   bool
+}
+
+func (s *flowState) copy() *flowState {
+	res := &flowState{calls: append([]callSite{}, s.calls...), checked: make(map[int]bool), mutex: s.mutex}
+	for id := range s.checked {
+		res.checked[id] = true
+	}
+	return res
+}
+
+// merge joins src into s and reports whether s changed
+func (s *flowState) merge(src *flowState) bool {
+	changed := false
+	for _, call := range src.calls {
+		found := false
+		for _, c := range s.calls {
+			if c.node == call.node {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.calls = append(s.calls, call)
+			changed = true
+		}
+	}
+	for id := range src.checked {
+		if !s.checked[id] {
+			s.checked[id] = true
+			changed = true
+		}
+	}
+	if s.mutex && !src.mutex {
+		s.mutex = false
+		changed = true
+	}
+	return changed
+}
+
+// _detectFunction propagates the external calls along the CFG of an entry point
+// until a fixpoint, then reports the writes reached by a call. A first walk finds
+// the bool state variables written after a call, the locks it may release.
+func (d *Reentrancy) _detectFunction(entry *CFG.Function, report func(w write, calls []callSite)) {
+	if entry.Entry == nil {
+		return
+	}
+
+	// without a lock to release, every write after a call is reported
+	d.resets = nil
+	resets := make(map[int]bool)
+	d._walk(entry, func(w write, calls []callSite) {
+		if isLock(w) {
+			resets[w.id] = true
+		}
+	})
+	d.resets = resets
+	d._walk(entry, report)
+}
+
+// _walk propagates the external calls and the locks taken along the CFG of an
+// entry point until a fixpoint, then calls report on the writes reached by a call
+// outside a lock
+func (d *Reentrancy) _walk(entry *CFG.Function, report func(w write, calls []callSite)) {
+	in := map[*CFG.Block]*flowState{entry.Entry: {checked: make(map[int]bool)}}
+	worklist := []*CFG.Block{entry.Entry}
+	for len(worklist) > 0 {
+		block := worklist[0]
+		worklist = worklist[1:]
+
+		out := d._transfer(block, in[block].copy(), nil)
+		for _, succ := range block.Successors() {
+			if in[succ] == nil {
+				in[succ] = out.copy()
+				worklist = append(worklist, succ)
+			} else if in[succ].merge(out) {
+				worklist = append(worklist, succ)
+			}
+		}
+	}
+
+	for _, block := range entry.Blocks {
+		if state, ok := in[block]; ok {
+			d._transfer(block, state.copy(), report)
+		}
+	}
+}
+
+func (d *Reentrancy) _transfer(block *CFG.Block, state *flowState, report func(w write, calls []callSite)) *flowState {
+	for _, stmt := range block.Statements {
+		eff := d.effects[stmt]
+		if eff == nil {
+			continue
+		}
+
+		for id := range eff.guards {
+			state.checked[id] = true
+		}
+		for _, w := range eff.writes {
+			if len(state.calls) == 0 && state.checked[w.id] && d.resets[w.id] {
+				state.mutex = true
+			} else if len(state.calls) > 0 && !state.mutex && report != nil {
+				report(w, state.calls)
+			}
+		}
+		if len(state.calls) == 0 {
+			for id := range eff.locks {
+				if d.resets[id] {
+					state.mutex = true
+				}
+			}
+		}
+		if !state.mutex && report != nil {
+			for _, late := range eff.late {
+				report(late.write, late.calls)
+			}
+		}
+		state.calls = append(state.calls, eff.calls...)
+	}
+	return state
+}
+
+// _statementEffects returns the effects of a statement of the CFG, the state
+// variable written by the statement itself is the first symbol of Modify
+func (d *Reentrancy) _statementEffects(stmt *CFG.Statement) *effects {
+	eff := newEffects()
+
+	for _, symbol := range stmt.Writes() {
+		if symbol.Type != ST.StateVariable {
+			continue
+		}
+		if decl := d.ctx.SymbolTable.LookupDeclaration(symbol.DeclarationID); decl != nil {
+			if vd, ok := decl.ASTNode.(*AST.VariableDeclaration); ok {
+				eff.writes = append(eff.writes, write{id: symbol.DeclarationID, variable: vd, node: &stmt.ASTNode})
+			}
+		}
+	}
+	for _, symbol := range stmt.Depends {
+		if symbol.Type == ST.StateVariable {
+			eff.reads[symbol.DeclarationID] = true
+		}
+	}
+	// balances[msg.sender] = 0 reads msg.sender only
+	for _, symbol := range stmt.IndexReads() {
+		if symbol.Type == ST.StateVariable {
+			eff.reads[symbol.DeclarationID] = true
+		}
+	}
+
+	switch stmt.Type {
+	case CFG.Require, CFG.Authorize, CFG.Assert, CFG.If, CFG.For, CFG.While, CFG.DoWhile:
+		for id := range eff.reads {
+			eff.guards[id] = true
+		}
+	}
+
+	d._collectCalls(CFG.EvaluatedExpression(&stmt.ASTNode), eff)
+	return eff
+}
+
+// _collectCalls adds the external calls of an expression, those of assembly
+// included but selfdestruct, and the effects of the internal functions it calls
+func (d *Reentrancy) _collectCalls(expr *AST.Common, eff *effects) {
+	AST.Inspect(expr, func(node *AST.Common) bool {
+		if call, ok := node.ASTNode.(*AST.YulFunctionCall); ok && runsYulCallee(call) {
+			eff.calls = append(eff.calls, callSite{node: node})
+			return true
+		}
+		call, ok := node.ASTNode.(*AST.FunctionCall)
+		if !ok {
+			return true
+		}
+		if isExternalCall(call) {
+			eff.calls = append(eff.calls, callSite{node: node})
+		} else if callee := internalCallee(d.ctx.SymbolTable, call); callee != nil {
+			eff.apply(d._summary(callee).via(callee.Name))
+		}
+		return true
+	})
+}
+
+// via returns a copy of the effects attributed to the internal function name
+func (e *effects) via(name string) *effects {
+	res := newEffects()
+	for id := range e.locks {
+		res.locks[id] = true
+	}
+	for id := range e.reads {
+		res.reads[id] = true
+	}
+	for id := range e.guards {
+		res.guards[id] = true
+	}
+	for _, c := range e.calls {
+		if c.via == "" {
+			c.via = name
+		}
+		res.calls = append(res.calls, c)
+	}
+	for _, w := range e.writes {
+		if w.via == "" {
+			w.via = name
+		}
+		res.writes = append(res.writes, w)
+	}
+	res.late = append(res.late, e.late...)
+	return res
+}
+
+// _summary returns the effects of an internal function, its statements taken
+// in source order. Recursive calls contribute nothing.
+func (d *Reentrancy) _summary(funcDef *AST.FunctionDefinition) *effects {
+	if summary, ok := d.summaries[funcDef]; ok {
+		return summary
+	}
+	summary := newEffects()
+	d.summaries[funcDef] = summary
+
+	AST.InspectBlock(&funcDef.Body, func(node *AST.Common) bool {
+		switch node.ASTNode.(type) {
+		case *AST.Block, *AST.TryCatchClause:
+			return true
+		case *AST.IfStatement, *AST.ForStatement, *AST.WhileStatement, *AST.DoWhileStatement, *AST.TryStatement:
+			eff := d._expressionEffects(CFG.EvaluatedExpression(node))
+			for id := range eff.reads {
+				eff.guards[id] = true
+			}
+			summary.apply(eff)
+			return true
+		case *AST.ExpressionStatement, *AST.VariableDeclarationStatement, *AST.Return, *AST.RevertStatement, *AST.EmitStatement, *AST.InlineAssembly:
+			eff := d._expressionEffects(node)
+			if isGuard(node) {
+				for id := range eff.reads {
+					eff.guards[id] = true
+				}
+			}
+			summary.apply(eff)
+		}
+		// expressions are handled with their statement
+		return false
+	})
+	return summary
+}
+
+// _expressionEffects returns the effects of a statement or an expression of an internal function
+func (d *Reentrancy) _expressionEffects(expr *AST.Common) *effects {
+	eff := newEffects()
+	addWrite := func(lvalue *AST.Common) {
+		if id, vd := stateVariableOf(d.ctx.SymbolTable, lvalue); vd != nil {
+			eff.writes = append(eff.writes, write{id: id, variable: vd, node: lvalue})
+		}
+	}
+
+	AST.Inspect(expr, func(node *AST.Common) bool {
+		switch n := node.ASTNode.(type) {
+		case *AST.Assignment:
+			addWrite(n.LeftHandSide)
+		case *AST.UnaryOperation:
+			switch n.Operator {
+			case AST.UnaryOperator_Increment, AST.UnaryOperator_Decrement, AST.UnaryOperator_Delete:
+				addWrite(n.SubExpression)
+			}
+		case *AST.Identifier:
+			if id, vd := stateVariableOf(d.ctx.SymbolTable, node); vd != nil {
+				eff.reads[id] = true
+			}
+		case *AST.YulFunctionCall:
+			if len(n.Arguments) == 0 {
+				break
+			}
+			for _, slot := range AST.YulSlots(n.Arguments[0]) {
+				switch n.FunctionName.Name {
+				case "sstore":
+					addWrite(slot)
+				case "sload":
+					if id, vd := stateVariableOf(d.ctx.SymbolTable, slot); vd != nil {
+						eff.reads[id] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+	d._collectCalls(expr, eff)
+	return eff
+}
+
+// isGuard reports whether the statement is a require or an assert
+func isGuard(stmt *AST.Common) bool {
+	exprStmt, ok := stmt.ASTNode.(*AST.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	call, ok := exprStmt.Expression.ASTNode.(*AST.FunctionCall)
+	if !ok {
+		return false
+	}
+	ident, ok := call.Expression.ASTNode.(*AST.Identifier)
+	return ok && (ident.Name == "require" || ident.Name == "assert")
+}
+
+func (d *Reentrancy) _newFinding(entry *CFG.Function, w write, calls []callSite) Finding {
+	var names []string
+	seen := make(map[string]bool)
+	for _, c := range calls {
+		name := callName(c.node)
+		if c.via != "" {
+			name += " in " + c.via + "()"
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	message := fmt.Sprintf("state variable %s is written", w.variable.Name)
+	if w.via != "" {
+		message += " in " + w.via + "()"
+	}
+	if len(names) == 1 {
+		message += " after the external call " + names[0]
+	} else {
+		message += " after the external calls " + strings.Join(names, ", ")
+	}
+
+	finding := NewFinding(d, d.ctx, w.node, message)
+	finding.Function = entry.Name
+	entryStep := Step{Location: SrcLocation(d.ctx, entry.Src), Message: "entry point " + entry.Name}
+	writeStep := Step{Location: finding.Location, Message: "write to " + w.variable.Name}
+	if w.via != "" {
+		writeStep.Message += " in " + w.via + "()"
+	}
+	for _, c := range calls {
+		location := NewLocation(d.ctx, c.node)
+		finding.Related = append(finding.Related, location)
+		callStep := Step{Location: location, Message: "external call " + callName(c.node)}
+		if c.via != "" {
+			callStep.Message += " in " + c.via + "()"
+		}
+		finding.Paths = append(finding.Paths, []Step{entryStep, callStep, writeStep})
+	}
+	return finding
+}
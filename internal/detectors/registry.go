@@ -0,0 +1,98 @@
+package detectors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"txtracker/internal/logger"
+)
+
+// registry maps the ID of every detector to its constructor
+var registry = map[string]func() Detector{
+	"integer-overflow":   NewIntegerOverflow,
+	"reentrancy":         NewReentrancy,
+	"unchecked-call":     NewUncheckedCall,
+	"unguarded-function": NewUnguardedFunction,
+}
+
+// Register adds a detector to the registry, it panics if the ID is already taken
+func Register(factory func() Detector) {
+	id := factory().ID()
+	if _, ok := registry[id]; ok {
+		panic("detector registered twice: " + id)
+	}
+	registry[id] = factory
+}
+
+// All returns a new instance of every registered detector, sorted by ID
+func All() []Detector {
+	var res []Detector
+	for _, factory := range registry {
+		res = append(res, factory())
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID() < res[j].ID()
+	})
+	return res
+}
+
+// Select returns the detectors to run: the enabled ones, or every registered
+// detector if none is enabled, minus the disabled ones.
+func Select(enabled []string, disabled []string) ([]Detector, error) {
+	for _, id := range append(append([]string{}, enabled...), disabled...) {
+		if _, ok := registry[id]; !ok {
+			return nil, fmt.Errorf("unknown detector %q (available: %s)", id, strings.Join(ids(), ", "))
+		}
+	}
+
+	var res []Detector
+	for _, d := range All() {
+		if len(enabled) > 0 && !contains(enabled, d.ID()) {
+			continue
+		}
+		if contains(disabled, d.ID()) {
+			continue
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+// Run runs the detectors over the context. The findings are sorted by location,
+// then by detector.
+func Run(detectors []Detector, ctx *Context) []Finding {
+	var findings []Finding
+	for _, d := range detectors {
+		logger.Info.Println("Running detector:", d.ID())
+		findings = append(findings, d.Detect(ctx)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Location, findings[j].Location
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		return findings[i].ID < findings[j].ID
+	})
+	return findings
+}
+
+func ids() []string {
+	var res []string
+	for id := range registry {
+		res = append(res, id)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package detectors
+
+import (
+	"strings"
+	AST "txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+	ST "txtracker/internal/symbol_table"
+)
+
+// FunctionSummary is the state access of an entry point of a deployed contract,
+// gathered from the statements of its CFG: the function body and its modifiers.
+// The writes and the external calls of the internal functions called are
+// included, whatever their path.
+type FunctionSummary struct {
+	Path          string   `json:"path"`
+	Contract      string   `json:"contract"`
+	Function      string   `json:"function"` // the signature, e.g. withdraw(uint256)
+	Visibility    string   `json:"visibility"`
+	Payable       bool     `json:"payable"`
+	Modifiers     []string `json:"modifiers"`
+	Reads         []string `json:"reads"`         // state variables read, in conditions included
+	Writes        []string `json:"writes"`        // state variables assigned
+	Events        []string `json:"events"`        // events emitted, e.g. Transfer
+	ExternalCalls []string `json:"externalCalls"` // e.g. msg.sender.call, token.transfer, delegatecall(impl) in assembly
+	Guards        []string `json:"guards"`        // conditions of the Authorize statements, e.g. msg.sender == owner
+}
+
+// NewFunctionSummaries returns a summary per entry point of the context, in the
+// order of the entry points. Each list holds distinct values in the order they
+// appear in the blocks.
+func NewFunctionSummaries(ctx *Context) []*FunctionSummary {
+	a := newAccessAnalysis(ctx)
+
+	var res []*FunctionSummary
+	for _, entry := range ctx.CFG.EntryPoints {
+		name := strings.SplitN(entry.Name, "::", 2)
+		s := &FunctionSummary{
+			Path:          ctx.Path,
+			Contract:      name[0],
+			Function:      entry.Signature,
+			Modifiers:     append([]string{}, entry.Modifiers...),
+			Reads:         []string{},
+			Writes:        []string{},
+			Events:        []string{},
+			ExternalCalls: []string{},
+			Guards:        []string{},
+		}
+		if decl := ctx.SymbolTable.LookupDeclaration(entry.SrcID); decl != nil {
+			switch def := decl.ASTNode.(type) {
+			case *AST.FunctionDefinition:
+				s.Visibility = string(def.Visibility)
+				s.Payable = def.StateMutability == AST.StateMutability_Payable
+			case *AST.VariableDeclaration: // the getter of a public state variable
+				s.Visibility = string(def.Visibility)
+			}
+		}
+
+		for _, block := range entry.Blocks {
+			for _, stmt := range block.Statements {
+				for _, symbol := range stmt.Writes() {
+					if symbol.Type == ST.StateVariable {
+						s.Writes = appendNew(s.Writes, symbol.Identifier)
+					}
+				}
+				for _, symbol := range stmt.IndexReads() {
+					if symbol.Type == ST.StateVariable {
+						s.Reads = appendNew(s.Reads, symbol.Identifier)
+					}
+				}
+				for _, symbol := range stmt.Depends {
+					switch symbol.Type {
+					case ST.StateVariable:
+						s.Reads = appendNew(s.Reads, symbol.Identifier)
+					case ST.Event:
+						s.Events = appendNew(s.Events, strings.TrimSuffix(symbol.Identifier, "()"))
+					}
+				}
+				if stmt.Type == CFG.Authorize {
+					s.Guards = appendNew(s.Guards, a._guard(block, stmt).Condition)
+				}
+				AST.Inspect(CFG.EvaluatedExpression(&stmt.ASTNode), func(node *AST.Common) bool {
+					if name, ok := externalCall(node); ok {
+						s.ExternalCalls = appendNew(s.ExternalCalls, name)
+					}
+					// the writes and the calls of _send(to, amount)
+					if call, ok := node.ASTNode.(*AST.FunctionCall); ok {
+						if callee := internalCallee(ctx.SymbolTable, call); callee != nil {
+							called := a._summary(callee)
+							for _, w := range called.writes {
+								s.Writes = appendNew(s.Writes, w.Variable)
+							}
+							for _, name := range called.calls {
+								s.ExternalCalls = appendNew(s.ExternalCalls, name)
+							}
+						}
+					}
+					return true
+				})
+			}
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// appendNew appends a value to a list unless it is already there
+func appendNew(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
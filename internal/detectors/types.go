@@ -0,0 +1,157 @@
+package detectors
+
+import (
+	"fmt"
+	AST "txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/srcmap"
+	ST "txtracker/internal/symbol_table"
+)
+
+type Severity int
+
+const (
+	Informational Severity = iota
+	Low
+	Medium
+	High
+)
+
+func (s Severity) String() string {
+	return [...]string{
+		"Informational",
+		"Low",
+		"Medium",
+		"High",
+	}[s]
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+type Confidence int
+
+const (
+	LowConfidence Confidence = iota
+	MediumConfidence
+	HighConfidence
+)
+
+func (c Confidence) String() string {
+	return [...]string{
+		"Low",
+		"Medium",
+		"High",
+	}[c]
+}
+
+func (c Confidence) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// Context holds what a detector may inspect for one source unit
+type Context struct {
+	Path        string // path of the source file or source unit name
+	Root        *AST.Common
+	SymbolTable *ST.GlobalSymbolTable
+	CFG         *CFG.CFG
+	Sources     *srcmap.SourceMap // source files of the compilation, nil if unknown
+}
+
+// Detector is a check run over a source unit
+type Detector interface {
+	ID() string // short kebab-case name used on the command line, e.g. reentrancy
+	Description() string
+	Severity() Severity
+	Confidence() Confidence
+	Detect(ctx *Context) []Finding
+}
+
+// Location is a range of the source code as written in Common.Src: "start:length:fileIndex".
+// Line and Column are set when the source file is known, starting at 1.
+type Location struct {
+	Path      string `json:"path"`
+	Start     int    `json:"start"`
+	Length    int    `json:"length"`
+	File      int    `json:"file"` // index of the source file in the compilation, -1 if unknown
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+}
+
+// ParseSrc parses the src attribute of an AST node
+func ParseSrc(src string) (Location, error) {
+	r, err := srcmap.Parse(src)
+	if err != nil {
+		return Location{}, err
+	}
+	return Location{Start: r.Start, Length: r.Length, File: r.File}, nil
+}
+
+// String returns path:line:column, or path@start:length if the source file is unknown
+func (l Location) String() string {
+	if l.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d", l.Path, l.Line, l.Column)
+	}
+	return fmt.Sprintf("%s@%d:%d", l.Path, l.Start, l.Length)
+}
+
+// Finding is an issue reported by a detector
+type Finding struct {
+	ID         string     `json:"id"` // ID of the detector
+	Severity   Severity   `json:"severity"`
+	Confidence Confidence `json:"confidence"`
+	Message    string     `json:"message"`
+	Function   string     `json:"function,omitempty"` // entry point, e.g. Token::transfer(address,uint256)
+	Location   Location   `json:"location"`
+	Related    []Location `json:"related,omitempty"` // other locations involved, e.g. the external call before a write
+	Snippet    string     `json:"snippet,omitempty"` // the source lines of Location, underlined
+	// execution paths from the entry point to Location, for the findings about an order of events
+	Paths [][]Step `json:"paths,omitempty"`
+}
+
+// Step is a location on an execution path with what happens there
+type Step struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
+}
+
+// NewLocation returns the location of an AST node of the context. The path is
+// the one of the file index of the node when the sources of the compilation are
+// known, the path of the context otherwise.
+func NewLocation(ctx *Context, node *AST.Common) Location {
+	return SrcLocation(ctx, node.Src)
+}
+
+// SrcLocation returns the location of a src attribute, e.g. the one of a CFG warning
+func SrcLocation(ctx *Context, src string) Location {
+	location, err := ParseSrc(src)
+	if err != nil {
+		location = Location{File: -1}
+	}
+	location.Path = ctx.Path
+	if file := ctx.Sources.File(location.File); file != nil && err == nil {
+		start, end := file.Position(location.Start), file.Position(location.Start+location.Length)
+		location.Path = file.Path
+		location.Line, location.Column = start.Line, start.Column
+		location.EndLine, location.EndColumn = end.Line, end.Column
+	}
+	return location
+}
+
+// NewFinding creates a finding of the detector located at node
+func NewFinding(d Detector, ctx *Context, node *AST.Common, message string) Finding {
+	finding := Finding{
+		ID:         d.ID(),
+		Severity:   d.Severity(),
+		Confidence: d.Confidence(),
+		Message:    message,
+		Location:   NewLocation(ctx, node),
+	}
+	if r, file, err := ctx.Sources.Resolve(node.Src); err == nil && file != nil {
+		finding.Snippet = file.Snippet(r)
+	}
+	return finding
+}
@@ -0,0 +1,197 @@
+package detectors
+
+import (
+	"fmt"
+	AST "txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+)
+
+// uncheckedCalls are the low-level calls returning false instead of reverting
+var uncheckedCalls = map[string]bool{
+	"call":         true,
+	"callcode":     true,
+	"delegatecall": true,
+	"send":         true,
+}
+
+// UncheckedCall reports the low-level calls whose success is ignored: the call
+// is a statement of its own, e.g. to.send(amount);, or its result is stored in a
+// local variable that no require, assert, condition or return reads.
+//
+// Every function and modifier of the source unit is inspected, internal ones
+// included.
+type UncheckedCall struct {
+	ctx *Context
+}
+
+func NewUncheckedCall() Detector {
+	return &UncheckedCall{}
+}
+
+func (d *UncheckedCall) ID() string {
+	return "unchecked-call"
+}
+
+func (d *UncheckedCall) Description() string {
+	return "return value of a low-level call or send not checked"
+}
+
+func (d *UncheckedCall) Severity() Severity {
+	return Medium
+}
+
+func (d *UncheckedCall) Confidence() Confidence {
+	return HighConfidence
+}
+
+func (d *UncheckedCall) Detect(ctx *Context) []Finding {
+	d.ctx = ctx
+	var findings []Finding
+	for _, contractDef := range ctx.Root.Children {
+		contract, ok := contractDef.ASTNode.(*AST.ContractDefinition)
+		if !ok {
+			continue
+		}
+		for _, node := range contractDef.Children {
+			switch def := node.ASTNode.(type) {
+			case *AST.FunctionDefinition:
+				findings = append(findings, d._detectBody(contract.Name+"::"+def.DisplayName(), &def.Body)...)
+			case *AST.ModifierDefinition:
+				findings = append(findings, d._detectBody(contract.Name+"::"+def.Name, &def.Body)...)
+			}
+		}
+	}
+	return findings
+}
+
+// storedCall is a low-level call whose success is stored in a local variable
+type storedCall struct {
+	call  *AST.Common
+	local string
+	id    int // declaration ID of the local variable
+}
+
+func (d *UncheckedCall) _detectBody(function string, body *AST.Block) []Finding {
+	var findings []Finding
+	report := func(call *AST.Common, message string) {
+		finding := NewFinding(d, d.ctx, call, message)
+		finding.Function = function
+		findings = append(findings, finding)
+	}
+
+	var stored []storedCall
+	AST.InspectBlock(body, func(node *AST.Common) bool {
+		switch n := node.ASTNode.(type) {
+		case *AST.ExpressionStatement:
+			if call := uncheckedCall(n.Expression); call != nil {
+				report(call, fmt.Sprintf("return value of %s is not checked", callName(call)))
+				return false
+			}
+			assign, ok := n.Expression.ASTNode.(*AST.Assignment)
+			if !ok {
+				return true
+			}
+			call := uncheckedCall(assign.RightHandSide)
+			if call == nil {
+				return true
+			}
+			// ok = to.send(amount) or (ok, data) = to.call(...)
+			target := assign.LeftHandSide
+			if tuple, ok := target.ASTNode.(*AST.TupleExpression); ok {
+				target = nil
+				if len(tuple.Components) > 0 {
+					target = tuple.Components[0]
+				}
+			}
+			if target == nil {
+				report(call, fmt.Sprintf("return value of %s is discarded", callName(call)))
+			} else if ident, ok := target.ASTNode.(*AST.Identifier); ok && d._isLocal(ident.ReferencedDeclaration) {
+				stored = append(stored, storedCall{call: call, local: ident.Name, id: ident.ReferencedDeclaration})
+			}
+			return false
+		case *AST.VariableDeclarationStatement:
+			call := uncheckedCall(n.InitialValue)
+			if call == nil {
+				return true
+			}
+			// bool ok = to.send(amount) or (bool ok, ) = to.call(...)
+			if len(n.Declarations) == 0 || n.Declarations[0] == nil || len(n.Assignments) == 0 {
+				report(call, fmt.Sprintf("return value of %s is discarded", callName(call)))
+			} else {
+				stored = append(stored, storedCall{call: call, local: n.Declarations[0].Name, id: n.Assignments[0]})
+			}
+			return false
+		}
+		return true
+	})
+
+	if len(stored) == 0 {
+		return findings
+	}
+	checked := checkedVariables(body)
+	for _, s := range stored {
+		if !checked[s.id] {
+			report(s.call, fmt.Sprintf("return value of %s is stored in %s, which is never checked", callName(s.call), s.local))
+		}
+	}
+	return findings
+}
+
+// _isLocal reports whether the declaration is a local variable or a parameter
+func (d *UncheckedCall) _isLocal(id int) bool {
+	decl := d.ctx.SymbolTable.LookupDeclaration(id)
+	if decl == nil {
+		// the declarations of the function bodies are not indexed
+		return true
+	}
+	vd, ok := decl.ASTNode.(*AST.VariableDeclaration)
+	return ok && !vd.StateVariable
+}
+
+// uncheckedCall returns expr if it is a call, callcode, delegatecall or send on an address
+func uncheckedCall(expr *AST.Common) *AST.Common {
+	if expr == nil {
+		return nil
+	}
+	call, ok := expr.ASTNode.(*AST.FunctionCall)
+	if !ok {
+		return nil
+	}
+	if member, ok := lowLevelCall(call); ok && uncheckedCalls[member] {
+		return expr
+	}
+	return nil
+}
+
+// checkedVariables returns the declaration IDs of the variables read by a
+// require, an assert, the condition of a branch or a loop, or a return
+func checkedVariables(body *AST.Block) map[int]bool {
+	checked := make(map[int]bool)
+	mark := func(expr *AST.Common) {
+		AST.Inspect(expr, func(node *AST.Common) bool {
+			if ident, ok := node.ASTNode.(*AST.Identifier); ok {
+				checked[ident.ReferencedDeclaration] = true
+			}
+			return true
+		})
+	}
+
+	AST.InspectBlock(body, func(node *AST.Common) bool {
+		switch n := node.ASTNode.(type) {
+		case *AST.IfStatement, *AST.ForStatement, *AST.WhileStatement, *AST.DoWhileStatement:
+			mark(CFG.EvaluatedExpression(node))
+		case *AST.Conditional:
+			mark(n.Condition)
+		case *AST.Return:
+			mark(n.Expression)
+		case *AST.FunctionCall:
+			if ident, ok := n.Expression.ASTNode.(*AST.Identifier); ok && (ident.Name == "require" || ident.Name == "assert") {
+				for _, arg := range n.Arguments {
+					mark(arg)
+				}
+			}
+		}
+		return true
+	})
+	return checked
+}
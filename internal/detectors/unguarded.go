@@ -0,0 +1,112 @@
+package detectors
+
+import (
+	"fmt"
+	"strings"
+	AST "txtracker/internal/ast"
+)
+
+// UnguardedFunction reports the entry points writing an access-controlled state
+// variable without any guard. A variable is access-controlled when a guard
+// compares it to the caller, e.g. owner or admins, or when it is not a mapping
+// nor an array and another entry point writes it under a guard, e.g. paused.
+// Mappings such as balances are written by the owner and by anyone alike.
+//
+// An entry point changing the state without any guard is not reported on its
+// own: deposit or transfer write balances for anyone by design. Only the writes
+// to the state the contract protects elsewhere make a finding.
+type UnguardedFunction struct{}
+
+func NewUnguardedFunction() Detector {
+	return &UnguardedFunction{}
+}
+
+func (d *UnguardedFunction) ID() string {
+	return "unguarded-function"
+}
+
+func (d *UnguardedFunction) Description() string {
+	return "access-controlled state variable written by a function without guard"
+}
+
+func (d *UnguardedFunction) Severity() Severity {
+	return High
+}
+
+func (d *UnguardedFunction) Confidence() Confidence {
+	return MediumConfidence
+}
+
+func (d *UnguardedFunction) Detect(ctx *Context) []Finding {
+	var findings []Finding
+	for _, m := range NewPermissionMatrices(ctx) {
+		// one guarded writer per variable, to explain the finding
+		guardedBy := make(map[string]*StateWrite)
+		writers := make(map[*StateWrite]*Permission)
+		for _, p := range m.Permissions {
+			for _, w := range p.Writes {
+				if len(w.Guards) > 0 && guardedBy[w.Variable] == nil && !isCollection(ctx, w.ID) {
+					guardedBy[w.Variable] = w
+					writers[w] = p
+				}
+			}
+		}
+
+		for _, p := range m.Permissions {
+			var variables []string
+			unguarded := make(map[string][]*StateWrite)
+			for _, w := range p.Unguarded() {
+				if !m.Checked[w.Variable] && guardedBy[w.Variable] == nil {
+					continue
+				}
+				if unguarded[w.Variable] == nil {
+					variables = append(variables, w.Variable)
+				}
+				unguarded[w.Variable] = append(unguarded[w.Variable], w)
+			}
+
+			for _, variable := range variables {
+				writes := unguarded[variable]
+				message := fmt.Sprintf("state variable %s is written without access control", variable)
+				if writes[0].Via != "" {
+					message = fmt.Sprintf("state variable %s is written in %s() without access control", variable, writes[0].Via)
+				}
+				if w := guardedBy[variable]; w != nil {
+					message += fmt.Sprintf(", %s writes it under %s", writers[w].Function.Name, guardNames(w.Guards))
+				} else {
+					message += ", guards compare it to the caller"
+				}
+
+				finding := NewFinding(d, ctx, writes[0].Node, message)
+				finding.Function = p.Function.Name
+				for _, w := range writes[1:] {
+					finding.Related = append(finding.Related, NewLocation(ctx, w.Node))
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings
+}
+
+func guardNames(guards []*Guard) string {
+	var names []string
+	for _, g := range guards {
+		names = append(names, g.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// isCollection reports whether a state variable is a mapping or an array
+func isCollection(ctx *Context, id int) bool {
+	decl := ctx.SymbolTable.LookupDeclaration(id)
+	if decl == nil {
+		return false
+	}
+	vd, ok := decl.ASTNode.(*AST.VariableDeclaration)
+	if !ok {
+		return false
+	}
+	ti := vd.TypeDescriptions.TypeIdentifier
+	return strings.HasPrefix(ti, "t_mapping") || strings.HasPrefix(ti, "t_array")
+}
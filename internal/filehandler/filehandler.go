@@ -25,71 +25,78 @@ type SolidityFileHandler struct {
 	isSpecific bool
 }
 
-// loadContracts replaces the init function and is called within the constructor
-
-func NewFileHandler(dataPath string, specific_file string) (FileHandler, error) {
-	dataPath = filepath.Join(dataPath, specific_file)
+// NewFileHandler accepts either a single contract file or a directory,
+// in which case every .sol and .evm file below it is loaded.
+func NewFileHandler(dataPath string) (FileHandler, error) {
+	info, err := os.Stat(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading input path: %v", err)
+	}
 
 	handler := &SolidityFileHandler{
-		DataPath: dataPath,
+		DataPath:   dataPath,
+		isSpecific: !info.IsDir(),
 	}
 
-	if specific_file != "" {
-		handler.isSpecific = true
-	} else {
-		handler.isSpecific = false
+	logger.Info.Println("NewFileHandler called with path:", dataPath)
+	if err := handler.loadContracts(); err != nil {
+		return nil, err
 	}
 
-	fmt.Println("NewFileHandler called with path:", dataPath)
-	handler.loadContracts()
-
 	return handler, nil
 }
 
-func (s *SolidityFileHandler) loadContracts() {
-	var solCode models.SoliditySourceCode
-	var evmCode models.EVMByteCode
-
+func (s *SolidityFileHandler) loadContracts() error {
 	if !s.isSpecific {
 		// Walk through the directory and read all files
 		err := filepath.Walk(s.DataPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
 			if !info.IsDir() {
-				fileName, fileExtension := filepath.Base(path), filepath.Ext(path)
-
-				if fileExtension == ".sol" {
-					s.contracts = append(s.contracts,
-						models.Contract{
-							ContractName: strings.Split(fileName, ".")[0],
-						},
-					)
+				switch filepath.Ext(path) {
+				case ".sol", ".evm":
+					s.addContract(path)
 				}
 			}
 			return nil
 		})
 		if err != nil {
 			logger.Fatal.Println("Error reading contract files:", err)
-			panic(err)
+			return fmt.Errorf("error reading contract files: %v", err)
 		}
 
 	} else {
 		// Only load the specific file
-		fileName, fileExtension := filepath.Base(s.DataPath), filepath.Ext(s.DataPath)
-		switch fileExtension {
-		case ".sol":
-			solCode = readThenDumpFileContent(s.DataPath)
-		case ".evm":
-			evmCode = readThenDumpFileContent(s.DataPath)
-		default:
-			logger.Warning.Println("Unknown file extension:", fileExtension)
+		s.addContract(s.DataPath)
+	}
+	return nil
+}
+
+// addContract merges the .sol and .evm files of the same contract into one entry
+func (s *SolidityFileHandler) addContract(path string) {
+	fileName, fileExtension := filepath.Base(path), filepath.Ext(path)
+	contractName := strings.Split(fileName, ".")[0]
+
+	var contract *models.Contract
+	for i := range s.contracts {
+		if s.contracts[i].ContractName == contractName {
+			contract = &s.contracts[i]
 		}
+	}
+	if contract == nil {
+		s.contracts = append(s.contracts, models.Contract{ContractName: contractName})
+		contract = &s.contracts[len(s.contracts)-1]
+	}
 
-		s.contracts = append(s.contracts,
-			models.Contract{
-				ContractName: strings.Split(fileName, ".")[0],
-				SolidityCode: models.SolidityCode{SourceCode: solCode},
-				EVMCode:      models.EVMCode{ByteCode: evmCode},
-			},
-		)
+	switch fileExtension {
+	case ".sol":
+		contract.SourcePath = path
+		contract.SolidityCode.SourceCode = readThenDumpFileContent(path)
+	case ".evm":
+		contract.EVMCode.ByteCode = readThenDumpFileContent(path)
+	default:
+		logger.Warning.Println("Unknown file extension:", fileExtension)
 	}
 }
 
@@ -121,14 +128,19 @@ func (s SolidityFileHandler) GetContractData(contractName string) (models.Solidi
 	return "", "", nil // Consider returning an error if the contract is not found
 }
 
+// GetContractSolPathList returns the .sol files to compile. The contracts of a
+// directory that only have an .evm file are skipped.
 func (s SolidityFileHandler) GetContractSolPathList() []string {
 	var contractPathList []string
 	if s.isSpecific {
 		contractPathList = append(contractPathList, s.DataPath)
 	} else {
 		for _, contract := range s.contracts {
-			contractPathList = append(contractPathList,
-				filepath.Join(s.DataPath, contract.ContractName+".sol"))
+			if contract.SourcePath == "" {
+				logger.Info.Println("No .sol file for contract:", contract.ContractName)
+				continue
+			}
+			contractPathList = append(contractPathList, contract.SourcePath)
 		}
 	}
 
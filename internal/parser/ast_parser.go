@@ -1,16 +1,18 @@
 package parser
 
 import (
-	"bufio"
-	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"txtracker/internal/ast"
 	"txtracker/internal/logger"
 )
 
 type ASTParser interface {
-	ParseAST_JSON(astFilePath string) *ast.Common
+	ParseAST_JSON(astFilePath string) (*ast.Common, error)
+	ParseSources(astFilePath string) ([]SourceUnit, error)
 }
 
 type ASTParserImpl struct {
@@ -20,91 +22,91 @@ func NewASTParser() ASTParser {
 	return &ASTParserImpl{}
 }
 
-func (a *ASTParserImpl) ParseAST_JSON(astFilePath string) *ast.Common {
+// ParseAST_JSON reads the output of solc --ast-compact-json and returns the AST of
+// the compiled file, Token.sol for Token.sol.ast.json, when solc printed those of
+// its imports too. A node type the ast package does not know becomes an
+// ast.GenericNode; a file that is not a JSON AST is an error.
+func (a *ASTParserImpl) ParseAST_JSON(astFilePath string) (*ast.Common, error) {
 	logger.Info.Println("ParseAST_JSON called with path:", astFilePath)
-	JsonData := collectJSON(astFilePath)
-
-	var root ast.Common
-	parseAST(JsonData, &root)
-
-	root = *root.Children[0]
-	root.SetParent(&root)
-	return &root
-}
-
-func collectJSON(filePath string) interface{} {
-	// read file, collect lines, and return
-	file, err := os.Open(filePath)
+	units, err := a.ParseSources(astFilePath)
 	if err != nil {
-		logger.Fatal.Println("Error reading file:", err)
-		return nil
+		return nil, err
 	}
-	defer file.Close()
+	target, err := TargetUnit(units, strings.TrimSuffix(astFilePath, ".ast.json"))
+	if err != nil {
+		return nil, err
+	}
+	return target.Root, nil
+}
 
-	// Delete Until meet the first '{'
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		if strings.Contains(scanner.Text(), "{") {
-			break
+// TargetUnit returns the source unit of the compiled file among the units of its
+// compilation. solc prints the paths as given, relative to where it was run, and
+// the standard JSON names the units relative to the base path: the longest unit
+// path the compiled file ends with wins, then a unit of the same base name. A
+// compiled file that is none of the units is an error, rather than taking one of
+// its imports, and so is a base name shared by several units, e.g. two Token.sol.
+func TargetUnit(units []SourceUnit, solFilePath string) (*SourceUnit, error) {
+	var found *SourceUnit
+	for i := range units {
+		if units[i].Path == solFilePath {
+			return &units[i], nil
+		}
+		if strings.HasSuffix(filepath.ToSlash(solFilePath), "/"+units[i].Path) && (found == nil || len(units[i].Path) > len(found.Path)) {
+			found = &units[i]
 		}
 	}
+	if found != nil {
+		return found, nil
+	}
 
-	// Collect the rest of the lines
-	var builder strings.Builder
-	builder.WriteString("{\n") // Add the first '{'
-	for scanner.Scan() {
-		builder.WriteString(scanner.Text())
+	var matches []string
+	for i := range units {
+		if filepath.Base(units[i].Path) == filepath.Base(solFilePath) {
+			found = &units[i]
+			matches = append(matches, units[i].Path)
+		}
 	}
-	if err := scanner.Err(); err != nil {
-		logger.Fatal.Println("Error scanning file:", err)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no source unit of %s among the %d units of its AST", solFilePath, len(units))
+	case 1:
+		return found, nil
 	}
+	return nil, fmt.Errorf("ambiguous source unit of %s among %s", solFilePath, strings.Join(matches, ", "))
+}
 
-	cleanJSON := builder.String()
-	var result interface{}
-
-	// Unmarshal the JSON data
-	err = json.Unmarshal([]byte(cleanJSON), &result)
+// ParseSources reads the output of solc --ast-compact-json and returns a source
+// unit per source file, in the order of the output, all of the same compilation.
+// The path of an AST printed without header is the compiled file.
+func (a *ASTParserImpl) ParseSources(astFilePath string) ([]SourceUnit, error) {
+	file, err := os.Open(astFilePath)
 	if err != nil {
-		logger.Fatal.Println("Error unmarshalling JSON data:", err)
+		return nil, fmt.Errorf("error reading file: %v", err)
 	}
-	return result
-}
-
-func parseAST(jsonNode interface{}, root *ast.Common) {
-
-	switch data := jsonNode.(type) {
-	case map[string]interface{}:
-		// Try create ASTNode from JSON
-		// Common fields set at here
-		dest := ast.NodeFactory(data)
-
-		// Set relationship between parent and child
-		dest.SetParent(root.Instance())
-		root.AddChild(dest.Instance())
-
-		// Special fields set at here
-		dest.ASTNode.Constructor(&data)
-
-		// Recursively parse children from "nodes" or "body"
-		childs, ok := data["nodes"].([]interface{})
+	defer file.Close()
 
-		if !ok {
-			return
+	var units []SourceUnit
+	decoder := NewSourceDecoder(file)
+	for {
+		unit, err := decoder.Next()
+		if err == io.EOF {
+			break
 		}
-		for _, child := range childs {
-			logger.Info.Println("Visiting child:" + child.(map[string]interface{})["nodeType"].(string))
-			parseAST(child, dest.Instance())
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the AST of %s: %v", astFilePath, err)
 		}
-		return
-
-	case []interface{}:
-		for _, value := range data {
-			logger.Info.Println("Visiting array element")
-			parseAST(value, root)
+		if unit.Path == "" {
+			unit.Path = strings.TrimSuffix(astFilePath, ".ast.json")
 		}
-		return
-	default:
-		logger.Fatal.Fatalf("Unknown type: %v", data)
-		panic("Unknown type")
+		units = append(units, *unit)
+	}
+	if len(units) == 0 {
+		return nil, fmt.Errorf("error parsing the AST of %s: no JSON AST found", astFilePath)
+	}
+	compilation := &Compilation{}
+	for i := range units {
+		units[i].Compilation = compilation
+		compilation.Roots = append(compilation.Roots, units[i].Root)
 	}
+	return units, nil
 }
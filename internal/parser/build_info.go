@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"txtracker/internal/ast"
+	"txtracker/internal/logger"
+	"txtracker/internal/srcmap"
+)
+
+// SourceUnit is a source file of a build-info artifact with its parsed AST
+type SourceUnit struct {
+	Path        string // source unit name, e.g. src/Token.sol
+	ID          int
+	SolcVersion string
+	Root        *ast.Common
+	Sources     *srcmap.SourceMap // the source files of the compilation, by file index
+	Compilation *Compilation
+}
+
+// Compilation is a run of solc, the source units compiled together: the targets
+// and the units they import
+type Compilation struct {
+	Roots []*ast.Common
+}
+
+// BuildInfoLoader reads the ASTs of the build-info artifacts written by Foundry
+// (out/build-info/*.json) and Hardhat (artifacts/build-info/*.json), so that a
+// project can be analyzed with the exact compiler settings it was built with and
+// without any solc on the machine.
+type BuildInfoLoader interface {
+	Load(path string) ([]SourceUnit, error)
+}
+
+type BuildInfoLoaderImpl struct {
+}
+
+func NewBuildInfoLoader() BuildInfoLoader {
+	return &BuildInfoLoaderImpl{}
+}
+
+// buildInfo is the part of a build-info file that is read: of the standard JSON
+// input, only the content of the sources
+type buildInfo struct {
+	SolcVersion string `json:"solcVersion"`
+	Input       struct {
+		Sources map[string]struct {
+			Content string `json:"content"`
+		} `json:"sources"`
+	} `json:"input"`
+	Output struct {
+		Sources map[string]struct {
+			ID  int             `json:"id"`
+			AST json.RawMessage `json:"ast"`
+		} `json:"sources"`
+	} `json:"output"`
+}
+
+// Load accepts a build-info file, a build-info directory or the root of a Foundry
+// or Hardhat project. It returns the source units of the project, sorted by name;
+// those of the dependencies, e.g. lib/ or @openzeppelin/, are only part of their
+// compilation. A source unit found in several build-info files is taken from the
+// most recent one.
+func (b *BuildInfoLoaderImpl) Load(path string) ([]SourceUnit, error) {
+	logger.Info.Println("Load build-info called with path:", path)
+
+	files, err := FindBuildInfo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var units []SourceUnit
+	seen := make(map[string]bool)
+	for _, file := range files {
+		fileUnits, err := b.loadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, unit := range fileUnits {
+			if seen[unit.Path] || isDependency(unit.Path) {
+				continue
+			}
+			seen[unit.Path] = true
+			units = append(units, unit)
+		}
+	}
+
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].Path < units[j].Path
+	})
+	return units, nil
+}
+
+func (b *BuildInfoLoaderImpl) loadFile(file string) ([]SourceUnit, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading build-info file: %v", err)
+	}
+
+	var info buildInfo
+	if err := json.Unmarshal(content, &info); err != nil {
+		return nil, fmt.Errorf("error decoding build-info file %s: %v", file, err)
+	}
+	if len(info.Output.Sources) == 0 {
+		return nil, fmt.Errorf("build-info file %s has no output sources", file)
+	}
+
+	sources := srcmap.NewSourceMap()
+	for name, source := range info.Output.Sources {
+		if input, ok := info.Input.Sources[name]; ok {
+			sources.Add(source.ID, name, []byte(input.Content))
+		}
+	}
+
+	var units []SourceUnit
+	compilation := &Compilation{}
+	for name, source := range info.Output.Sources {
+		if len(source.AST) == 0 {
+			return nil, fmt.Errorf("build-info file %s has no AST for %s, was it built with the ast output?", file, name)
+		}
+		root, err := decodeRoot(json.NewDecoder(bytes.NewReader(source.AST)))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the AST of %s in %s: %v", name, file, err)
+		}
+		if root.NodeType != "SourceUnit" {
+			return nil, fmt.Errorf("the AST of %s in %s is not a source unit", name, file)
+		}
+
+		units = append(units, SourceUnit{
+			Path:        name,
+			ID:          source.ID,
+			SolcVersion: info.SolcVersion,
+			Root:        root,
+			Sources:     sources,
+			Compilation: compilation,
+		})
+		compilation.Roots = append(compilation.Roots, root)
+	}
+	return units, nil
+}
+
+// isDependency reports whether a source unit name is that of a library installed
+// in the project, by Foundry in lib/ or by npm, e.g. @openzeppelin/contracts/...
+func isDependency(name string) bool {
+	first, _, _ := strings.Cut(name, "/")
+	return first == "lib" || first == "node_modules" || first == "npm" || strings.HasPrefix(first, "@")
+}
+
+// FindBuildInfo returns the build-info files of a path, the most recent first.
+// The path is either a build-info file, a directory of build-info files or the
+// root of a project holding out/build-info or artifacts/build-info.
+func FindBuildInfo(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading build-info path: %v", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	// the project layouts first, a project root may hold a package.json
+	dirs := []string{filepath.Join(path, "out", "build-info"), filepath.Join(path, "artifacts", "build-info"), path}
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		modTimes := make(map[string]int64)
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil {
+				modTimes[file] = info.ModTime().UnixNano()
+			}
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			return modTimes[files[i]] > modTimes[files[j]]
+		})
+		return files, nil
+	}
+	return nil, fmt.Errorf("no build-info file found in %s, out/build-info or artifacts/build-info", path)
+}
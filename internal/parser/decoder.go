@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"txtracker/internal/ast"
+)
+
+// SourceDecoder reads the source units of the output of solc --ast-compact-json,
+// or of solc --ast-json for the legacy AST of solc < 0.4.12.
+// With imports, solc prints the AST of every source file after a header line:
+//
+//	======= contracts/Token.sol =======
+//	{"absolutePath": "contracts/Token.sol", ...}
+//
+// The JSON is streamed with a json.Decoder: the fields of a node object are read
+// into a map, which the map-based constructors of the ast package turn into the
+// node once the object is closed, and which is then dropped. Neither the file nor
+// a JSON tree of it is held in memory, only the maps of the nodes being read, but
+// every node still costs a map as before. A legacy AST, which has no nodeType, is
+// read whole, then converted.
+type SourceDecoder struct {
+	r    *bufio.Reader
+	path string // of the last header read
+}
+
+func NewSourceDecoder(r io.Reader) *SourceDecoder {
+	return &SourceDecoder{
+		r: bufio.NewReader(r),
+	}
+}
+
+// sourceHeader is the line solc prints before the AST of a source file
+var sourceHeader = regexp.MustCompile(`^======= (.+) =======$`)
+
+// Next returns the next source unit, with the path of its header, empty for an
+// AST printed without header. The other lines, e.g. "JSON AST (compact format):",
+// are skipped. It returns io.EOF after the last source unit.
+func (d *SourceDecoder) Next() (*SourceUnit, error) {
+	for {
+		c, err := d._skipSpaces()
+		if err != nil {
+			return nil, err
+		}
+		if c == '{' {
+			break
+		}
+		line, err := d.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if match := sourceHeader.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			d.path = match[1]
+		}
+	}
+
+	dec := json.NewDecoder(d.r)
+	root, err := decodeRoot(dec)
+	// the decoder reads ahead, the next source unit starts in its buffer
+	d.r = bufio.NewReader(io.MultiReader(dec.Buffered(), d.r))
+	if err != nil {
+		return nil, err
+	}
+
+	unit := &SourceUnit{
+		Path: d.path,
+		Root: root,
+	}
+	d.path = ""
+	// the source index is the last field of the location, e.g. 0:1024:3
+	if i := strings.LastIndex(root.Src, ":"); i >= 0 {
+		unit.ID, _ = strconv.Atoi(root.Src[i+1:])
+	}
+	return unit, nil
+}
+
+// _skipSpaces skips the white spaces and returns the next byte without reading it
+func (d *SourceDecoder) _skipSpaces() (byte, error) {
+	for {
+		c, err := d.r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsAny(c, " \t\r\n") {
+			return c[0], nil
+		}
+		d.r.Discard(1)
+	}
+}
+
+// decodeRoot builds the tree of a source unit from the JSON AST read by dec. The
+// constructors of the nodes panic on an object that is not a node, or on a field
+// of an unexpected type, the panic is returned as an error.
+func decodeRoot(dec *json.Decoder) (_ *ast.Common, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed AST: %v", r)
+		}
+	}()
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	value, err := decodeObject(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := value.(*ast.Common)
+	if !ok {
+		data := value.(map[string]interface{})
+		if !isLegacyNode(data) {
+			return nil, fmt.Errorf("the AST has no nodeType")
+		}
+		if root, err = newLegacyNode(data); err != nil {
+			return nil, err
+		}
+	}
+	root.SetParent(root)
+	return root, nil
+}
+
+// decodeValue reads a JSON value, building the nodes of its objects
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	switch token {
+	case json.Delim('{'):
+		return decodeObject(dec)
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, decodeError(err)
+		}
+		return list, nil
+	}
+	return token, nil
+}
+
+// decodeObject reads the fields of the object whose opening brace was read into a
+// map. An object with a nodeType is built into its node as soon as it is read, so
+// that the map of a node holds its children already built, not their JSON. Another
+// object, e.g. typeDescriptions or a node of the legacy AST, is returned as is.
+func decodeObject(dec *json.Decoder) (interface{}, error) {
+	data := make(map[string]interface{})
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, decodeError(err)
+		}
+		value, err := decodeValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		data[token.(string)] = value
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, decodeError(err)
+	}
+
+	if _, ok := data["nodeType"].(string); !ok {
+		return data, nil
+	}
+	return newNode(data)
+}
+
+// newNode builds a node from the fields of its JSON object, the nodes of its
+// "nodes" array are its children. The nodes of a converted legacy AST are still
+// JSON objects, they are built here.
+func newNode(data map[string]interface{}) (*ast.Common, error) {
+	nodes, _ := data["nodes"].([]interface{})
+	delete(data, "nodes")
+
+	node := ast.NewNode(data)
+	for _, value := range nodes {
+		var child *ast.Common
+		switch value := value.(type) {
+		case *ast.Common:
+			child = value
+		case map[string]interface{}:
+			var err error
+			if child, err = newNode(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %v where a node is expected", value)
+		}
+		child.SetParent(node)
+		node.AddChild(child)
+	}
+	return node, nil
+}
+
+// expectDelim reads the opening delimiter of an object or array
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return decodeError(err)
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected %v where a node is expected", token)
+	}
+	return nil
+}
+
+func decodeError(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("error decoding the JSON AST: %v", err)
+}
@@ -0,0 +1,527 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"txtracker/internal/ast"
+)
+
+// The legacy JSON AST, the only one of solc < 0.4.12 (solc --ast-json), names
+// the node type "name", holds the plain fields in "attributes" and the nodes in
+// "children", in the order of the source and without the absent ones:
+//
+//	{"id": 5, "name": "IfStatement", "src": "...", "attributes": {...}, "children": [...]}
+//
+// newLegacyNode converts it into the compact JSON AST, so that the ast package builds
+// the same nodes. The types are only given as strings, their identifiers are
+// derived from them; the identifiers of the oldest versions, which have no
+// referencedDeclaration, are resolved by name.
+
+// isLegacyNode reports whether a JSON object is a node of the legacy JSON AST
+func isLegacyNode(data map[string]interface{}) bool {
+	_, compact := data["nodeType"]
+	_, name := data["name"].(string)
+	_, attributes := data["attributes"]
+	_, children := data["children"]
+	return !compact && name && (attributes || children)
+}
+
+// newLegacyNode builds a node and its subtree from the legacy JSON AST
+func newLegacyNode(data map[string]interface{}) (*ast.Common, error) {
+	c := &legacyConverter{
+		contracts: make(map[float64]map[string]interface{}),
+		members:   make(map[float64]map[string]float64),
+		events:    make(map[float64]bool),
+	}
+	c._index(data)
+	return newNode(c._convert(data, ""))
+}
+
+type legacyConverter struct {
+	contracts map[float64]map[string]interface{} // ContractDefinition by ID
+	members   map[float64]map[string]float64     // declarations of a contract by name
+	events    map[float64]bool                   // IDs of the EventDefinition nodes
+	scopes    []map[string]float64               // declarations visible by name, innermost last
+	contract  string                             // name of the contract being converted
+}
+
+// _index records the contracts, their members and the events of the source unit
+func (c *legacyConverter) _index(node map[string]interface{}) {
+	id, _ := node["id"].(float64)
+	switch node["name"] {
+	case "ContractDefinition":
+		c.contracts[id] = node
+		c.members[id] = make(map[string]float64)
+		for _, child := range legacyChildren(node) {
+			if name, ok := legacyAttributes(child)["name"].(string); ok && name != "" {
+				c.members[id][name], _ = child["id"].(float64)
+			}
+		}
+	case "EventDefinition":
+		c.events[id] = true
+	}
+	for _, child := range legacyChildren(node) {
+		c._index(child)
+	}
+}
+
+func legacyChildren(node map[string]interface{}) []map[string]interface{} {
+	var res []map[string]interface{}
+	children, _ := node["children"].([]interface{})
+	for _, child := range children {
+		if child, ok := child.(map[string]interface{}); ok {
+			res = append(res, child)
+		}
+	}
+	return res
+}
+
+func legacyAttributes(node map[string]interface{}) map[string]interface{} {
+	attributes, _ := node["attributes"].(map[string]interface{})
+	return attributes
+}
+
+// _convert returns the compact node of a legacy node, parent is the node type
+// of its parent
+func (c *legacyConverter) _convert(node map[string]interface{}, parent string) map[string]interface{} {
+	nodeType, _ := node["name"].(string)
+	res := map[string]interface{}{
+		"nodeType": nodeType,
+		"id":       node["id"],
+		"src":      node["src"],
+	}
+	for key, value := range legacyAttributes(node) {
+		// an empty list of nodes is written [null]
+		if list, ok := value.([]interface{}); ok && len(list) > 0 && list[0] == nil {
+			continue
+		}
+		res[key] = value
+	}
+	if typeString, ok := res["type"].(string); ok {
+		res["typeDescriptions"] = map[string]interface{}{
+			"typeIdentifier": legacyTypeIdentifier(typeString),
+			"typeString":     typeString,
+		}
+		delete(res, "type")
+	}
+
+	// the scopes of the names declared by the node
+	switch nodeType {
+	case "ContractDefinition":
+		name, _ := res["name"].(string)
+		outer := c.contract
+		c.contract = name
+		c.scopes = append(c.scopes, c._contractScope(node))
+		defer func() {
+			c.contract = outer
+			c.scopes = c.scopes[:len(c.scopes)-1]
+		}()
+	case "FunctionDefinition", "ModifierDefinition":
+		scope := make(map[string]float64)
+		legacyDeclarations(node, scope)
+		c.scopes = append(c.scopes, scope)
+		defer func() { c.scopes = c.scopes[:len(c.scopes)-1] }()
+	}
+
+	var children []interface{}
+	for _, child := range legacyChildren(node) {
+		children = append(children, c._convert(child, nodeType))
+	}
+	c._fields(res, nodeType, parent, children)
+	return res
+}
+
+// _contractScope returns the members of a contract and of its bases in the source
+// unit, the contracts of the source unit included
+func (c *legacyConverter) _contractScope(node map[string]interface{}) map[string]float64 {
+	scope := make(map[string]float64)
+	for id, contract := range c.contracts {
+		if name, ok := legacyAttributes(contract)["name"].(string); ok {
+			scope[name] = id
+		}
+	}
+	bases, _ := legacyAttributes(node)["linearizedBaseContracts"].([]interface{})
+	if len(bases) == 0 {
+		bases = []interface{}{node["id"]}
+	}
+	// from the most base contract, the members of the derived ones override them
+	for i := len(bases) - 1; i >= 0; i-- {
+		id, _ := bases[i].(float64)
+		for name, member := range c.members[id] {
+			scope[name] = member
+		}
+	}
+	return scope
+}
+
+// legacyDeclarations records the variables declared in a function or a modifier,
+// the parameters included
+func legacyDeclarations(node map[string]interface{}, scope map[string]float64) {
+	for _, child := range legacyChildren(node) {
+		if child["name"] == "VariableDeclaration" {
+			if name, ok := legacyAttributes(child)["name"].(string); ok && name != "" {
+				scope[name], _ = child["id"].(float64)
+			}
+		}
+		legacyDeclarations(child, scope)
+	}
+}
+
+// _lookup returns the ID of the declaration of a name, 0 if it is not declared
+// in the source unit, e.g. msg
+func (c *legacyConverter) _lookup(name string) (float64, bool) {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if id, ok := c.scopes[i][name]; ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// _fields sets the fields of a compact node that the legacy AST holds in its
+// children or names differently
+func (c *legacyConverter) _fields(res map[string]interface{}, nodeType, parent string, children []interface{}) {
+	child := func(i int) interface{} {
+		if i < len(children) {
+			return children[i]
+		}
+		return nil
+	}
+	rest := func(i int) []interface{} {
+		if i < len(children) {
+			return children[i:]
+		}
+		return []interface{}{}
+	}
+	childType := func(i int) string {
+		if node, ok := child(i).(map[string]interface{}); ok {
+			return node["nodeType"].(string)
+		}
+		return ""
+	}
+
+	switch nodeType {
+	case "SourceUnit":
+		res["nodes"] = children
+	case "ContractDefinition":
+		i := 0
+		for childType(i) == "InheritanceSpecifier" {
+			i++
+		}
+		res["baseContracts"] = children[:i]
+		res["nodes"] = rest(i)
+		if _, ok := res["contractKind"]; !ok {
+			res["contractKind"] = "contract"
+			if isLibrary, _ := res["isLibrary"].(bool); isLibrary {
+				res["contractKind"] = "library"
+			}
+		}
+	case "InheritanceSpecifier":
+		res["baseName"] = child(0)
+		res["arguments"] = rest(1)
+	case "UsingForDirective":
+		res["libraryName"] = child(0)
+		res["typeName"] = child(1)
+	case "StructDefinition", "EnumDefinition":
+		res["members"] = children
+	case "ParameterList":
+		res["parameters"] = children
+	case "FunctionDefinition":
+		res["parameters"] = child(0)
+		res["returnParameters"] = child(1)
+		var modifiers []interface{}
+		for i := 2; i < len(children); i++ {
+			switch childType(i) {
+			case "ModifierInvocation":
+				modifiers = append(modifiers, children[i])
+			case "Block":
+				res["body"] = children[i]
+			}
+		}
+		res["modifiers"] = modifiers
+		if _, ok := res["implemented"]; !ok {
+			res["implemented"] = res["body"] != nil
+		}
+		if _, ok := res["isConstructor"]; !ok {
+			res["isConstructor"] = res["name"] == c.contract && c.contract != ""
+		}
+		if _, ok := res["stateMutability"]; !ok {
+			res["stateMutability"] = "nonpayable"
+			if constant, _ := res["constant"].(bool); constant {
+				res["stateMutability"] = "view"
+			}
+			if payable, _ := res["payable"].(bool); payable {
+				res["stateMutability"] = "payable"
+			}
+		}
+		if _, ok := res["visibility"]; !ok {
+			res["visibility"] = "public"
+		}
+	case "ModifierDefinition":
+		res["parameters"] = child(0)
+		res["body"] = child(1)
+	case "ModifierInvocation":
+		res["modifierName"] = child(0)
+		res["arguments"] = rest(1)
+	case "EventDefinition":
+		res["parameters"] = child(0)
+	case "VariableDeclaration":
+		i := 0
+		switch childType(0) {
+		case "ElementaryTypeName", "UserDefinedTypeName", "Mapping", "ArrayTypeName", "FunctionTypeName":
+			res["typeName"] = child(0)
+			i = 1
+		}
+		res["value"] = child(i)
+		if _, ok := res["stateVariable"]; !ok {
+			res["stateVariable"] = parent == "ContractDefinition"
+		}
+		if _, ok := res["visibility"]; !ok {
+			res["visibility"] = "internal"
+		}
+		if _, ok := res["storageLocation"]; !ok {
+			res["storageLocation"] = "default"
+		}
+	case "Mapping":
+		res["keyType"] = child(0)
+		res["valueType"] = child(1)
+	case "ArrayTypeName":
+		res["baseType"] = child(0)
+		res["length"] = child(1)
+	case "UserDefinedTypeName":
+		name, _ := res["name"].(string)
+		if _, ok := res["referencedDeclaration"]; !ok {
+			if id, ok := c._lookup(name); ok {
+				res["referencedDeclaration"] = id
+			}
+		}
+	case "Block":
+		res["statements"] = children
+	case "IfStatement":
+		res["condition"] = child(0)
+		res["trueBody"] = child(1)
+		res["falseBody"] = child(2)
+	case "WhileStatement", "DoWhileStatement":
+		res["condition"] = child(0)
+		res["body"] = child(1)
+	case "ForStatement":
+		// the initialization and the loop expression are statements, the
+		// condition is an expression, the body is last
+		if len(children) == 0 {
+			break
+		}
+		res["body"] = children[len(children)-1]
+		var statements []int
+		condition := -1
+		for i := 0; i < len(children)-1; i++ {
+			if strings.HasSuffix(childType(i), "Statement") {
+				statements = append(statements, i)
+			} else {
+				res["condition"] = children[i]
+				condition = i
+			}
+		}
+		switch len(statements) {
+		case 2:
+			res["initializationExpression"] = children[statements[0]]
+			res["loopExpression"] = children[statements[1]]
+		case 1:
+			i := statements[0]
+			res[legacyForClause(res, childType(i), i > condition, children[i], res["body"])] = children[i]
+		}
+	case "ExpressionStatement", "Return":
+		res["expression"] = child(0)
+	case "EmitStatement":
+		res["eventCall"] = child(0)
+	case "VariableDeclarationStatement":
+		i := 0
+		for childType(i) == "VariableDeclaration" {
+			i++
+		}
+		res["declarations"] = children[:i]
+		res["initialValue"] = child(i)
+	case "Assignment":
+		res["leftHandSide"] = child(0)
+		res["rightHandSide"] = child(1)
+	case "BinaryOperation":
+		res["leftExpression"] = child(0)
+		res["rightExpression"] = child(1)
+	case "UnaryOperation":
+		res["subExpression"] = child(0)
+	case "Conditional":
+		res["condition"] = child(0)
+		res["trueExpression"] = child(1)
+		res["falseExpression"] = child(2)
+	case "FunctionCall":
+		res["expression"] = child(0)
+		res["arguments"] = rest(1)
+		switch {
+		case res["type_conversion"] == true:
+			res["kind"] = "typeConversion"
+		case res["isStructConstructorCall"] == true:
+			res["kind"] = "structConstructorCall"
+		default:
+			res["kind"] = "functionCall"
+		}
+	case "NewExpression":
+		res["typeName"] = child(0)
+	case "MemberAccess":
+		res["expression"] = child(0)
+		rename(res, "member_name", "memberName")
+		// the members of an address and .value() or .gas() are no internal
+		// functions, whatever their type string
+		if types, ok := res["typeDescriptions"].(map[string]interface{}); ok {
+			name, _ := res["memberName"].(string)
+			base := legacyTypeIdentifierOf(child(0))
+			if id, ok := legacyAddressMembers[name]; ok && strings.HasPrefix(base, "t_address") {
+				types["typeIdentifier"] = id
+			} else if id, ok := legacyFunctionMembers[name]; ok && strings.HasPrefix(base, "t_function") {
+				types["typeIdentifier"] = id
+			}
+		}
+	case "IndexAccess":
+		res["baseExpression"] = child(0)
+		res["indexExpression"] = child(1)
+	case "TupleExpression":
+		res["components"] = children
+	case "Identifier":
+		rename(res, "value", "name")
+		name, _ := res["name"].(string)
+		if _, ok := res["referencedDeclaration"]; !ok {
+			if id, ok := c._lookup(name); ok {
+				res["referencedDeclaration"] = id
+			}
+		}
+		// an event is called like a function before solc 0.4.21
+		if id, ok := res["referencedDeclaration"].(float64); ok && c.events[id] {
+			if types, ok := res["typeDescriptions"].(map[string]interface{}); ok {
+				types["typeIdentifier"] = "t_function_event_nonpayable"
+			}
+		}
+	case "ElementaryTypeNameExpression":
+		res["typeName"] = res["value"]
+	case "Literal":
+		rename(res, "hexvalue", "hexValue")
+		rename(res, "token", "kind")
+		switch res["kind"] {
+		case "true", "false":
+			res["kind"] = "bool"
+		case nil, "":
+			res["kind"] = "number"
+			if types, ok := res["typeDescriptions"].(map[string]interface{}); ok {
+				if strings.HasPrefix(types["typeString"].(string), "literal_string") {
+					res["kind"] = "string"
+				}
+			}
+		}
+	}
+}
+
+// legacyForClause returns the clause of the lone statement of a for loop, either
+// initializationExpression or loopExpression. solc >= 0.4.12 writes the absent
+// clause as a null attribute; before, the initialization is told apart by its
+// kind, by preceding the condition, or, in for (;; i++), by its position: it is
+// followed by ";;)", the loop expression is preceded by "(;;".
+func legacyForClause(res map[string]interface{}, statementType string, afterCondition bool, statement, body interface{}) string {
+	if _, ok := res["initializationExpression"]; ok {
+		return "loopExpression"
+	}
+	if _, ok := res["loopExpression"]; ok {
+		return "initializationExpression"
+	}
+	if statementType == "VariableDeclarationStatement" {
+		return "initializationExpression"
+	}
+	if _, ok := res["condition"]; ok {
+		if afterCondition {
+			return "loopExpression"
+		}
+		return "initializationExpression"
+	}
+
+	forStart, _ := legacySrc(res)
+	start, length := legacySrc(statement)
+	bodyStart, _ := legacySrc(body)
+	// for (i = 0;;) { and for (;; i++) {, the difference is 1 and 6
+	if (start-forStart)-(bodyStart-start-length) >= 3 {
+		return "loopExpression"
+	}
+	return "initializationExpression"
+}
+
+// legacySrc returns the start and the length of the location of a node
+func legacySrc(node interface{}) (int, int) {
+	data, _ := node.(map[string]interface{})
+	src, _ := data["src"].(string)
+	parts := strings.Split(src, ":")
+	if len(parts) < 2 {
+		return 0, 0
+	}
+	start, _ := strconv.Atoi(parts[0])
+	length, _ := strconv.Atoi(parts[1])
+	return start, length
+}
+
+// legacyTypeIdentifierOf returns the type identifier of a converted node
+func legacyTypeIdentifierOf(node interface{}) string {
+	data, _ := node.(map[string]interface{})
+	types, _ := data["typeDescriptions"].(map[string]interface{})
+	id, _ := types["typeIdentifier"].(string)
+	return id
+}
+
+func rename(res map[string]interface{}, from, to string) {
+	if value, ok := res[from]; ok {
+		res[to] = value
+		delete(res, from)
+	}
+}
+
+// legacyTypeIdentifier derives the type identifier of a compact AST from a type
+// string, e.g. t_uint256 from uint256. Only its prefix, the kind of type, is
+// reliable.
+func legacyTypeIdentifier(typeString string) string {
+	for _, t := range legacyTypes {
+		if t.pattern.MatchString(typeString) {
+			return t.pattern.ReplaceAllString(typeString, t.identifier)
+		}
+	}
+	return ""
+}
+
+// legacyAddressMembers are the type identifiers of the members of an address
+// making a message call
+var legacyAddressMembers = map[string]string{
+	"call":         "t_function_barecall_payable",
+	"callcode":     "t_function_barecallcode_payable",
+	"delegatecall": "t_function_baredelegatecall_nonpayable",
+	"send":         "t_function_send_nonpayable",
+	"transfer":     "t_function_transfer_nonpayable",
+}
+
+// legacyFunctionMembers are the type identifiers of the members of a function
+// setting the options of its call, e.g. msg.sender.call.value(amount)
+var legacyFunctionMembers = map[string]string{
+	"value": "t_function_setvalue_nonpayable",
+	"gas":   "t_function_setgas_nonpayable",
+}
+
+var legacyTypes = []struct {
+	pattern    *regexp.Regexp
+	identifier string
+}{
+	{regexp.MustCompile(`^mapping\(.*$`), "t_mapping"},
+	{regexp.MustCompile(`^.*\](?: storage ref| storage pointer| memory| calldata)?$`), "t_array"},
+	{regexp.MustCompile(`^type\(contract (\w+)\)$`), "t_type$$_t_contract$$_${1}"},
+	{regexp.MustCompile(`^contract (\w+)$`), "t_contract$$_${1}"},
+	{regexp.MustCompile(`^struct (\w+)\.(\w+).*$`), "t_struct$$_${2}"},
+	{regexp.MustCompile(`^enum (\w+)\.(\w+)$`), "t_enum$$_${2}"},
+	{regexp.MustCompile(`^int_const (-?)(\d+)$`), "t_rational_${1}${2}_by_1"},
+	{regexp.MustCompile(`^(address|bool|u?int\d*|bytes\d*|string)(?: storage ref| storage pointer| memory| calldata)?$`), "t_${1}"},
+	// function (address,uint256) external returns (bool), the parameters hold no
+	// function type
+	{regexp.MustCompile(`^function \([^()]*\)(?: \w+)* external(?: .*)?$`), "t_function_external"},
+	{regexp.MustCompile(`^function .*$`), "t_function_internal"},
+	{regexp.MustCompile(`^(msg|block|tx)$`), "t_magic_${1}"},
+}
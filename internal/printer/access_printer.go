@@ -0,0 +1,150 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"txtracker/internal/detectors"
+)
+
+type AccessPrinter struct {
+	Matrices []*detectors.PermissionMatrix
+	Out      io.Writer
+}
+
+func NewAccessPrinter(matrices []*detectors.PermissionMatrix, out io.Writer) *AccessPrinter {
+	return &AccessPrinter{
+		Matrices: matrices,
+		Out:      out,
+	}
+}
+
+// Print writes the permission matrix of each contract: one row per entry point,
+// one column per state variable, and the guards of the writes in the cells.
+// An unguarded write is shown as "none", a variable not written as ".".
+//
+//	Bank
+//	entry point          balances   owner
+//	Bank::deposit        none       .
+//	Bank::setOwner       .          onlyOwner
+//
+//	guards:
+//	  onlyOwner: msg.sender == owner
+//	unguarded state-changing functions: Bank::deposit
+func (p *AccessPrinter) Print() {
+	for _, m := range p.Matrices {
+		fmt.Fprintln(p.Out, m.Contract)
+
+		w := tabwriter.NewWriter(p.Out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, strings.Join(append([]string{"entry point"}, m.Variables...), "\t"))
+		for _, perm := range m.Permissions {
+			row := []string{perm.Function.Name}
+			for _, v := range m.Variables {
+				row = append(row, cell(perm, v))
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+
+		if guards := guardConditions(m); len(guards) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "guards:")
+			for _, g := range guards {
+				fmt.Fprintf(p.Out, "  %s\n", g)
+			}
+		}
+		if unguarded := unguardedFunctions(m); len(unguarded) > 0 {
+			fmt.Fprintf(p.Out, "unguarded state-changing functions: %s\n", strings.Join(unguarded, ", "))
+		}
+		fmt.Fprintln(p.Out)
+	}
+}
+
+func cell(perm *detectors.Permission, variable string) string {
+	guards, ok := perm.Guards(variable)
+	if !ok {
+		return "."
+	}
+	for i, g := range guards {
+		if g == "" {
+			guards[i] = "none"
+		}
+	}
+	return strings.Join(guards, "|")
+}
+
+// guardConditions lists the condition checked by each modifier guard, e.g. "onlyOwner: msg.sender == owner"
+func guardConditions(m *detectors.PermissionMatrix) []string {
+	var res []string
+	seen := make(map[string]bool)
+	for _, perm := range m.Permissions {
+		for _, w := range perm.Writes {
+			for _, g := range w.Guards {
+				if g.Modifier == "" {
+					continue
+				}
+				line := g.Modifier + ": " + g.Condition
+				if !seen[line] {
+					seen[line] = true
+					res = append(res, line)
+				}
+			}
+		}
+	}
+	return res
+}
+
+func unguardedFunctions(m *detectors.PermissionMatrix) []string {
+	var res []string
+	for _, perm := range m.Permissions {
+		if len(perm.Unguarded()) > 0 {
+			res = append(res, perm.Function.Name)
+		}
+	}
+	return res
+}
+
+type entryPointAccessJSON struct {
+	Name      string              `json:"name"`
+	Writes    map[string][]string `json:"writes"` // guards per written variable, "" for an unguarded write
+	Unguarded bool                `json:"unguarded"`
+}
+
+type contractAccessJSON struct {
+	Contract    string                 `json:"contract"`
+	Variables   []string               `json:"variables"`
+	EntryPoints []entryPointAccessJSON `json:"entryPoints"`
+	Guards      []string               `json:"guards"`
+}
+
+// PrintJSON writes the permission matrices as a JSON array
+func (p *AccessPrinter) PrintJSON() error {
+	contracts := []contractAccessJSON{}
+	for _, m := range p.Matrices {
+		c := contractAccessJSON{
+			Contract:    m.Contract,
+			Variables:   append([]string{}, m.Variables...),
+			EntryPoints: []entryPointAccessJSON{},
+			Guards:      append([]string{}, guardConditions(m)...),
+		}
+		for _, perm := range m.Permissions {
+			e := entryPointAccessJSON{
+				Name:      perm.Function.Name,
+				Writes:    make(map[string][]string),
+				Unguarded: len(perm.Unguarded()) > 0,
+			}
+			for _, v := range m.Variables {
+				if guards, ok := perm.Guards(v); ok {
+					e.Writes[v] = guards
+				}
+			}
+			c.EntryPoints = append(c.EntryPoints, e)
+		}
+		contracts = append(contracts, c)
+	}
+	encoder := json.NewEncoder(p.Out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(contracts)
+}
@@ -2,6 +2,7 @@ package printer
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"txtracker/internal/ast"
 )
@@ -12,21 +13,22 @@ type Printer struct {
 
 type ASTPrinter struct {
 	Root *ast.Common
+	Out  io.Writer
 }
 
-func NewASTPrinter(root *ast.Common) *ASTPrinter {
-	return &ASTPrinter{Root: root}
+func NewASTPrinter(root *ast.Common, out io.Writer) *ASTPrinter {
+	return &ASTPrinter{Root: root, Out: out}
 }
 
 func (a *ASTPrinter) PrintAST() {
-	fmt.Print("Printing AST\n")
+	fmt.Fprint(a.Out, "Printing AST\n")
 
-	printerHelper(a.Root, 0, "")
+	printerHelper(a.Out, a.Root, 0, "")
 }
 
 // printerHelper is a recursive helper function that prints the tree structure.
 // It takes a node, the current depth, and the prefix string for indentation.
-func printerHelper(node *ast.Common, depth int, prefix string) {
+func printerHelper(out io.Writer, node *ast.Common, depth int, prefix string) {
 	if node == nil {
 		return
 	}
@@ -34,14 +36,14 @@ func printerHelper(node *ast.Common, depth int, prefix string) {
 	// Calculate indentation based on depth
 	indent := strings.Repeat(" ", depth*4) // 4 spaces per depth level
 	if depth > 0 {
-		fmt.Println(prefix + "-> " + node.NodeType)
+		fmt.Fprintln(out, prefix+"-> "+node.NodeType)
 	} else {
-		fmt.Println(node.NodeType)
+		fmt.Fprintln(out, node.NodeType)
 	}
 
 	// If the node has children, recursively call this function for each child
 	newPrefix := indent + strings.Repeat(" ", len(node.NodeType)+3)
 	for _, child := range node.Children {
-		printerHelper(child, depth+1, newPrefix)
+		printerHelper(out, child, depth+1, newPrefix)
 	}
 }
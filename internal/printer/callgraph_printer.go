@@ -0,0 +1,87 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"txtracker/internal/callgraph"
+)
+
+type CallGraphPrinter struct {
+	CallGraph *callgraph.CallGraph
+	Out       io.Writer
+}
+
+func NewCallGraphPrinter(cg *callgraph.CallGraph, out io.Writer) *CallGraphPrinter {
+	return &CallGraphPrinter{
+		CallGraph: cg,
+		Out:       out,
+	}
+}
+
+// Print writes every function followed by its call sites
+func (p *CallGraphPrinter) Print() {
+	for _, node := range p.CallGraph.Nodes {
+		if len(node.Callees) == 0 {
+			continue
+		}
+		fmt.Fprintln(p.Out, node.String())
+		for _, edge := range node.Callees {
+			fmt.Fprintf(p.Out, " |%-10s %s\n", edge.Kind.String(), edge.CalleeName())
+		}
+		fmt.Fprintln(p.Out)
+	}
+}
+
+// edge styles of the DOT output, indexed by CallKind
+var dotEdgeStyles = map[callgraph.CallKind]string{
+	callgraph.Internal: `color="black"`,
+	callgraph.Library:  `color="blue", style="dotted"`,
+	callgraph.Super:    `color="darkgreen"`,
+	callgraph.External: `color="red", style="dashed"`,
+	callgraph.LowLevel: `color="red", style="bold"`,
+	callgraph.Modifier: `color="gray40", style="dashed", arrowhead="diamond"`,
+}
+
+// PrintDOT writes the call graph in Graphviz DOT, one cluster per contract.
+// Unresolved callees (low-level calls, getters) are drawn as plain text nodes.
+func (p *CallGraphPrinter) PrintDOT() {
+	fmt.Fprintln(p.Out, "digraph callgraph {")
+	fmt.Fprintln(p.Out, "  rankdir=LR;")
+	fmt.Fprintln(p.Out, "  node [shape=box, fontname=\"Helvetica\"];")
+
+	var contracts []string
+	byContract := make(map[string][]*callgraph.Node)
+	for _, node := range p.CallGraph.Nodes {
+		if _, ok := byContract[node.Contract]; !ok {
+			contracts = append(contracts, node.Contract)
+		}
+		byContract[node.Contract] = append(byContract[node.Contract], node)
+	}
+
+	for i, contract := range contracts {
+		fmt.Fprintf(p.Out, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(p.Out, "    label=%s;\n", strconv.Quote(contract))
+		for _, node := range byContract[contract] {
+			shape := ""
+			if node.Definition.NodeType == "ModifierDefinition" {
+				shape = ", shape=hexagon"
+			}
+			fmt.Fprintf(p.Out, "    n%d [label=%s%s];\n", node.ID, strconv.Quote(node.Name), shape)
+		}
+		fmt.Fprintln(p.Out, "  }")
+	}
+
+	for i, edge := range p.CallGraph.Edges {
+		var dest string
+		if edge.Callee != nil {
+			dest = fmt.Sprintf("n%d", edge.Callee.ID)
+		} else {
+			dest = fmt.Sprintf("u%d", i)
+			fmt.Fprintf(p.Out, "  %s [label=%s, shape=plaintext];\n", dest, strconv.Quote(edge.Name))
+		}
+		fmt.Fprintf(p.Out, "  n%d -> %s [label=%s, %s];\n",
+			edge.Caller.ID, dest, strconv.Quote(edge.Kind.String()), dotEdgeStyles[edge.Kind])
+	}
+	fmt.Fprintln(p.Out, "}")
+}
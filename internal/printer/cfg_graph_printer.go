@@ -0,0 +1,292 @@
+package printer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	AST "txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+)
+
+// CFGGraph is a diagram of entry points: those of a contract, or a single one
+type CFGGraph struct {
+	Name      string // the contract, or the entry point, e.g. Bank::withdraw
+	Signature string // the signature of the single entry point, e.g. withdraw(uint256)
+	Functions []*CFG.Function
+}
+
+// FileName returns a file name for the graph with the given extension, with the
+// parameter types of its entry point so that overloads do not share a file,
+// e.g. Bank.withdraw-uint256.dot
+func (g CFGGraph) FileName(ext string) string {
+	name := strings.ReplaceAll(g.Name, "::", ".")
+	if open := strings.Index(g.Signature, "("); open >= 0 {
+		if params := strings.TrimSuffix(g.Signature[open+1:], ")"); params != "" {
+			name += "-" + strings.ReplaceAll(params, ",", "-")
+		}
+	}
+	return unsafeFileChars.ReplaceAllString(name, "_") + "." + ext
+}
+
+// UnitDir returns the directory of the files written for a source unit: its
+// path without the extension, relative and cleaned, e.g. src/Token for
+// src/Token.sol, so that the contracts of two units do not share a file
+func UnitDir(path string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	var parts []string
+	for _, part := range strings.Split(strings.TrimSuffix(path, filepath.Ext(path)), "/") {
+		if part == "" || part == "." || part == ".." {
+			continue
+		}
+		parts = append(parts, unsafeFileChars.ReplaceAllString(part, "_"))
+	}
+	return filepath.Join(parts...)
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// CFGGraphsByContract returns a graph per contract holding its entry points
+func CFGGraphsByContract(cfg *CFG.CFG) []CFGGraph {
+	var res []CFGGraph
+	index := make(map[string]int)
+	for _, f := range cfg.EntryPoints {
+		contract := strings.Split(f.Name, "::")[0]
+		i, ok := index[contract]
+		if !ok {
+			i = len(res)
+			index[contract] = i
+			res = append(res, CFGGraph{Name: contract})
+		}
+		res[i].Functions = append(res[i].Functions, f)
+	}
+	return res
+}
+
+// CFGGraphsByFunction returns a graph per entry point
+func CFGGraphsByFunction(cfg *CFG.CFG) []CFGGraph {
+	var res []CFGGraph
+	for _, f := range cfg.EntryPoints {
+		res = append(res, CFGGraph{Name: f.Name, Signature: f.Signature, Functions: []*CFG.Function{f}})
+	}
+	return res
+}
+
+// graphCluster is a group of blocks of a function inlined from a modifier
+type graphCluster struct {
+	label  string
+	blocks []*CFG.Block
+}
+
+// _clusters returns the blocks of the function body, then a cluster per modifier
+// in the order of the invocations
+func (p *CFGPrinter) _clusters(f *CFG.Function) ([]*CFG.Block, []graphCluster) {
+	var body []*CFG.Block
+	var clusters []graphCluster
+	index := make(map[string]int)
+	for _, b := range f.Blocks {
+		if b.Modifier == "" {
+			body = append(body, b)
+			continue
+		}
+		i, ok := index[b.Modifier]
+		if !ok {
+			i = len(clusters)
+			index[b.Modifier] = i
+			clusters = append(clusters, graphCluster{label: "modifier " + b.Modifier})
+		}
+		clusters[i].blocks = append(clusters[i].blocks, b)
+	}
+	return body, clusters
+}
+
+// internalCall is an edge from a block to an internal function it calls
+type internalCall struct {
+	block  *CFG.Block
+	callee int // index in the callees of the function
+}
+
+// _internalCalls returns the internal functions called by the blocks of a
+// function, each once, and the calls
+func (p *CFGPrinter) _internalCalls(f *CFG.Function) ([]*AST.FunctionDefinition, []internalCall) {
+	var callees []*AST.FunctionDefinition
+	var calls []internalCall
+	index := make(map[*AST.FunctionDefinition]int)
+	for _, b := range f.Blocks {
+		called := make(map[int]bool)
+		for _, s := range b.Statements {
+			for _, funcDef := range p.CFG.InternalCalls(s) {
+				i, ok := index[funcDef]
+				if !ok {
+					i = len(callees)
+					index[funcDef] = i
+					callees = append(callees, funcDef)
+				}
+				if !called[i] {
+					called[i] = true
+					calls = append(calls, internalCall{block: b, callee: i})
+				}
+			}
+		}
+	}
+	return callees, calls
+}
+
+// blockLines returns the header of a block followed by a line per statement,
+// its type and its symbols, as printed by Print
+func blockLines(b *CFG.Block) []string {
+	lines := []string{fmt.Sprintf("B%d (%s)", b.ID, b.Label)}
+	for _, s := range b.Statements {
+		lines = append(lines, strings.TrimSpace(s.Type.String()+" "+CFG.StatementToString(s)))
+	}
+	return lines
+}
+
+// edge styles of the CFG DOT output, indexed by EdgeType
+var dotCFGEdgeStyles = map[CFG.EdgeType]string{
+	CFG.ConditionalTrue:  `label="true", color="darkgreen"`,
+	CFG.ConditionalFalse: `label="false", color="red"`,
+	CFG.Unconditional:    `color="black"`,
+}
+
+// PrintDOT writes a graph in Graphviz DOT: a cluster per entry point holding its
+// blocks, a cluster per modifier inlined and a cluster of the internal functions
+// called, linked to the calling blocks by dashed edges
+func (p *CFGPrinter) PrintDOT(g CFGGraph) {
+	fmt.Fprintf(p.Out, "digraph %s {\n", strconv.Quote(g.Name))
+	fmt.Fprintln(p.Out, "  node [shape=box, fontname=\"Courier\"];")
+
+	for i, f := range g.Functions {
+		fmt.Fprintf(p.Out, "  subgraph cluster_f%d {\n", i)
+		fmt.Fprintf(p.Out, "    label=%s;\n", strconv.Quote(f.Name))
+		body, clusters := p._clusters(f)
+		for _, b := range body {
+			p._printDOTBlock(f, b, "    ")
+		}
+		for j, c := range clusters {
+			fmt.Fprintf(p.Out, "    subgraph cluster_f%d_m%d {\n", i, j)
+			fmt.Fprintf(p.Out, "      label=%s;\n      style=dashed;\n", strconv.Quote(c.label))
+			for _, b := range c.blocks {
+				p._printDOTBlock(f, b, "      ")
+			}
+			fmt.Fprintln(p.Out, "    }")
+		}
+
+		callees, calls := p._internalCalls(f)
+		if len(callees) > 0 {
+			fmt.Fprintf(p.Out, "    subgraph cluster_f%d_calls {\n", i)
+			fmt.Fprintln(p.Out, "      label=\"internal calls\";\n      style=dotted;")
+			for j, callee := range callees {
+				fmt.Fprintf(p.Out, "      f%d_c%d [label=%s, shape=component];\n", i, j, strconv.Quote(callee.DisplayName()+"()"))
+			}
+			fmt.Fprintln(p.Out, "    }")
+		}
+		for _, b := range f.Blocks {
+			for _, e := range b.SuccessorsEdges {
+				fmt.Fprintf(p.Out, "    b%d -> b%d [%s];\n", e.Source.ID, e.Destination.ID, dotCFGEdgeStyles[e.Type])
+			}
+		}
+		for _, c := range calls {
+			fmt.Fprintf(p.Out, "    b%d -> f%d_c%d [style=\"dashed\", color=\"gray40\", arrowhead=\"open\"];\n", c.block.ID, i, c.callee)
+		}
+		fmt.Fprintln(p.Out, "  }")
+	}
+	fmt.Fprintln(p.Out, "}")
+}
+
+func (p *CFGPrinter) _printDOTBlock(f *CFG.Function, b *CFG.Block, indent string) {
+	shape := ""
+	if b == f.Entry || b == f.Exit {
+		shape = ", shape=oval"
+	}
+	var label strings.Builder
+	for _, line := range blockLines(b) {
+		// \l ends a left-justified line
+		label.WriteString(dotEscaper.Replace(line) + `\l`)
+	}
+	fmt.Fprintf(p.Out, "%sb%d [label=\"%s\"%s];\n", indent, b.ID, label.String(), shape)
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// PrintMermaid writes a graph as a Mermaid flowchart, with the same clusters as
+// PrintDOT drawn as subgraphs. The conditional edges are labeled and colored.
+func (p *CFGPrinter) PrintMermaid(g CFGGraph) {
+	fmt.Fprintln(p.Out, "flowchart TD")
+	link := 0 // index of the next link, for linkStyle
+	var trueLinks, falseLinks, callLinks []string
+
+	for i, f := range g.Functions {
+		fmt.Fprintf(p.Out, "  subgraph f%d[\"%s\"]\n", i, mermaidEscaper.Replace(f.Name))
+		body, clusters := p._clusters(f)
+		for _, b := range body {
+			p._printMermaidBlock(f, b, "    ")
+		}
+		for j, c := range clusters {
+			fmt.Fprintf(p.Out, "    subgraph f%d_m%d[\"%s\"]\n", i, j, mermaidEscaper.Replace(c.label))
+			for _, b := range c.blocks {
+				p._printMermaidBlock(f, b, "      ")
+			}
+			fmt.Fprintln(p.Out, "    end")
+		}
+		callees, calls := p._internalCalls(f)
+		if len(callees) > 0 {
+			fmt.Fprintf(p.Out, "    subgraph f%d_calls[\"internal calls\"]\n", i)
+			for j, callee := range callees {
+				fmt.Fprintf(p.Out, "      f%d_c%d[[\"%s\"]]\n", i, j, mermaidEscaper.Replace(callee.DisplayName()+"()"))
+			}
+			fmt.Fprintln(p.Out, "    end")
+		}
+		fmt.Fprintln(p.Out, "  end")
+
+		for _, b := range f.Blocks {
+			for _, e := range b.SuccessorsEdges {
+				switch e.Type {
+				case CFG.ConditionalTrue:
+					fmt.Fprintf(p.Out, "  b%d -->|true| b%d\n", e.Source.ID, e.Destination.ID)
+					trueLinks = append(trueLinks, strconv.Itoa(link))
+				case CFG.ConditionalFalse:
+					fmt.Fprintf(p.Out, "  b%d -->|false| b%d\n", e.Source.ID, e.Destination.ID)
+					falseLinks = append(falseLinks, strconv.Itoa(link))
+				default:
+					fmt.Fprintf(p.Out, "  b%d --> b%d\n", e.Source.ID, e.Destination.ID)
+				}
+				link++
+			}
+		}
+		for _, c := range calls {
+			fmt.Fprintf(p.Out, "  b%d -.-> f%d_c%d\n", c.block.ID, i, c.callee)
+			callLinks = append(callLinks, strconv.Itoa(link))
+			link++
+		}
+	}
+
+	for _, style := range []struct {
+		links []string
+		css   string
+	}{
+		{trueLinks, "stroke:darkgreen"},
+		{falseLinks, "stroke:red"},
+		{callLinks, "stroke:gray"},
+	} {
+		if len(style.links) > 0 {
+			fmt.Fprintf(p.Out, "  linkStyle %s %s\n", strings.Join(style.links, ","), style.css)
+		}
+	}
+}
+
+func (p *CFGPrinter) _printMermaidBlock(f *CFG.Function, b *CFG.Block, indent string) {
+	lines := blockLines(b)
+	for i, line := range lines {
+		lines[i] = mermaidEscaper.Replace(line)
+	}
+	left, right := "[", "]"
+	if b == f.Entry || b == f.Exit {
+		left, right = "([", "])"
+	}
+	fmt.Fprintf(p.Out, "%sb%d%s\"%s\"%s\n", indent, b.ID, left, strings.Join(lines, "<br/>"), right)
+}
+
+// mermaidEscaper escapes the characters Mermaid reads as markup in a quoted label
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
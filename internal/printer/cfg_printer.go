@@ -1,42 +1,83 @@
 package printer
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
 	CFG "txtracker/internal/cfg"
+	"txtracker/internal/srcmap"
 )
 
 type CFGPrinter struct {
-	CFG *CFG.CFG
+	CFG     *CFG.CFG
+	Sources *srcmap.SourceMap // nil if the source files are unknown
+	Out     io.Writer
 }
 
-func NewCFGPrinter(cfg *CFG.CFG) *CFGPrinter {
+func NewCFGPrinter(cfg *CFG.CFG, sources *srcmap.SourceMap, out io.Writer) *CFGPrinter {
 	return &CFGPrinter{
-		CFG: cfg,
+		CFG:     cfg,
+		Sources: sources,
+		Out:     out,
 	}
 }
 
+// Print writes every entry point with its location, then its blocks. The
+// statements are prefixed with their line when the source file is known.
+//
+//	Entry Point# Bank.sol:14:5 -- [amount] --> Bank::withdraw(uint256)
+//	 B1 (entry) -> B2 [Unconditional]
+//	 |15 Require require(balances[msg.sender] >= amount)
 func (p *CFGPrinter) Print() {
 	for _, entry := range p.CFG.EntryPoints {
-		fmt.Println("Entry Point#", entry.SrcID, "--", func() string {
+		location := strconv.Itoa(entry.SrcID)
+		if r, file, err := p.Sources.Resolve(entry.Src); err == nil && file != nil {
+			location = file.Path + ":" + file.Position(r.Start).String()
+		}
+		fmt.Fprintln(p.Out, "Entry Point#", location, "--", func() string {
 			var res string
 			for _, p := range entry.Parameters {
 				res += "[" + p.Identifier + "]"
 			}
 			return res
-		}(), "-->", entry.Name)
+		}(), "-->", entry.Name+func() string {
+			var res string
+			for _, m := range entry.Modifiers {
+				res += " " + m
+			}
+			return res
+		}())
 		p.printFunction(entry)
-		fmt.Println()
+		fmt.Fprintln(p.Out)
 	}
 }
 
-func (p *CFGPrinter) printFunction(f *CFG.Function) {
+// PrintJSON writes the versioned export of the CFG on a single line, so that the
+// documents of several source units can be read line by line
+func (p *CFGPrinter) PrintJSON(source string) error {
+	return json.NewEncoder(p.Out).Encode(p.CFG.Export(source))
+}
 
-	p.printBlock(f.Block)
+func (p *CFGPrinter) printFunction(f *CFG.Function) {
+	for _, b := range f.Blocks {
+		p.printBlock(b)
+	}
 }
 
+// printBlock writes the block header with its successors, then its statements
 func (p *CFGPrinter) printBlock(b *CFG.Block) {
+	fmt.Fprintf(p.Out, " B%d (%s)", b.ID, b.Label)
+	for i, e := range b.SuccessorsEdges {
+		sep := ","
+		if i == 0 {
+			sep = " ->"
+		}
+		fmt.Fprintf(p.Out, "%s B%d [%s]", sep, e.Destination.ID, e.Type.String())
+	}
+	fmt.Fprintln(p.Out)
 	for _, s := range b.Statements {
-		fmt.Print(" |")
+		fmt.Fprint(p.Out, " |")
 		p.printStatement(s)
 	}
 }
@@ -44,8 +85,11 @@ func (p *CFGPrinter) printBlock(b *CFG.Block) {
 func (p *CFGPrinter) printStatement(s *CFG.Statement) {
 	tp := s.Type
 
-	fmt.Print(tp.String())
-	fmt.Print(" ")
-	fmt.Print(CFG.StatementToString(s))
-	fmt.Println()
+	if r, file, err := p.Sources.Resolve(s.ASTNode.Src); err == nil && file != nil {
+		fmt.Fprint(p.Out, file.Position(r.Start).Line, " ")
+	}
+	fmt.Fprint(p.Out, tp.String())
+	fmt.Fprint(p.Out, " ")
+	fmt.Fprint(p.Out, CFG.StatementToString(s))
+	fmt.Fprintln(p.Out)
 }
@@ -0,0 +1,63 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"txtracker/internal/detectors"
+)
+
+type FindingsPrinter struct {
+	Findings []detectors.Finding
+	Out      io.Writer
+}
+
+func NewFindingsPrinter(findings []detectors.Finding, out io.Writer) *FindingsPrinter {
+	return &FindingsPrinter{
+		Findings: findings,
+		Out:      out,
+	}
+}
+
+// Print writes one finding per paragraph with the source lines involved when
+// they are known, e.g.
+//
+//	[High/Medium] reentrancy: state variable balances written after an external call
+//	  in Bank::withdraw() at Bank.sol:16:9
+//	    16 |         balances[msg.sender] -= amount;
+//	       |         ^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^
+func (p *FindingsPrinter) Print() {
+	for _, f := range p.Findings {
+		fmt.Fprintf(p.Out, "[%s/%s] %s: %s\n", f.Severity, f.Confidence, f.ID, f.Message)
+		if f.Function != "" {
+			fmt.Fprintf(p.Out, "  in %s at %s\n", f.Function, f.Location)
+		} else {
+			fmt.Fprintf(p.Out, "  at %s\n", f.Location)
+		}
+		if f.Snippet != "" {
+			for _, line := range strings.Split(strings.TrimSuffix(f.Snippet, "\n"), "\n") {
+				fmt.Fprintln(p.Out, "    "+line)
+			}
+		}
+	}
+	fmt.Fprintf(p.Out, "%d finding(s)\n", len(p.Findings))
+}
+
+// PrintJSON writes the findings as a JSON array
+func (p *FindingsPrinter) PrintJSON() error {
+	findings := p.Findings
+	if findings == nil {
+		findings = []detectors.Finding{}
+	}
+	encoder := json.NewEncoder(p.Out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(findings)
+}
+
+// PrintDetectors lists the registered detectors
+func PrintDetectors(list []detectors.Detector, out io.Writer) {
+	for _, d := range list {
+		fmt.Fprintf(out, "%-20s %-13s %-6s %s\n", d.ID(), d.Severity(), d.Confidence(), d.Description())
+	}
+}
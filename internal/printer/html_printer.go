@@ -0,0 +1,466 @@
+package printer
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	AST "txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/detectors"
+	"txtracker/internal/srcmap"
+	ST "txtracker/internal/symbol_table"
+	"txtracker/internal/txtracker"
+)
+
+// HTMLPrinter writes the report of a deployed contract as a single HTML page
+// holding its styles, to be opened offline: the source code, the entry points
+// and their CFG, the state variables read and written by each entry point and
+// the findings, linked to the source lines
+type HTMLPrinter struct {
+	Ctx      *detectors.Context
+	Findings []detectors.Finding // the findings of the source unit
+	Out      io.Writer
+}
+
+func NewHTMLPrinter(ctx *detectors.Context, findings []detectors.Finding, out io.Writer) *HTMLPrinter {
+	return &HTMLPrinter{
+		Ctx:      ctx,
+		Findings: findings,
+		Out:      out,
+	}
+}
+
+// Contracts returns the deployed contracts of the source unit, in the order of
+// their entry points
+func (p *HTMLPrinter) Contracts() []string {
+	var res []string
+	for _, g := range CFGGraphsByContract(p.Ctx.CFG) {
+		res = append(res, g.Name)
+	}
+	return res
+}
+
+// FileName returns the path of the report of a contract, relative to the report
+// directory: a file named after the contract in the directory of its source
+// unit, e.g. src/Bank/Bank.html for the Bank contract of src/Bank.sol
+func (p *HTMLPrinter) FileName(contract string) string {
+	return filepath.Join(UnitDir(p.Ctx.Path), unsafeFileChars.ReplaceAllString(contract, "_")+".html")
+}
+
+type htmlReport struct {
+	Contract    string
+	Path        string
+	Counts      []htmlCount
+	Functions   []*htmlFunction
+	Findings    []htmlFinding
+	Variables   []string
+	Rows        []htmlRow
+	Lines       []*htmlLine
+	SourceKnown bool
+}
+
+type htmlCount struct {
+	Severity string
+	Count    int
+}
+
+// htmlLocation is a location in the source, Line is set when it lies in the
+// file shown
+type htmlLocation struct {
+	Text string
+	Line int
+}
+
+type htmlFunction struct {
+	Index      int
+	Name       string
+	Location   htmlLocation
+	Modifiers  []string
+	Parameters []string
+	Blocks     []htmlBlock
+}
+
+type htmlBlock struct {
+	ID         int
+	Label      string
+	Modifier   string
+	Successors []htmlEdge
+	Statements []htmlStatement
+}
+
+type htmlEdge struct {
+	ID   int
+	Type string
+}
+
+type htmlStatement struct {
+	Location htmlLocation
+	Type     string
+	Text     string
+	Modify   []htmlSymbol
+	Depends  []htmlSymbol
+	Declare  []htmlSymbol
+}
+
+type htmlSymbol struct {
+	Name  string
+	State bool // a state variable
+}
+
+type htmlFinding struct {
+	ID         string
+	Severity   string
+	Confidence string
+	Message    string
+	Function   string
+	Location   htmlLocation
+	Related    []htmlLocation
+	Snippet    string
+}
+
+type htmlRow struct {
+	Function string
+	Index    int // of the function, -1 if it is not an entry point of the report
+	Cells    []htmlCell
+}
+
+type htmlCell struct {
+	Access string // R, W, RW or empty
+	Guards string // of the writes as in the access matrix, "none" if unguarded
+}
+
+type htmlLine struct {
+	Number  int
+	Text    string
+	Finding bool // part of the range of a finding
+	Entries []*htmlFunction
+}
+
+// Print writes the report of a contract
+func (p *HTMLPrinter) Print(contract string) error {
+	contractDef, names := p._contract(contract)
+	var file *srcmap.File // the file of the contract, nil if unknown
+	if contractDef != nil {
+		_, file, _ = p.Ctx.Sources.Resolve(contractDef.Src)
+	}
+
+	report := &htmlReport{Contract: contract, Path: p.Ctx.Path, SourceKnown: file != nil}
+	if file != nil {
+		report.Path = file.Path
+		last := file.Position(len(file.Content)).Line
+		if last > 1 && file.Line(last) == "" {
+			last-- // the line break ending the file
+		}
+		for n := 1; n <= last; n++ {
+			report.Lines = append(report.Lines, &htmlLine{Number: n, Text: file.Line(n)})
+		}
+	}
+	location := func(src string) htmlLocation {
+		r, f, err := p.Ctx.Sources.Resolve(src)
+		if err != nil {
+			return htmlLocation{}
+		}
+		if f == nil {
+			return htmlLocation{Text: fmt.Sprintf("%s@%d:%d", p.Ctx.Path, r.Start, r.Length)}
+		}
+		pos := f.Position(r.Start)
+		res := htmlLocation{Text: f.Path + ":" + pos.String()}
+		if f == file {
+			res.Line = pos.Line
+		}
+		return res
+	}
+
+	for _, f := range p.Ctx.CFG.EntryPoints {
+		if strings.Split(f.Name, "::")[0] != contract {
+			continue
+		}
+		function := &htmlFunction{
+			Index:     len(report.Functions),
+			Name:      f.Name,
+			Location:  location(f.Src),
+			Modifiers: f.Modifiers,
+		}
+		for _, param := range f.Parameters {
+			function.Parameters = append(function.Parameters, param.Identifier)
+		}
+		for _, b := range f.Blocks {
+			block := htmlBlock{ID: b.ID, Label: b.Label, Modifier: b.Modifier}
+			for _, e := range b.SuccessorsEdges {
+				block.Successors = append(block.Successors, htmlEdge{ID: e.Destination.ID, Type: e.Type.String()})
+			}
+			for _, s := range b.Statements {
+				block.Statements = append(block.Statements, htmlStatement{
+					Location: location(s.ASTNode.Src),
+					Type:     s.Type.String(),
+					Text:     strings.TrimSpace(CFG.StatementToString(s)),
+					Modify:   htmlSymbols(s.Modify),
+					Depends:  htmlSymbols(s.Depends),
+					Declare:  htmlSymbols(s.Declare),
+				})
+			}
+			function.Blocks = append(function.Blocks, block)
+		}
+		report.Functions = append(report.Functions, function)
+		if n := function.Location.Line; n >= 1 && n <= len(report.Lines) {
+			report.Lines[n-1].Entries = append(report.Lines[n-1].Entries, function)
+		}
+	}
+
+	counts := make(map[detectors.Severity]int)
+	for _, f := range p.Findings {
+		if !names[strings.Split(f.Function, "::")[0]] {
+			continue
+		}
+		counts[f.Severity]++
+		finding := htmlFinding{
+			ID:         f.ID,
+			Severity:   f.Severity.String(),
+			Confidence: f.Confidence.String(),
+			Message:    f.Message,
+			Function:   f.Function,
+			Location:   findingLocation(f.Location, file),
+			Snippet:    f.Snippet,
+		}
+		for _, related := range f.Related {
+			finding.Related = append(finding.Related, findingLocation(related, file))
+		}
+		report.Findings = append(report.Findings, finding)
+
+		// the lines of the range, but the one the range ends at the start of
+		if file != nil && f.Location.Path == file.Path && f.Location.Line > 0 {
+			end := f.Location.EndLine
+			if f.Location.EndColumn == 1 && end > f.Location.Line {
+				end--
+			}
+			for n := f.Location.Line; n <= end && n <= len(report.Lines); n++ {
+				report.Lines[n-1].Finding = true
+			}
+		}
+	}
+	for s := detectors.High; s >= detectors.Informational; s-- {
+		report.Counts = append(report.Counts, htmlCount{Severity: s.String(), Count: counts[s]})
+	}
+
+	p._matrix(report, contract)
+	return htmlTemplate.Execute(p.Out, report)
+}
+
+// _contract returns the definition of a contract and the names of the contract
+// and of its bases, whose functions the entry points may run
+func (p *HTMLPrinter) _contract(contract string) (*AST.Common, map[string]bool) {
+	names := map[string]bool{contract: true}
+	for _, node := range p.Ctx.Root.Children {
+		def, ok := node.ASTNode.(*AST.ContractDefinition)
+		if !ok || def.Name != contract {
+			continue
+		}
+		for _, base := range p.Ctx.SymbolTable.LinearizedBaseContracts(node) {
+			names[base.ASTNode.(*AST.ContractDefinition).Name] = true
+		}
+		return node, names
+	}
+	return nil, names
+}
+
+// _matrix fills the state variables read and written by each entry point, with
+// the guards of the writes
+func (p *HTMLPrinter) _matrix(report *htmlReport, contract string) {
+	var permissions []*detectors.Permission
+	for _, m := range detectors.NewPermissionMatrices(p.Ctx) {
+		if m.Contract == contract {
+			permissions = m.Permissions
+		}
+	}
+	txs := txtracker.NewGenerator(p.Ctx, 0).Transactions(contract)
+
+	seen := make(map[string]bool)
+	addVariable := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			report.Variables = append(report.Variables, name)
+		}
+	}
+	for _, tx := range txs {
+		for _, v := range append(append([]string{}, tx.Writes...), tx.Reads...) {
+			addVariable(variableName(v))
+		}
+	}
+	for _, perm := range permissions {
+		for _, w := range perm.Writes {
+			addVariable(w.Variable)
+		}
+	}
+
+	for _, tx := range txs {
+		row := htmlRow{Function: tx.Name, Index: -1}
+		for _, f := range report.Functions {
+			if f.Name == tx.Name {
+				row.Index = f.Index
+			}
+		}
+		var permission *detectors.Permission
+		for _, perm := range permissions {
+			if perm.Function == tx.Function {
+				permission = perm
+			}
+		}
+		for _, v := range report.Variables {
+			var c htmlCell
+			if containsVariable(tx.Reads, v) {
+				c.Access = "R"
+			}
+			// the permission holds the writes of the internal functions called too
+			if permission != nil {
+				if _, ok := permission.Guards(v); ok {
+					c.Access += "W"
+					c.Guards = cell(permission, v)
+				}
+			}
+			if containsVariable(tx.Writes, v) && c.Guards == "" {
+				c.Access += "W"
+			}
+			row.Cells = append(row.Cells, c)
+		}
+		report.Rows = append(report.Rows, row)
+	}
+}
+
+// variableName returns the name of a state variable given as Contract::name
+func variableName(namespace string) string {
+	parts := strings.Split(namespace, "::")
+	return parts[len(parts)-1]
+}
+
+func containsVariable(list []string, name string) bool {
+	for _, v := range list {
+		if variableName(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlSymbols(symbols []ST.Symbol) []htmlSymbol {
+	var res []htmlSymbol
+	for _, s := range symbols {
+		name := s.Identifier
+		if s.Type == ST.Function {
+			name += "()"
+		}
+		res = append(res, htmlSymbol{Name: name, State: s.Type == ST.StateVariable})
+	}
+	return res
+}
+
+// findingLocation returns the location of a finding, linked to its line when it
+// lies in the file shown
+func findingLocation(l detectors.Location, file *srcmap.File) htmlLocation {
+	res := htmlLocation{Text: l.String()}
+	if file != nil && l.Path == file.Path {
+		res.Line = l.Line
+	}
+	return res
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lower": strings.ToLower,
+}).Parse(htmlReportTemplate))
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Contract}} - TxTracker report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #1f2328; }
+header { background: #24292f; color: #fff; padding: 16px 24px; }
+header h1 { margin: 0 0 4px; font-size: 22px; }
+header .path { font-family: monospace; color: #d0d7de; }
+main { padding: 8px 24px 48px; }
+h2 { border-bottom: 1px solid #d0d7de; padding-bottom: 4px; margin-top: 32px; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+code, pre, .mono { font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 13px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.counts span { display: inline-block; margin-right: 12px; }
+.sev { font-weight: bold; padding: 1px 6px; border-radius: 4px; color: #fff; }
+.sev-high { background: #cf222e; }
+.sev-medium { background: #bc4c00; }
+.sev-low { background: #9a6700; }
+.sev-informational { background: #57606a; }
+.finding { margin: 12px 0; padding: 8px 12px; border-left: 4px solid #d0d7de; background: #f6f8fa; }
+.finding pre { background: #fff; padding: 8px; overflow-x: auto; }
+.function { margin: 16px 0 24px; }
+.block { margin: 8px 0; }
+.block caption { text-align: left; font-weight: bold; padding: 4px 0; }
+.block .modifier { color: #8250df; font-weight: normal; }
+.state { font-weight: bold; color: #953800; }
+.matrix td { text-align: center; font-family: monospace; }
+.matrix td.name { text-align: left; }
+.matrix .guards { display: block; font-size: 11px; color: #57606a; }
+.source { width: 100%; }
+.source td { border: none; padding: 0 8px; white-space: pre; font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 13px; }
+.source td.ln { text-align: right; color: #8c959f; user-select: none; width: 1%; }
+.source td.entries { width: 1%; }
+.source tr.hit { background: #ffebe9; }
+.source tr:target { background: #fff8c5; }
+.entry { font-size: 11px; background: #ddf4ff; border-radius: 4px; padding: 0 4px; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Contract}}</h1>
+<div class="path">{{.Path}}</div>
+</header>
+<main>
+<p class="counts">{{range .Counts}}<span><span class="sev sev-{{lower .Severity}}">{{.Severity}}</span> {{.Count}}</span>{{end}}</p>
+
+<h2 id="entry-points">Entry points</h2>
+<ul>
+{{range .Functions}}<li><a href="#fn-{{.Index}}">{{.Name}}</a>{{if .Location.Text}} at {{template "location" .Location}}{{end}}</li>
+{{end}}</ul>
+
+<h2 id="findings">Findings</h2>
+{{if not .Findings}}<p>No finding.</p>{{end}}
+{{range .Findings}}<div class="finding">
+<div><span class="sev sev-{{lower .Severity}}">{{.Severity}}</span> <code>{{.ID}}</code> (confidence {{.Confidence}}): {{.Message}}</div>
+<div>{{if .Function}}in <code>{{.Function}}</code> {{end}}at {{template "location" .Location}}</div>
+{{if .Related}}<div>related: {{range $i, $l := .Related}}{{if $i}}, {{end}}{{template "location" $l}}{{end}}</div>{{end}}
+{{if .Snippet}}<pre>{{.Snippet}}</pre>{{end}}
+</div>
+{{end}}
+
+<h2 id="state-variables">State variables</h2>
+{{if not .Variables}}<p>No entry point reads or writes a state variable.</p>{{else}}
+<p>R: read, W: written, with the guards standing on every path to the write. Constructors are left out.</p>
+<table class="matrix">
+<tr><th>entry point</th>{{range .Variables}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr><td class="name">{{if ge .Index 0}}<a href="#fn-{{.Index}}">{{.Function}}</a>{{else}}{{.Function}}{{end}}</td>{{range .Cells}}<td>{{.Access}}{{if .Guards}}<span class="guards">{{.Guards}}</span>{{end}}</td>{{end}}</tr>
+{{end}}</table>{{end}}
+
+<h2 id="cfg">Control flow graphs</h2>
+{{range .Functions}}<div class="function" id="fn-{{.Index}}">
+<h3>{{.Name}}</h3>
+<p>{{template "location" .Location}}{{if .Parameters}} &middot; parameters: <code>{{range $i, $p := .Parameters}}{{if $i}}, {{end}}{{$p}}{{end}}</code>{{end}}{{if .Modifiers}} &middot; modifiers: <code>{{range $i, $m := .Modifiers}}{{if $i}}, {{end}}{{$m}}{{end}}</code>{{end}}</p>
+{{range .Blocks}}<table class="block" id="b{{.ID}}">
+<caption>B{{.ID}} ({{.Label}}){{if .Modifier}} <span class="modifier">modifier {{.Modifier}}</span>{{end}}{{range $i, $e := .Successors}}{{if $i}},{{else}} &rarr;{{end}} <a href="#b{{$e.ID}}">B{{$e.ID}}</a> <small>{{$e.Type}}</small>{{end}}</caption>
+{{if .Statements}}<tr><th>line</th><th>type</th><th>statement</th><th>modify</th><th>depends</th><th>declare</th></tr>
+{{range .Statements}}<tr><td>{{template "location" .Location}}</td><td>{{.Type}}</td><td class="mono">{{.Text}}</td><td class="mono">{{template "symbols" .Modify}}</td><td class="mono">{{template "symbols" .Depends}}</td><td class="mono">{{template "symbols" .Declare}}</td></tr>
+{{end}}{{end}}</table>
+{{end}}</div>
+{{end}}
+
+<h2 id="source">Source</h2>
+{{if .SourceKnown}}<table class="source">
+{{range .Lines}}<tr id="L{{.Number}}"{{if .Finding}} class="hit"{{end}}><td class="ln"><a href="#L{{.Number}}">{{.Number}}</a></td><td class="entries">{{range .Entries}}<a class="entry" href="#fn-{{.Index}}">{{.Name}}</a> {{end}}</td><td>{{.Text}}</td></tr>
+{{end}}</table>{{else}}<p>The source file of {{.Contract}} is unknown.</p>{{end}}
+</main>
+</body>
+</html>
+{{define "location"}}{{if .Line}}<a href="#L{{.Line}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}{{end}}
+{{define "symbols"}}{{range $i, $s := .}}{{if $i}}, {{end}}{{if $s.State}}<span class="state">{{$s.Name}}</span>{{else}}{{$s.Name}}{{end}}{{end}}{{end}}`
@@ -0,0 +1,259 @@
+package printer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"txtracker/internal/detectors"
+)
+
+// SARIFPrinter writes the findings as a SARIF 2.1.0 log for code scanning viewers
+type SARIFPrinter struct {
+	Findings []detectors.Finding
+	Rules    []detectors.Detector // the detectors run
+	Warnings []detectors.Step     // the warnings of the CFG construction
+	Out      io.Writer
+}
+
+func NewSARIFPrinter(findings []detectors.Finding, rules []detectors.Detector, warnings []detectors.Step, out io.Writer) *SARIFPrinter {
+	return &SARIFPrinter{
+		Findings: findings,
+		Rules:    rules,
+		Warnings: warnings,
+		Out:      out,
+	}
+}
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+	ColumnKind  string            `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules"`
+	} `json:"driver"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+	Properties struct {
+		Precision string `json:"precision"`
+	} `json:"properties"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	CodeFlows           []sarifCodeFlow   `json:"codeFlows,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          struct {
+		Confidence string `json:"confidence"`
+	} `json:"properties"`
+}
+
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"` // 1-based, related locations only
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+	Region *sarifRegion `json:"region,omitempty"` // nil if the src attribute is invalid
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+	ByteOffset  int `json:"byteOffset"` // solc counts bytes, not characters
+	ByteLength  int `json:"byteLength"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifCodeFlow struct {
+	ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifThreadFlow struct {
+	Locations []sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location sarifLocation `json:"location"`
+}
+
+// Print writes a log of a single run: the rules of the detectors run, a result per
+// finding and the CFG warnings as notifications of the invocation
+func (p *SARIFPrinter) Print() error {
+	run := sarifRun{
+		Invocations: []sarifInvocation{{ExecutionSuccessful: true, ToolExecutionNotifications: []sarifNotification{}}},
+		Results:     []sarifResult{},
+		ColumnKind:  "unicodeCodePoints",
+	}
+	run.Tool.Driver.Name = "TxTracker"
+	run.Tool.Driver.Rules = []sarifRule{}
+
+	ruleIndex := make(map[string]int)
+	for i, d := range p.Rules {
+		rule := sarifRule{
+			ID:               d.ID(),
+			Name:             ruleName(d.ID()),
+			ShortDescription: sarifMessage{Text: d.Description()},
+		}
+		rule.DefaultConfiguration.Level = sarifLevel(d.Severity())
+		rule.Properties.Precision = strings.ToLower(d.Confidence().String())
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		ruleIndex[d.ID()] = i
+	}
+
+	for _, w := range p.Warnings {
+		run.Invocations[0].ToolExecutionNotifications = append(run.Invocations[0].ToolExecutionNotifications, sarifNotification{
+			Level:     "warning",
+			Message:   sarifMessage{Text: w.Message},
+			Locations: []sarifLocation{newSARIFLocation(w.Location)},
+		})
+	}
+
+	// the findings sharing a fingerprint key, by their order of location
+	occurrences := make(map[string]int)
+	for _, f := range p.Findings {
+		key := fingerprintKey(f)
+		result := sarifResult{
+			RuleID:              f.ID,
+			RuleIndex:           ruleIndex[f.ID],
+			Level:               sarifLevel(f.Severity),
+			Message:             sarifMessage{Text: f.Message},
+			PartialFingerprints: map[string]string{"txtrackerFinding/v1": fingerprint(key, occurrences[key])},
+		}
+		occurrences[key]++
+		result.Properties.Confidence = strings.ToLower(f.Confidence.String())
+
+		location := newSARIFLocation(f.Location)
+		if f.Function != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: f.Function, Kind: "function"}}
+		}
+		result.Locations = []sarifLocation{location}
+		for i, related := range f.Related {
+			l := newSARIFLocation(related)
+			l.ID = i + 1
+			result.RelatedLocations = append(result.RelatedLocations, l)
+		}
+		for _, path := range f.Paths {
+			var thread sarifThreadFlow
+			for _, step := range path {
+				l := newSARIFLocation(step.Location)
+				l.Message = &sarifMessage{Text: step.Message}
+				thread.Locations = append(thread.Locations, sarifThreadFlowLocation{Location: l})
+			}
+			result.CodeFlows = append(result.CodeFlows, sarifCodeFlow{ThreadFlows: []sarifThreadFlow{thread}})
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(p.Out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}})
+}
+
+// newSARIFLocation returns the physical location of a finding, its region holds
+// the lines and columns when the source file is known and the byte range anyway
+func newSARIFLocation(l detectors.Location) sarifLocation {
+	var res sarifLocation
+	res.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(l.Path)
+	if l.File == -1 {
+		return res
+	}
+	res.PhysicalLocation.Region = &sarifRegion{
+		StartLine:   l.Line,
+		StartColumn: l.Column,
+		EndLine:     l.EndLine,
+		EndColumn:   l.EndColumn,
+		ByteOffset:  l.Start,
+		ByteLength:  l.Length,
+	}
+	return res
+}
+
+// sarifLevel maps a severity to the level of a result
+func sarifLevel(s detectors.Severity) string {
+	switch s {
+	case detectors.High:
+		return "error"
+	case detectors.Medium:
+		return "warning"
+	}
+	return "note"
+}
+
+// ruleName turns the ID of a detector into a rule name, e.g. UncheckedCall for unchecked-call
+func ruleName(id string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(id, "-") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// fingerprintKey is what identifies a finding across runs whatever the lines
+// added above it: the detector, the file, the function and the message
+func fingerprintKey(f detectors.Finding) string {
+	return strings.Join([]string{f.ID, filepath.ToSlash(f.Location.Path), f.Function, f.Message}, "\x00")
+}
+
+// fingerprint hashes the key of a finding and its occurrence among the findings
+// sharing the key, e.g. the same write after two calls of a function
+func fingerprint(key string, occurrence int) string {
+	sum := sha256.Sum256([]byte(key + "\x00" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,71 @@
+package printer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"txtracker/internal/detectors"
+)
+
+// SummaryPrinter writes the function summaries of the source units one after
+// the other, as a single CSV table or as JSON Lines
+type SummaryPrinter struct {
+	Out    io.Writer
+	header bool // whether the CSV header was written
+}
+
+func NewSummaryPrinter(out io.Writer) *SummaryPrinter {
+	return &SummaryPrinter{
+		Out: out,
+	}
+}
+
+// summaryColumns is the CSV header, the lists are joined with ";"
+var summaryColumns = []string{
+	"path", "contract", "function", "visibility", "payable", "modifiers",
+	"reads", "writes", "events", "external_calls", "guards",
+}
+
+// PrintCSV writes a row per summary, preceded by the header on the first call
+func (p *SummaryPrinter) PrintCSV(summaries []*detectors.FunctionSummary) error {
+	w := csv.NewWriter(p.Out)
+	if !p.header {
+		p.header = true
+		if err := w.Write(summaryColumns); err != nil {
+			return err
+		}
+	}
+	for _, s := range summaries {
+		err := w.Write([]string{
+			s.Path,
+			s.Contract,
+			s.Function,
+			s.Visibility,
+			strconv.FormatBool(s.Payable),
+			strings.Join(s.Modifiers, ";"),
+			strings.Join(s.Reads, ";"),
+			strings.Join(s.Writes, ";"),
+			strings.Join(s.Events, ";"),
+			strings.Join(s.ExternalCalls, ";"),
+			strings.Join(s.Guards, ";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// PrintJSONL writes a JSON object per summary, one per line
+func (p *SummaryPrinter) PrintJSONL(summaries []*detectors.FunctionSummary) error {
+	encoder := json.NewEncoder(p.Out)
+	for _, s := range summaries {
+		if err := encoder.Encode(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
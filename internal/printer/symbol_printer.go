@@ -0,0 +1,57 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	ST "txtracker/internal/symbol_table"
+)
+
+type SymbolPrinter struct {
+	SymbolTable *ST.GlobalSymbolTable
+	Out         io.Writer
+}
+
+func NewSymbolPrinter(symbolTable *ST.GlobalSymbolTable, out io.Writer) *SymbolPrinter {
+	return &SymbolPrinter{
+		SymbolTable: symbolTable,
+		Out:         out,
+	}
+}
+
+// Print writes one line per symbol, sorted by namespace
+func (p *SymbolPrinter) Print() {
+	for _, key := range p.sortedKeys() {
+		symbol := p.SymbolTable.Table[key]
+		fmt.Fprintf(p.Out, "%-14s %s\n", symbol.Type.String(), key)
+	}
+}
+
+type symbolJSON struct {
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+}
+
+// PrintJSON writes the symbol table as a JSON array
+func (p *SymbolPrinter) PrintJSON() error {
+	var symbols []symbolJSON
+	for _, key := range p.sortedKeys() {
+		symbols = append(symbols, symbolJSON{
+			Namespace: key,
+			Type:      p.SymbolTable.Table[key].Type.String(),
+		})
+	}
+	encoder := json.NewEncoder(p.Out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(symbols)
+}
+
+func (p *SymbolPrinter) sortedKeys() []string {
+	keys := make([]string, 0, len(p.SymbolTable.Table))
+	for key := range p.SymbolTable.Table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,63 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"txtracker/internal/txtracker"
+)
+
+type TxSequencePrinter struct {
+	Sequences []*txtracker.TxSeQuence
+	Out       io.Writer
+}
+
+func NewTxSequencePrinter(sequences []*txtracker.TxSeQuence, out io.Writer) *TxSequencePrinter {
+	return &TxSequencePrinter{
+		Sequences: sequences,
+		Out:       out,
+	}
+}
+
+// Print writes every sequence followed by the state variables linking its transactions
+func (p *TxSequencePrinter) Print() {
+	for _, seq := range p.Sequences {
+		fmt.Fprintln(p.Out, seq.Name)
+		for i := 1; i < len(seq.Tx); i++ {
+			fmt.Fprintf(p.Out, " |%s reads %v\n", seq.Tx[i].Name, seq.Dependencies(i))
+		}
+		fmt.Fprintln(p.Out)
+	}
+}
+
+type txJSON struct {
+	Name         string   `json:"name"`
+	Reads        []string `json:"reads"`
+	Writes       []string `json:"writes"`
+	Dependencies []string `json:"dependencies"`
+}
+
+type txSequenceJSON struct {
+	Name string   `json:"name"`
+	Txs  []txJSON `json:"txs"`
+}
+
+// PrintJSON writes the sequences as a JSON array
+func (p *TxSequencePrinter) PrintJSON() error {
+	sequences := []txSequenceJSON{}
+	for _, seq := range p.Sequences {
+		s := txSequenceJSON{Name: seq.Name}
+		for i, tx := range seq.Tx {
+			s.Txs = append(s.Txs, txJSON{
+				Name:         tx.Name,
+				Reads:        tx.Reads,
+				Writes:       tx.Writes,
+				Dependencies: seq.Dependencies(i),
+			})
+		}
+		sequences = append(sequences, s)
+	}
+	encoder := json.NewEncoder(p.Out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sequences)
+}
@@ -0,0 +1,200 @@
+package srcmap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Range is a decoded src attribute of an AST node, "start:length:fileIndex".
+// Start and Length count bytes of the UTF-8 source.
+type Range struct {
+	Start  int
+	Length int
+	File   int // index of the source file in the compilation, -1 if unknown
+}
+
+// Parse decodes the src attribute of an AST node
+func Parse(src string) (Range, error) {
+	parts := strings.Split(src, ":")
+	if len(parts) != 3 {
+		return Range{}, fmt.Errorf("invalid src %q", src)
+	}
+	var values [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid src %q: %v", src, err)
+		}
+		values[i] = v
+	}
+	return Range{Start: values[0], Length: values[1], File: values[2]}, nil
+}
+
+func (r Range) End() int {
+	return r.Start + r.Length
+}
+
+// Position is a place in a source file. Line and Column start at 1, Column counts
+// characters, not bytes: é is one column.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// File is a source file of a compilation
+type File struct {
+	Path    string
+	Content []byte
+	lines   []int // byte offset of the start of each line
+}
+
+func NewFile(path string, content []byte) *File {
+	f := &File{
+		Path:    path,
+		Content: content,
+		lines:   []int{0},
+	}
+	for i, c := range content {
+		if c == '\n' {
+			f.lines = append(f.lines, i+1)
+		}
+	}
+	return f
+}
+
+// Position returns the line and column of a byte offset, an offset past the end
+// of the file is moved to the end
+func (f *File) Position(offset int) Position {
+	offset = f._clamp(offset)
+	line := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	column := utf8.RuneCount(f.Content[f.lines[line]:offset]) + 1
+	return Position{Line: line + 1, Column: column}
+}
+
+// Line returns the text of a line without its line break, "" if out of range
+func (f *File) Line(n int) string {
+	if n < 1 || n > len(f.lines) {
+		return ""
+	}
+	end := len(f.Content)
+	if n < len(f.lines) {
+		end = f.lines[n] - 1
+	}
+	return strings.TrimSuffix(string(f.Content[f.lines[n-1]:end]), "\r")
+}
+
+// Text returns the source code of a range
+func (f *File) Text(r Range) string {
+	return string(f.Content[f._clamp(r.Start):f._clamp(r.End())])
+}
+
+func (f *File) _clamp(offset int) int {
+	if offset < 0 {
+		return 0
+	}
+	if offset > len(f.Content) {
+		return len(f.Content)
+	}
+	return offset
+}
+
+// maxSnippetLines is the number of lines of a range shown by Snippet
+const maxSnippetLines = 4
+
+// Snippet renders the lines of a range with their number, the range underlined
+// with carets:
+//
+//	14 |         balances[msg.sender] -= amount;
+//	   |         ^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^
+func (f *File) Snippet(r Range) string {
+	start, end := f.Position(r.Start), f.Position(r.End())
+	if r.Length > 0 && end.Column == 1 && end.Line > start.Line {
+		// the range ends with a line break
+		end.Line--
+		end.Column = utf8.RuneCountInString(f.Line(end.Line)) + 1
+	}
+	width := len(strconv.Itoa(end.Line))
+
+	var b strings.Builder
+	for n := start.Line; n <= end.Line; n++ {
+		if n-start.Line == maxSnippetLines {
+			fmt.Fprintf(&b, "%*s | ...\n", width, "")
+			break
+		}
+		text := f.Line(n)
+		from, to := 1, utf8.RuneCountInString(text)+1
+		if n == start.Line {
+			from = start.Column
+		}
+		if n == end.Line {
+			to = end.Column
+		}
+		fmt.Fprintf(&b, "%*d | %s\n", width, n, text)
+		fmt.Fprintf(&b, "%*s | %s\n", width, "", underline(text, from, to))
+	}
+	return b.String()
+}
+
+// underline returns carets under the columns [from, to) of a line, at least one,
+// keeping its tabs so that they line up
+func underline(text string, from, to int) string {
+	var b strings.Builder
+	column := 1
+	for _, r := range text {
+		if column >= from {
+			break
+		}
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+		column++
+	}
+	b.WriteString(strings.Repeat("^", max(to-from, 1)))
+	return b.String()
+}
+
+// SourceMap maps the file indices of the src attributes to the source files of a
+// compilation. solc numbers the files in the order of their source unit names;
+// the index is the id of the source in the standard JSON output.
+type SourceMap struct {
+	files map[int]*File
+}
+
+func NewSourceMap() *SourceMap {
+	return &SourceMap{
+		files: make(map[int]*File),
+	}
+}
+
+// Add registers the file of an index and returns it
+func (m *SourceMap) Add(index int, path string, content []byte) *File {
+	f := NewFile(path, content)
+	m.files[index] = f
+	return f
+}
+
+// File returns the file of an index, nil if unknown
+func (m *SourceMap) File(index int) *File {
+	if m == nil {
+		return nil
+	}
+	return m.files[index]
+}
+
+// Resolve returns the range of a src attribute and its file, nil if unknown
+func (m *SourceMap) Resolve(src string) (Range, *File, error) {
+	r, err := Parse(src)
+	if err != nil {
+		return Range{File: -1}, nil, err
+	}
+	return r, m.File(r.File), nil
+}
@@ -1,6 +1,7 @@
 package symboltable
 
 import (
+	"fmt"
 	"strings"
 	"txtracker/internal/ast"
 	"txtracker/internal/logger"
@@ -8,6 +9,9 @@ import (
 
 type GlobalSymbolTable struct {
 	Table map[string]Symbol
+	// Declarations indexes every node of the definition tree by its AST ID,
+	// so that referencedDeclaration can be resolved
+	Declarations map[int]*ast.Common
 }
 
 type Symbol struct {
@@ -17,6 +21,8 @@ type Symbol struct {
 	IsFunctionCall bool
 	FunctionCalls  []string
 	Arributes      map[string]interface{}
+	// AST ID of the declaration the symbol refers to, 0 if unknown
+	DeclarationID int
 }
 
 type Namespace []string
@@ -38,6 +44,13 @@ func (n *Namespace) Pop() Namespace {
 	return *n
 }
 
+// Copy returns a namespace that does not share memory with n
+func (n *Namespace) Copy() Namespace {
+	res := make(Namespace, len(*n))
+	copy(res, *n)
+	return res
+}
+
 func (n *Namespace) String() string {
 	return strings.Join(*n, "::")
 }
@@ -56,23 +69,57 @@ const (
 	Unknown
 )
 
-func NewGlobalSymbolTable(root *ast.Common) *GlobalSymbolTable {
+func (s SymbolType) String() string {
+	return [...]string{
+		"StateVariable",
+		"LocalVariable",
+		"Function",
+		"Constructor",
+		"Fallback",
+		"Receive",
+		"FreeFunction",
+		"Event",
+		"Unknown",
+	}[s]
+}
+
+// NewGlobalSymbolTable builds the symbol table of the source units of a compilation,
+// so that the declarations of the imported units are resolved too. It fails on a
+// declaration outside of a named contract.
+func NewGlobalSymbolTable(roots ...*ast.Common) (*GlobalSymbolTable, error) {
 	gst := &GlobalSymbolTable{
-		Table: make(map[string]Symbol),
+		Table:        make(map[string]Symbol),
+		Declarations: make(map[int]*ast.Common),
 	}
 
-	contractDefs := _findContractDefinition(root)
+	var contractDefs []*ast.Common
+	for _, root := range roots {
+		gst._indexDeclarations(root)
+		contractDefs = append(contractDefs, _findContractDefinition(root)...)
+	}
 
 	var symbols []*Symbol
 	for _, contractDef := range contractDefs {
-		symbols = append(symbols, _findGlobalSymbols(contractDef)...)
+		found, err := _findGlobalSymbols(contractDef)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, found...)
 	}
 
 	for _, symbol := range symbols {
 		gst.InsertSymbol(*symbol)
 	}
 
-	return gst
+	for _, contractDef := range contractDefs {
+		for _, symbol := range gst._findInheritedStateVariables(contractDef) {
+			if _, ok := gst.Table[symbol.Namespace.String()]; !ok {
+				gst.InsertSymbol(*symbol)
+			}
+		}
+	}
+
+	return gst, nil
 }
 
 func (gst *GlobalSymbolTable) InsertSymbol(symbol Symbol) {
@@ -83,6 +130,69 @@ func (gst *GlobalSymbolTable) LookupSymbol(symbolName string) Symbol {
 	return gst.Table[symbolName]
 }
 
+// LookupDeclaration returns the definition node with the given AST ID, or nil
+// if the ID does not belong to a definition (e.g. builtins have negative IDs)
+func (gst *GlobalSymbolTable) LookupDeclaration(id int) *ast.Common {
+	return gst.Declarations[id]
+}
+
+// LookupContract returns the ContractDefinition node that declares the given node
+func (gst *GlobalSymbolTable) LookupContract(node *ast.Common) *ast.Common {
+	for node != nil && node.NodeType != string(ContractDefinition) {
+		if node.Parent == node {
+			return nil
+		}
+		node = node.Parent
+	}
+	return node
+}
+
+func (gst *GlobalSymbolTable) _indexDeclarations(node *ast.Common) {
+	gst.Declarations[node.ID] = node
+	for _, child := range node.Children {
+		gst._indexDeclarations(child)
+	}
+}
+
+// LinearizedBaseContracts returns the ContractDefinition nodes of the given
+// contract and of its bases, from the most derived to the most base one
+func (gst *GlobalSymbolTable) LinearizedBaseContracts(contractDef *ast.Common) []*ast.Common {
+	var res []*ast.Common
+	for _, id := range contractDef.ASTNode.(*ast.ContractDefinition).LinearizedBaseContracts {
+		if base := gst.LookupDeclaration(id); base != nil {
+			res = append(res, base)
+		} else {
+			logger.Warning.Println("Base contract not found:", id)
+		}
+	}
+	if len(res) == 0 {
+		res = append(res, contractDef)
+	}
+	return res
+}
+
+// _findInheritedStateVariables returns the state variables declared by the bases
+// of the contract, in the namespace of the contract
+func (gst *GlobalSymbolTable) _findInheritedStateVariables(contractDef *ast.Common) []*Symbol {
+	var res []*Symbol
+	contractName := contractDef.ASTNode.(*ast.ContractDefinition).Name
+	for _, base := range gst.LinearizedBaseContracts(contractDef)[1:] {
+		for _, child := range base.Children {
+			if child.NodeType != string(VariableDeclaration) {
+				continue
+			}
+			res = append(res, &Symbol{
+				Namespace:     Namespace{contractName, child.ASTNode.(*ast.VariableDeclaration).Name},
+				Type:          StateVariable,
+				Identifier:    child.ASTNode.(*ast.VariableDeclaration).Name,
+				Arributes:     *child.ASTNode.Attributes(),
+				DeclarationID: child.ID,
+			})
+		}
+	}
+	return res
+}
+
 // This function do NOT check namespace
 func (gst *GlobalSymbolTable) IsExistWithIdentifierOnly(varname string) bool {
 	for _, symbol := range gst.Table {
@@ -106,15 +216,20 @@ func _findContractDefinition(root *ast.Common) []*ast.Common {
 	return res
 }
 
-func _findGlobalSymbols(contractDef *ast.Common) []*Symbol {
+func _findGlobalSymbols(contractDef *ast.Common) ([]*Symbol, error) {
 	var res []*Symbol
 	childs := contractDef.Children
 	for _, child := range childs {
 		if child.NodeType == string(VariableDeclaration) {
+			namespace, err := _findNamespace(child)
+			if err != nil {
+				return nil, err
+			}
 			res = append(res, &Symbol{
-				Namespace: _findNamespace(child),
-				Type:      StateVariable,
-				Arributes: *child.ASTNode.Attributes(),
+				Namespace:     namespace,
+				Type:          StateVariable,
+				Arributes:     *child.ASTNode.Attributes(),
+				DeclarationID: child.ID,
 			})
 		} else if child.NodeType == string(FunctionDefinition) {
 			// whether it is a constructor
@@ -133,10 +248,15 @@ func _findGlobalSymbols(contractDef *ast.Common) []*Symbol {
 				default:
 					functype = Function
 				}
+				namespace, err := _findNamespace(child)
+				if err != nil {
+					return nil, err
+				}
 				res = append(res, &Symbol{
-					Namespace: _findNamespace(child),
-					Type:      functype,
-					Arributes: *child.ASTNode.Attributes(),
+					Namespace:     namespace,
+					Type:          functype,
+					Arributes:     *child.ASTNode.Attributes(),
+					DeclarationID: child.ID,
 				})
 			} else {
 				logger.Warning.Println("")
@@ -144,20 +264,18 @@ func _findGlobalSymbols(contractDef *ast.Common) []*Symbol {
 
 		}
 	}
-	return res
+	return res, nil
 
 }
 
-func _findNamespace(node *ast.Common) Namespace {
+func _findNamespace(node *ast.Common) (Namespace, error) {
 	var res Namespace
 
 	contractName := _findRootContractName(node)
-	if contractName != "" {
-		res = append(res, contractName)
-	} else {
-		logger.Fatal.Println("Error: Contract name not found")
-		panic("Error: Contract name not found")
+	if contractName == "" {
+		return nil, fmt.Errorf("contract name not found for the %s at %s", node.NodeType, node.Src)
 	}
+	res = append(res, contractName)
 
 	if node.NodeType == string(VariableDeclaration) {
 		attr := node.ASTNode.Attributes()
@@ -177,13 +295,19 @@ func _findNamespace(node *ast.Common) Namespace {
 		}
 	}
 
-	return res
+	return res, nil
 }
 
 func _findRootContractName(node *ast.Common) string {
-	for node.NodeType != string(ContractDefinition) {
+	for node != nil && node.NodeType != string(ContractDefinition) {
+		if node.Parent == node {
+			return ""
+		}
 		node = node.Parent
 	}
+	if node == nil {
+		return ""
+	}
 	attr := node.ASTNode.Attributes()
 	if attr != nil {
 		attr := *attr
@@ -0,0 +1,128 @@
+package txtracker
+
+import (
+	"strings"
+	AST "txtracker/internal/ast"
+	"txtracker/internal/detectors"
+	"txtracker/internal/logger"
+)
+
+const DEFAULT_MAX_LENGTH = 2
+
+// Generator builds the candidate transaction sequences of the entry points of a CFG
+type Generator struct {
+	ctx       *detectors.Context
+	maxLength int
+	// transactions per deployed contract, in the order of the entry points
+	contracts []string
+	txs       map[string][]*Tx
+}
+
+func NewGenerator(ctx *detectors.Context, maxLength int) *Generator {
+	if maxLength < 2 {
+		maxLength = DEFAULT_MAX_LENGTH
+	}
+	g := &Generator{
+		ctx:       ctx,
+		maxLength: maxLength,
+		txs:       make(map[string][]*Tx),
+	}
+	g._collectTxs()
+	return g
+}
+
+// Generate returns the sequences of 2 to maxLength transactions in which every
+// transaction depends on a previous one through a state variable write -> read:
+// it reads the variable before assigning it itself. Constructors only run at
+// deployment and are not part of any sequence.
+func (g *Generator) Generate() []*TxSeQuence {
+	var res []*TxSeQuence
+	for _, contract := range g.contracts {
+		for _, tx := range g.txs[contract] {
+			if len(tx.Writes) == 0 {
+				continue
+			}
+			g._extend(contract, []*Tx{tx}, &res)
+		}
+	}
+	logger.Info.Println("Transaction sequences generated:", len(res))
+	return res
+}
+
+// Transactions returns the transactions of the entry points of a deployed contract,
+// with the state variables they read and write. Constructors are left out.
+func (g *Generator) Transactions(contract string) []*Tx {
+	return g.txs[contract]
+}
+
+func (g *Generator) _extend(contract string, seq []*Tx, res *[]*TxSeQuence) {
+	if len(seq) >= 2 {
+		*res = append(*res, _newTxSequence(seq))
+	}
+	if len(seq) == g.maxLength {
+		return
+	}
+
+	written := make(map[string]bool)
+	for _, tx := range seq {
+		for _, w := range tx.Writes {
+			written[w] = true
+		}
+	}
+
+	for _, next := range g.txs[contract] {
+		if _readsAny(next, written) {
+			g._extend(contract, append(seq[:len(seq):len(seq)], next), res)
+		}
+	}
+}
+
+func (g *Generator) _collectTxs() {
+	for _, access := range detectors.NewStateAccesses(g.ctx) {
+		f := access.Function
+		if f.Kind == AST.FunctionKind_Constructor {
+			continue
+		}
+		contract := strings.Split(f.Name, "::")[0]
+		if _, ok := g.txs[contract]; !ok {
+			g.contracts = append(g.contracts, contract)
+		}
+		g.txs[contract] = append(g.txs[contract], _newTx(access))
+	}
+}
+
+func _newTx(access *detectors.StateAccess) *Tx {
+	tx := &Tx{
+		Name:     access.Function.Name,
+		Function: access.Function,
+		Reads:    access.Reads,
+		Inputs:   access.Inputs,
+		Writes:   access.Writes,
+	}
+	for _, block := range access.Function.Blocks {
+		for _, stmt := range block.Statements {
+			tx.Statements = append(tx.Statements, *stmt)
+		}
+	}
+	return tx
+}
+
+func _readsAny(tx *Tx, written map[string]bool) bool {
+	for _, r := range tx.Inputs {
+		if written[r] {
+			return true
+		}
+	}
+	return false
+}
+
+func _newTxSequence(seq []*Tx) *TxSeQuence {
+	s := &TxSeQuence{}
+	var names []string
+	for _, tx := range seq {
+		s.Tx = append(s.Tx, *tx)
+		names = append(names, tx.Name)
+	}
+	s.Name = strings.Join(names, " -> ")
+	return s
+}
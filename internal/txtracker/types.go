@@ -1,7 +1,10 @@
 package txtracker
 
-import CFG "txtracker/pkg/cfg"
+import CFG "txtracker/internal/cfg"
 
+// TxSeQuence is an ordering of transactions on a deployed contract where
+// every transaction after the first one reads a state variable written by
+// a previous one
 type TxSeQuence struct {
 	Name string
 	Tx   []Tx
@@ -10,4 +13,27 @@ type TxSeQuence struct {
 type Tx struct {
 	Name       string
 	Statements []CFG.Statement
+	Function   *CFG.Function
+	Reads      []string // state variables read, in guards included
+	Inputs     []string // state variables read before the transaction assigns them
+	Writes     []string // state variables written, by the internal functions called included
+}
+
+// Dependencies returns the state variables the i-th transaction reads before
+// assigning them that are written by a previous transaction of the sequence
+func (s *TxSeQuence) Dependencies(i int) []string {
+	written := make(map[string]bool)
+	for _, tx := range s.Tx[:i] {
+		for _, w := range tx.Writes {
+			written[w] = true
+		}
+	}
+
+	var res []string
+	for _, r := range s.Tx[i].Inputs {
+		if written[r] {
+			res = append(res, r)
+		}
+	}
+	return res
 }
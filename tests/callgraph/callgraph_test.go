@@ -0,0 +1,120 @@
+package callgraph
+
+import (
+	"testing"
+	"txtracker/internal/callgraph"
+	"txtracker/internal/parser"
+	symboltable "txtracker/internal/symbol_table"
+)
+
+func setupTestEnvironment() *callgraph.CallGraph {
+	testPath := "../parser/test_ast_dataset/0x0a3f9678d6b631386c2dd3de8809b48b0d1bbd56.sol.ast.json"
+	root, err := parser.NewASTParser().ParseAST_JSON(testPath)
+	if err != nil {
+		panic(err)
+	}
+	symbolTable, err := symboltable.NewGlobalSymbolTable(root)
+	if err != nil {
+		panic(err)
+	}
+	return callgraph.NewCallGraph(root, symbolTable)
+}
+
+func findNode(cg *callgraph.CallGraph, name string) *callgraph.Node {
+	for _, node := range cg.Nodes {
+		if node.String() == name {
+			return node
+		}
+	}
+	return nil
+}
+
+func hasEdge(node *callgraph.Node, kind callgraph.CallKind, callee string) bool {
+	for _, edge := range node.Callees {
+		if edge.Kind == kind && edge.CalleeName() == callee {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCallGraph_Edges(t *testing.T) {
+	cg := setupTestEnvironment()
+
+	tests := []struct {
+		caller string
+		kind   callgraph.CallKind
+		callee string
+	}{
+		{"Pausable::pause", callgraph.Modifier, "Ownable::onlyOwner"},
+		{"Token::burn", callgraph.Library, "SafeMath::sub"},
+		{"Token::distribute", callgraph.Internal, "Token::validTransfer"},
+	}
+
+	for _, tt := range tests {
+		caller := findNode(cg, tt.caller)
+		if caller == nil {
+			t.Fatalf("Expected node %s to exist", tt.caller)
+		}
+		if !hasEdge(caller, tt.kind, tt.callee) {
+			t.Errorf("Expected %s edge from %s to %s", tt.kind, tt.caller, tt.callee)
+		}
+	}
+}
+
+func TestCallGraph_Callers(t *testing.T) {
+	cg := setupTestEnvironment()
+
+	onlyOwner := findNode(cg, "Ownable::onlyOwner")
+	if onlyOwner == nil {
+		t.Fatalf("Expected node Ownable::onlyOwner to exist")
+	}
+	if len(onlyOwner.Callers) == 0 {
+		t.Errorf("Expected Ownable::onlyOwner to have callers")
+	}
+
+	for _, edge := range cg.Edges {
+		if edge.Kind == callgraph.Library && edge.Callee.Contract != "SafeMath" {
+			t.Errorf("Expected library calls to target SafeMath, got %s", edge.CalleeName())
+		}
+	}
+}
+
+// Token.sol imports Math and Owned from Base.sol, the call graph of Token.sol
+// resolves them through the symbol table of both units
+func TestCallGraph_ImportedUnits(t *testing.T) {
+	units, err := parser.NewASTParser().ParseSources("test_ast_dataset/Token.sol.ast.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := parser.TargetUnit(units, "Token.sol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbolTable, err := symboltable.NewGlobalSymbolTable(target.Compilation.Roots...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg := callgraph.NewCallGraph(target.Root, symbolTable)
+
+	mint := findNode(cg, "Token::mint")
+	if mint == nil {
+		t.Fatal("Expected node Token::mint to exist")
+	}
+	if !hasEdge(mint, callgraph.Library, "Math::add") {
+		t.Errorf("Expected a library edge from Token::mint to Math::add, got %+v", mint.Callees)
+	}
+	if !hasEdge(mint, callgraph.Modifier, "Owned::onlyOwner") {
+		t.Errorf("Expected a modifier edge from Token::mint to Owned::onlyOwner, got %+v", mint.Callees)
+	}
+
+	// without Base.sol, the call is kept without its callee
+	symbolTable, err = symboltable.NewGlobalSymbolTable(target.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mint = findNode(callgraph.NewCallGraph(target.Root, symbolTable), "Token::mint")
+	if !hasEdge(mint, callgraph.Library, "add") {
+		t.Errorf("Expected an unresolved library edge from Token::mint to add, got %+v", mint.Callees)
+	}
+}
@@ -0,0 +1,231 @@
+package cfg
+
+import (
+	"testing"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/parser"
+	symboltable "txtracker/internal/symbol_table"
+)
+
+func setupTestEnvironment() *CFG.CFG {
+	testPath := "../parser/test_ast_dataset/0x0a3f9678d6b631386c2dd3de8809b48b0d1bbd56.sol.ast.json"
+	root, err := parser.NewASTParser().ParseAST_JSON(testPath)
+	if err != nil {
+		panic(err)
+	}
+	symbolTable, err := symboltable.NewGlobalSymbolTable(root)
+	if err != nil {
+		panic(err)
+	}
+	return CFG.NewCFG(root, symbolTable)
+}
+
+func findFunction(cfg *CFG.CFG, name string) *CFG.Function {
+	for _, f := range cfg.EntryPoints {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func findBlock(f *CFG.Function, label string) *CFG.Block {
+	for _, b := range f.Blocks {
+		if b.Label == label {
+			return b
+		}
+	}
+	return nil
+}
+
+func hasEdge(src, dst *CFG.Block, t CFG.EdgeType) bool {
+	for _, e := range src.SuccessorsEdges {
+		if e.Destination == dst && e.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCFG_EntryExit(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	for _, f := range cfg.EntryPoints {
+		if len(f.Entry.PredecessorsEdges) != 0 {
+			t.Errorf("%s: expected entry block without predecessors", f.Name)
+		}
+		if len(f.Exit.SuccessorsEdges) != 0 {
+			t.Errorf("%s: expected exit block without successors", f.Name)
+		}
+		if len(f.Exit.PredecessorsEdges) == 0 {
+			t.Errorf("%s: expected exit block to be reachable", f.Name)
+		}
+	}
+}
+
+func TestCFG_If(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	f := findFunction(cfg, "LikerCoin::addLockDate")
+	if f == nil {
+		t.Fatal("Expected function LikerCoin::addLockDate to exist")
+	}
+
+	// if (isExists) revert(); else { ... }
+	header := findBlock(f, "for.end")
+	if header == nil || len(header.Successors()) != 2 {
+		t.Fatal("Expected the if statement after the loop to branch")
+	}
+	trueBlock, falseBlock := header.Successors()[0], header.Successors()[1]
+	if !hasEdge(header, trueBlock, CFG.ConditionalTrue) || trueBlock.Label != "if.true" {
+		t.Errorf("Expected a ConditionalTrue edge to if.true, got %s", trueBlock.Label)
+	}
+	if !hasEdge(header, falseBlock, CFG.ConditionalFalse) || falseBlock.Label != "if.false" {
+		t.Errorf("Expected a ConditionalFalse edge to if.false, got %s", falseBlock.Label)
+	}
+	if !hasEdge(trueBlock, f.Exit, CFG.Unconditional) {
+		t.Error("Expected revert to jump to the exit block")
+	}
+}
+
+func TestCFG_ForLoop(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	f := findFunction(cfg, "LikerCoin::setLockUsers")
+	if f == nil {
+		t.Fatal("Expected function LikerCoin::setLockUsers to exist")
+	}
+
+	header, body, next, end := findBlock(f, "for.header"), findBlock(f, "for.body"), findBlock(f, "for.next"), findBlock(f, "for.end")
+	if header == nil || body == nil || next == nil || end == nil {
+		t.Fatal("Expected the for loop blocks to exist")
+	}
+	if !hasEdge(header, body, CFG.ConditionalTrue) {
+		t.Error("Expected a ConditionalTrue edge from for.header to for.body")
+	}
+	if !hasEdge(header, end, CFG.ConditionalFalse) {
+		t.Error("Expected a ConditionalFalse edge from for.header to for.end")
+	}
+	if !hasEdge(next, header, CFG.Unconditional) {
+		t.Error("Expected a back edge from for.next to for.header")
+	}
+}
+
+func TestCFG_Modifiers(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	f := findFunction(cfg, "LikerCoin::pause")
+	if f == nil {
+		t.Fatal("Expected function LikerCoin::pause to exist")
+	}
+
+	if len(f.Modifiers) != 2 || f.Modifiers[0] != "onlyOwner" || f.Modifiers[1] != "whenNotPaused" {
+		t.Fatalf("Expected modifiers [onlyOwner whenNotPaused], got %v", f.Modifiers)
+	}
+
+	// entry -> onlyOwner -> whenNotPaused -> body
+	outer := f.Entry.Successors()[0]
+	if outer.Label != "modifier.onlyOwner" {
+		t.Fatalf("Expected the entry to jump to modifier.onlyOwner, got %s", outer.Label)
+	}
+	inner := outer.Successors()[0]
+	if inner.Label != "modifier.whenNotPaused" {
+		t.Fatalf("Expected onlyOwner to jump to modifier.whenNotPaused, got %s", inner.Label)
+	}
+	body := inner.Successors()[0]
+	if body.Label != "body" {
+		t.Fatalf("Expected whenNotPaused to jump to the body, got %s", body.Label)
+	}
+	if len(outer.Statements) == 0 || outer.Statements[0].Type != CFG.Authorize {
+		t.Error("Expected the onlyOwner guard in the CFG as an Authorize statement")
+	}
+}
+
+func TestCFG_InheritedEntryPoints(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	seen := make(map[string]bool)
+	for _, f := range cfg.EntryPoints {
+		if seen[f.Name] {
+			t.Errorf("Expected overridden functions to appear once, got %s twice", f.Name)
+		}
+		seen[f.Name] = true
+	}
+
+	// inherited from Ownable, Pausable and Token
+	for _, name := range []string{"LikerCoin::transferOwnership", "LikerCoin::pause", "LikerCoin::transfer", "LikerCoin::fallback"} {
+		if !seen[name] {
+			t.Errorf("Expected inherited entry point %s", name)
+		}
+	}
+	// the constructors of the bases are not part of the deployed contract
+	for _, name := range []string{"LikerCoin::Ownable", "LikerCoin::Token"} {
+		if seen[name] {
+			t.Errorf("Expected no entry point %s", name)
+		}
+	}
+	// a concrete base can be deployed on its own
+	for _, name := range []string{"Ownable::Ownable", "Ownable::transferOwnership", "Token::transfer"} {
+		if !seen[name] {
+			t.Errorf("Expected entry point %s of a base contract", name)
+		}
+	}
+}
+
+func TestCFG_Getters(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	f := findFunction(cfg, "LikerCoin::owner")
+	if f == nil {
+		t.Fatal("Expected the getter LikerCoin::owner of a public state variable")
+	}
+	body := findBlock(f, "body")
+	if body == nil || len(body.Statements) != 1 || !hasEdge(f.Entry, body, CFG.Unconditional) || !hasEdge(body, f.Exit, CFG.Unconditional) {
+		t.Fatal("Expected a single block between the entry and the exit")
+	}
+	owner := body.Statements[0].Depends
+	if body.Statements[0].Type != CFG.Return || len(owner) != 1 || owner[0].Type != symboltable.StateVariable || owner[0].Namespace.String() != "LikerCoin::owner" {
+		t.Errorf("Expected the getter to return the state variable LikerCoin::owner, got %+v", owner)
+	}
+}
+
+func TestCFG_InheritedStateVariables(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	f := findFunction(cfg, "LikerCoin::pause")
+	body := findBlock(f, "body")
+	if len(body.Statements) == 0 || len(body.Statements[0].Modify) == 0 {
+		t.Fatal("Expected pause to assign a variable")
+	}
+
+	paused := body.Statements[0].Modify[0]
+	if paused.Type != symboltable.StateVariable || paused.Namespace.String() != "LikerCoin::paused" {
+		t.Errorf("Expected the state variable LikerCoin::paused, got %s %s", paused.Type, paused.Namespace.String())
+	}
+	// the function itself stays in the namespace of the contract defining it
+	if body.Namespace.String() != "Pausable::pause" {
+		t.Errorf("Expected the namespace Pausable::pause, got %s", body.Namespace.String())
+	}
+}
+
+func TestStatementType_Unknown(t *testing.T) {
+	// a statement the CFG does not model is printed, not indexed out of range
+	if s := CFG.Unknown.String(); s != "Unknown" {
+		t.Errorf("expected Unknown, got %q", s)
+	}
+}
+
+func TestStatementToString_Stable(t *testing.T) {
+	stmt := &CFG.Statement{
+		Type:    CFG.FunctionCall,
+		Declare: []symboltable.Symbol{{Identifier: "transfer"}, {Identifier: "token"}},
+	}
+	first := CFG.StatementToString(stmt)
+	if first != "token.transfer()" {
+		t.Errorf("expected token.transfer(), got %q", first)
+	}
+	// the printers render a statement more than once
+	if again := CFG.StatementToString(stmt); again != first {
+		t.Errorf("expected %q on the second render, got %q", first, again)
+	}
+}
@@ -0,0 +1,113 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	CFG "txtracker/internal/cfg"
+	ST "txtracker/internal/symbol_table"
+)
+
+func TestCFG_ExportImport(t *testing.T) {
+	cfg := setupTestEnvironment()
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(cfg.Export("LikerCoin.sol")); err != nil {
+		t.Fatal(err)
+	}
+	exported := buf.String()
+
+	docs, err := CFG.ReadDocuments(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Source != "LikerCoin.sol" || docs[0].Version != CFG.SchemaVersion {
+		t.Fatalf("Expected a single document of LikerCoin.sol, got %+v", docs)
+	}
+	imported, err := CFG.Import(docs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var again bytes.Buffer
+	if err := json.NewEncoder(&again).Encode(imported.Export("LikerCoin.sol")); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != exported {
+		t.Error("Expected the export of the imported CFG to match the original export")
+	}
+
+	if len(imported.EntryPoints) != len(cfg.EntryPoints) || len(imported.Blocks) != len(cfg.Blocks) {
+		t.Fatalf("Expected %d entry points and %d blocks, got %d and %d",
+			len(cfg.EntryPoints), len(cfg.Blocks), len(imported.EntryPoints), len(imported.Blocks))
+	}
+	f := findFunction(imported, "LikerCoin::addLockDate")
+	if f == nil {
+		t.Fatal("Expected function LikerCoin::addLockDate to exist")
+	}
+	header := findBlock(f, "for.end")
+	if header == nil || len(header.Successors()) != 2 {
+		t.Fatal("Expected the if statement after the loop to branch")
+	}
+	if !hasEdge(header, header.Successors()[0], CFG.ConditionalTrue) || header.Successors()[0].Label != "if.true" {
+		t.Error("Expected the ConditionalTrue edge to if.true to be rebuilt")
+	}
+	if len(f.Exit.PredecessorsEdges) == 0 || f.Exit.PredecessorsEdges[0].Destination != f.Exit {
+		t.Error("Expected the predecessors of the exit block to be rebuilt")
+	}
+
+	// the statements keep their node and symbols
+	original := findFunction(cfg, "LikerCoin::addLockDate")
+	for i, b := range f.Blocks {
+		for j, s := range b.Statements {
+			o := original.Blocks[i].Statements[j]
+			if s.Type != o.Type || s.ASTNode.ID != o.ASTNode.ID || s.ASTNode.Src != o.ASTNode.Src {
+				t.Errorf("B%d statement %d: expected %s node %d, got %s node %d", b.ID, j, o.Type, o.ASTNode.ID, s.Type, s.ASTNode.ID)
+			}
+			if CFG.StatementToString(s) != CFG.StatementToString(o) {
+				t.Errorf("B%d statement %d: expected %q, got %q", b.ID, j, CFG.StatementToString(o), CFG.StatementToString(s))
+			}
+		}
+	}
+}
+
+func TestCFG_ExportStateVariable(t *testing.T) {
+	doc := setupTestEnvironment().Export("")
+
+	for _, b := range doc.Blocks {
+		for _, s := range b.Statements {
+			for _, m := range s.Modify {
+				if m.Type == ST.StateVariable.String() && m.DeclarationID != 0 {
+					return
+				}
+			}
+		}
+	}
+	t.Error("Expected a write to a state variable with its declaration ID")
+}
+
+func TestCFG_ImportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{"schema", `{"schema": "other", "version": 1}`, "not a CFG export"},
+		{"version", `{"schema": "txtracker/cfg", "version": 99}`, "unsupported CFG export version 99"},
+		{"edge", `{"schema": "txtracker/cfg", "version": 1, "blocks": [{"id": 0}], "edges": [{"source": 0, "destination": 3, "type": "Unconditional"}]}`, "edge 0: unknown block 3"},
+		{"statement", `{"schema": "txtracker/cfg", "version": 1, "blocks": [{"id": 0, "statements": [{"type": "Goto"}]}]}`, "block 0: unknown statement type \"Goto\""},
+		{"entry", `{"schema": "txtracker/cfg", "version": 1, "entryPoints": [{"name": "A::f", "entry": 1}]}`, "A::f: unknown block 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := CFG.ReadDocuments(strings.NewReader(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := CFG.Import(docs[0]); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+	"txtracker/internal/ast"
+)
+
+// the node types of the compact AST of solc 0.4.12 to 0.8.x
+var nodeTypes = []string{
+	"SourceUnit", "PragmaDirective", "ImportDirective", "ContractDefinition", "InheritanceSpecifier",
+	"UsingForDirective", "StructDefinition", "EnumDefinition", "EnumValue", "UserDefinedValueTypeDefinition",
+	"ParameterList", "OverrideSpecifier", "FunctionDefinition", "VariableDeclaration", "ModifierDefinition",
+	"ModifierInvocation", "EventDefinition", "ErrorDefinition", "StructuredDocumentation", "IdentifierPath",
+	// TypeNames
+	"ElementaryTypeName", "UserDefinedTypeName", "FunctionTypeName", "Mapping", "ArrayTypeName",
+	// Statements
+	"Block", "UncheckedBlock", "PlaceholderStatement", "IfStatement", "TryStatement", "TryCatchClause",
+	"WhileStatement", "DoWhileStatement", "ForStatement", "Continue", "Break", "Return", "Throw",
+	"EmitStatement", "RevertStatement", "VariableDeclarationStatement", "ExpressionStatement", "InlineAssembly",
+	// Expressions
+	"Conditional", "Assignment", "TupleExpression", "UnaryOperation", "BinaryOperation", "FunctionCall",
+	"FunctionCallOptions", "NewExpression", "MemberAccess", "IndexAccess", "IndexRangeAccess",
+	"ElementaryTypeNameExpression", "Identifier", "Literal",
+}
+
+func TestNodeFactoryCoverage(t *testing.T) {
+	for _, nodeType := range nodeTypes {
+		t.Run(nodeType, func(t *testing.T) {
+			data := map[string]interface{}{"id": 1.0, "nodeType": nodeType, "src": "0:0:0"}
+			node := ast.NodeFactory(data)
+			if _, ok := node.ASTNode.(*ast.GenericNode); ok || node.ASTNode == nil {
+				t.Fatalf("Expected a typed node for %s", nodeType)
+			}
+			node.ASTNode.Constructor(&data)
+			if node.ASTNode.Attributes() == nil {
+				t.Errorf("Expected the attributes of %s", nodeType)
+			}
+		})
+	}
+}
+
+func TestNodeFactoryGenericNode(t *testing.T) {
+	data := map[string]interface{}{"id": 1.0, "nodeType": "YetUnknownStatement", "src": "0:0:0", "body": "kept"}
+	node := ast.NodeFactory(data)
+	node.ASTNode.Constructor(&data)
+
+	generic, ok := node.ASTNode.(*ast.GenericNode)
+	if !ok || node.NodeType != "YetUnknownStatement" || generic.Data["body"] != "kept" {
+		t.Errorf("Expected a GenericNode keeping the JSON object, got %+v", node.ASTNode)
+	}
+}
+
+func TestNodeFactoryNodeError(t *testing.T) {
+	defer func() {
+		err, ok := recover().(*ast.NodeError)
+		if !ok || err.Field != "src" || err.Error() != "Identifier node without a valid src" {
+			t.Errorf("Expected a NodeError on the missing src, got %v", err)
+		}
+	}()
+	ast.NodeFactory(map[string]interface{}{"id": 1.0, "nodeType": "Identifier"})
+}
+
+func identifier(id float64, name string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "name": name, "nodeType": "Identifier", "src": "0:0:0"}
+}
+
+func TestEmitStatementConstructor(t *testing.T) {
+	data := map[string]interface{}{
+		"eventCall": map[string]interface{}{
+			"arguments":  []interface{}{identifier(3, "to")},
+			"expression": identifier(2, "Transfer"),
+			"id":         1.0,
+			"kind":       "functionCall",
+			"names":      []interface{}{},
+			"nodeType":   "FunctionCall",
+			"src":        "0:0:0",
+		},
+	}
+
+	var emit ast.EmitStatement
+	emit.Constructor(&data)
+
+	call, ok := emit.EventCall.ASTNode.(*ast.FunctionCall)
+	if !ok || call.Expression.ASTNode.(*ast.Identifier).Name != "Transfer" || len(call.Arguments) != 1 {
+		t.Errorf("Expected the call of Transfer, got %+v", emit.EventCall)
+	}
+}
+
+// msg.sender.call{value: amount}("")
+func TestFunctionCallOptionsConstructor(t *testing.T) {
+	data := map[string]interface{}{
+		"expression": map[string]interface{}{
+			"expression": identifier(3, "msg"),
+			"id":         2.0,
+			"memberName": "call",
+			"nodeType":   "MemberAccess",
+			"src":        "0:0:0",
+		},
+		"names":   []interface{}{"value"},
+		"options": []interface{}{identifier(4, "amount")},
+	}
+
+	var options ast.FunctionCallOptions
+	options.Constructor(&data)
+
+	if !reflect.DeepEqual(options.Names, []string{"value"}) || len(options.Options) != 1 {
+		t.Fatalf("Expected a value option, got names %v options %v", options.Names, options.Options)
+	}
+	if member, ok := options.Expression.ASTNode.(*ast.MemberAccess); !ok || member.MemberName != "call" {
+		t.Errorf("Expected the options of call, got %+v", options.Expression)
+	}
+}
+
+func TestInlineAssemblyConstructor(t *testing.T) {
+	// solc >= 0.6
+	data := map[string]interface{}{
+		"AST":        map[string]interface{}{"nodeType": "YulBlock", "src": "0:0:0", "statements": []interface{}{}},
+		"evmVersion": "paris",
+		"externalReferences": []interface{}{
+			map[string]interface{}{"declaration": 7.0, "isOffset": false, "isSlot": true, "src": "10:10:0", "suffix": "slot", "valueSize": 1.0},
+		},
+		"flags": []interface{}{"memory-safe"},
+	}
+	var assembly ast.InlineAssembly
+	assembly.Constructor(&data)
+
+	if assembly.AST == nil || assembly.AST.NodeType != "YulBlock" || assembly.EvmVersion != "paris" || !reflect.DeepEqual(assembly.Flags, []string{"memory-safe"}) {
+		t.Errorf("Unexpected assembly %+v", assembly)
+	}
+	want := []ast.InlineAssemblyReference{{Declaration: 7, IsSlot: true, Src: "10:10:0", Suffix: "slot", ValueSize: 1}}
+	if !reflect.DeepEqual(assembly.ExternalReferences, want) {
+		t.Errorf("ExternalReferences = %+v, want %+v", assembly.ExternalReferences, want)
+	}
+
+	// solc < 0.6 keys the references by name
+	data = map[string]interface{}{
+		"externalReferences": []interface{}{
+			map[string]interface{}{"x": map[string]interface{}{"declaration": 5.0, "isOffset": false, "isSlot": false, "src": "3:1:0", "valueSize": 1.0}},
+		},
+		"operations": "{\n    x := sload(0)\n}",
+	}
+	assembly = ast.InlineAssembly{}
+	assembly.Constructor(&data)
+
+	want = []ast.InlineAssemblyReference{{Name: "x", Declaration: 5, Src: "3:1:0", ValueSize: 1}}
+	if !reflect.DeepEqual(assembly.ExternalReferences, want) || assembly.Operations == "" {
+		t.Errorf("ExternalReferences = %+v, want %+v", assembly.ExternalReferences, want)
+	}
+	// the operations are parsed, x refers to its declaration
+	block := assembly.AST.ASTNode.(*ast.YulBlock)
+	assignment := block.Statements[0].ASTNode.(*ast.YulAssignment)
+	if x := assignment.VariableNames[0].ASTNode.(*ast.YulIdentifier); x.Name != "x" || x.Declaration != 5 {
+		t.Errorf("Expected x to refer to the declaration 5, got %+v", x)
+	}
+}
+
+// import {Ownable as Owned} from "./Ownable.sol";
+func TestImportDirectiveConstructor(t *testing.T) {
+	data := map[string]interface{}{
+		"absolutePath": "contracts/Ownable.sol",
+		"file":         "./Ownable.sol",
+		"sourceUnit":   12.0,
+		"symbolAliases": []interface{}{
+			map[string]interface{}{"foreign": identifier(3, "Ownable"), "local": "Owned"},
+		},
+		"unitAlias": "",
+	}
+
+	var directive ast.ImportDirective
+	directive.Constructor(&data)
+
+	if directive.File != "./Ownable.sol" || directive.SourceUnit != 12 || len(directive.SymbolAliases) != 1 {
+		t.Fatalf("Unexpected import %+v", directive)
+	}
+	if alias := directive.SymbolAliases[0]; alias.Foreign.Name != "Ownable" || alias.Local != "Owned" {
+		t.Errorf("Expected Ownable as Owned, got %+v", alias)
+	}
+}
+
+func TestIdentifierPathNameLocations(t *testing.T) {
+	data := map[string]interface{}{
+		"name":                  "Lib.Point",
+		"nameLocations":         []interface{}{"10:3:0", "14:5:0"},
+		"referencedDeclaration": 8.0,
+	}
+
+	var path ast.IdentifierPath
+	path.Constructor(&data)
+
+	if !reflect.DeepEqual([]string(path.NameLocations), []string{"10:3:0", "14:5:0"}) || path.ReferencedDeclaration != 8 {
+		t.Errorf("Unexpected path %+v", path)
+	}
+}
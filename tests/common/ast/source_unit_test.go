@@ -3,21 +3,22 @@ package ast
 import (
 	"reflect"
 	"testing"
-	"txtracker/pkg/ast"
+	"txtracker/internal/ast"
 )
 
 func TestSourceUnitConstructor(t *testing.T) {
 	data := map[string]interface{}{
 		"absolutePath":         "../../dataset/contracts/0x0a3f9678d6b631386c2dd3de8809b48b0d1bbd56.sol",
 		"experimentalSolidity": true,
-		"exportedSymbols": map[string][]float64{
-			"ERC20":       {275},
-			"LikerCoin":   {1738},
-			"LockBalance": {1554},
-			"Ownable":     {151},
-			"Pausable":    {208},
-			"SafeMath":    {97},
-			"Token":       {847},
+		// encoding/json decodes the arrays of IDs as []interface{} of float64
+		"exportedSymbols": map[string]interface{}{
+			"ERC20":       []interface{}{275.0},
+			"LikerCoin":   []interface{}{1738.0},
+			"LockBalance": []interface{}{1554.0},
+			"Ownable":     []interface{}{151.0},
+			"Pausable":    []interface{}{208.0},
+			"SafeMath":    []interface{}{97.0},
+			"Token":       []interface{}{847.0},
 		},
 		"license": "MIT",
 	}
@@ -66,7 +67,7 @@ func TestSourceUnitAttributes(t *testing.T) {
 	expectedAttrs := map[string]interface{}{
 		"AbsolutePath":         "../../dataset/contracts/0x0a3f9678d6b631386c2dd3de8809b48b0d1bbd56.sol",
 		"ExperimentalSolidity": true,
-		"ExportedSymbols": map[string][]int{
+		"ExportedSymbols": ast.ExportedSymbols{
 			"ERC20":       []int{275},
 			"LikerCoin":   []int{1738},
 			"LockBalance": []int{1554},
@@ -82,3 +83,26 @@ func TestSourceUnitAttributes(t *testing.T) {
 		t.Errorf("Attributes() = %v, want %v", *attrs, expectedAttrs)
 	}
 }
+
+func TestPragmaDirectiveVersionRange(t *testing.T) {
+	tests := []struct {
+		literals []interface{}
+		expected string
+		ok       bool
+	}{
+		{[]interface{}{"solidity", "^", "0.4", ".24"}, "^0.4.24", true},
+		{[]interface{}{"solidity", ">=", "0.4", ".22", "<", "0.9", ".0"}, ">=0.4.22 <0.9.0", true},
+		{[]interface{}{"solidity", "0.4", ".0", "-", "0.5", ".0", "||", "^", "0.8", ".0"}, "0.4.0 - 0.5.0 || ^0.8.0", true},
+		{[]interface{}{"experimental", "ABIEncoderV2"}, "", false},
+	}
+
+	for _, tt := range tests {
+		data := map[string]interface{}{"literals": tt.literals}
+		var p ast.PragmaDirective
+		p.Constructor(&data)
+
+		if got, ok := p.VersionRange(); got != tt.expected || ok != tt.ok {
+			t.Errorf("VersionRange() of %v = %q, %v, want %q, %v", tt.literals, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
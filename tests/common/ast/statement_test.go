@@ -0,0 +1,51 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+	"txtracker/internal/ast"
+)
+
+// (bool success, ) = target.call(data) leaves the second component out
+func TestVariableDeclarationStatementOmittedComponent(t *testing.T) {
+	data := map[string]interface{}{
+		"assignments": []interface{}{12.0, nil},
+		"declarations": []interface{}{
+			map[string]interface{}{"id": 12.0, "name": "success", "nodeType": "VariableDeclaration", "src": "81:12:0"},
+			nil,
+		},
+	}
+
+	var stmt ast.VariableDeclarationStatement
+	stmt.Constructor(&data)
+
+	if !reflect.DeepEqual(stmt.Assignments, []int{12, 0}) {
+		t.Errorf("Assignments = %v, want [12 0]", stmt.Assignments)
+	}
+	if len(stmt.Declarations) != 2 || stmt.Declarations[0] == nil || stmt.Declarations[1] != nil {
+		t.Fatalf("Expected the declaration of success then nil, got %v", stmt.Declarations)
+	}
+	if names, _ := stmt.GetDeclarations(); !reflect.DeepEqual(names, []string{"success"}) {
+		t.Errorf("GetDeclarations() = %v, want [success]", names)
+	}
+}
+
+// (success, ) = target.call(data)
+func TestTupleExpressionOmittedComponent(t *testing.T) {
+	data := map[string]interface{}{
+		"components": []interface{}{
+			map[string]interface{}{"id": 20.0, "name": "success", "nodeType": "Identifier", "referencedDeclaration": 12.0, "src": "101:7:0"},
+			nil,
+		},
+	}
+
+	var tuple ast.TupleExpression
+	tuple.Constructor(&data)
+
+	if len(tuple.Components) != 2 || tuple.Components[1] != nil {
+		t.Fatalf("Expected 2 components, the second one nil, got %v", tuple.Components)
+	}
+	if ident, ok := tuple.Components[0].ASTNode.(*ast.Identifier); !ok || ident.Name != "success" {
+		t.Errorf("Unexpected first component %v", tuple.Components[0].ASTNode)
+	}
+}
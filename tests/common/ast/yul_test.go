@@ -0,0 +1,146 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+	"txtracker/internal/ast"
+)
+
+func yulIdentifier(name, src string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "nodeType": "YulIdentifier", "src": src}
+}
+
+func yulCall(name string, args ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"arguments":    args,
+		"functionName": yulIdentifier(name, "0:0:0"),
+		"nodeType":     "YulFunctionCall",
+		"src":          "0:0:0",
+	}
+}
+
+//	assembly {
+//	    for { let i := 0 } lt(i, n) { i := add(i, 1) } {
+//	        sstore(add(data.slot, i), 0)
+//	    }
+//	}
+func TestInlineAssemblyYulAST(t *testing.T) {
+	i := map[string]interface{}{"name": "i", "nodeType": "YulIdentifier", "src": "0:0:0"}
+	zero := map[string]interface{}{"kind": "number", "nodeType": "YulLiteral", "src": "0:0:0", "type": "", "value": "0"}
+	data := map[string]interface{}{
+		"AST": map[string]interface{}{
+			"nodeType": "YulBlock", "src": "9:80:0",
+			"statements": []interface{}{map[string]interface{}{
+				"nodeType": "YulForLoop", "src": "15:70:0",
+				"pre": map[string]interface{}{"nodeType": "YulBlock", "src": "19:16:0", "statements": []interface{}{
+					map[string]interface{}{"nodeType": "YulVariableDeclaration", "src": "21:10:0", "value": zero,
+						"variables": []interface{}{map[string]interface{}{"name": "i", "nodeType": "YulTypedName", "src": "25:1:0", "type": ""}}},
+				}},
+				"condition": yulCall("lt", i, yulIdentifier("n", "39:1:0")),
+				"post": map[string]interface{}{"nodeType": "YulBlock", "src": "42:20:0", "statements": []interface{}{
+					map[string]interface{}{"nodeType": "YulAssignment", "src": "44:14:0", "value": yulCall("add", i, zero),
+						"variableNames": []interface{}{i}},
+				}},
+				"body": map[string]interface{}{"nodeType": "YulBlock", "src": "63:22:0", "statements": []interface{}{
+					map[string]interface{}{"nodeType": "YulExpressionStatement", "src": "65:18:0",
+						"expression": yulCall("sstore", yulCall("add", yulIdentifier("data.slot", "76:9:0"), i), zero)},
+				}},
+			}},
+		},
+		"externalReferences": []interface{}{
+			map[string]interface{}{"declaration": 3.0, "isOffset": false, "isSlot": true, "src": "76:9:0", "suffix": "slot", "valueSize": 1.0},
+			map[string]interface{}{"declaration": 8.0, "isOffset": false, "isSlot": false, "src": "39:1:0", "valueSize": 1.0},
+		},
+		"id": 20.0, "nodeType": "InlineAssembly", "src": "0:89:0",
+	}
+	node := ast.NodeFactory(data)
+	node.ASTNode.Constructor(&data)
+
+	assembly := node.ASTNode.(*ast.InlineAssembly)
+	block := assembly.AST.ASTNode.(*ast.YulBlock)
+	loop, ok := block.Statements[0].ASTNode.(*ast.YulForLoop)
+	if !ok || loop.Pre.NodeType != "YulBlock" || loop.Post.NodeType != "YulBlock" || loop.Body.NodeType != "YulBlock" {
+		t.Fatalf("Expected a for loop, got %+v", block.Statements[0].ASTNode)
+	}
+	if decl := loop.Pre.ASTNode.(*ast.YulBlock).Statements[0].ASTNode.(*ast.YulVariableDeclaration); decl.Variables[0].Name != "i" || decl.Value.NodeType != "YulLiteral" {
+		t.Errorf("Expected let i := 0, got %+v", decl)
+	}
+
+	// the references are located by src
+	if n := loop.Condition.ASTNode.(*ast.YulFunctionCall).Arguments[1].ASTNode.(*ast.YulIdentifier); n.Declaration != 8 || n.Suffix != "" {
+		t.Errorf("Expected n to refer to the declaration 8, got %+v", n)
+	}
+	sstore := loop.Body.ASTNode.(*ast.YulBlock).Statements[0].ASTNode.(*ast.YulExpressionStatement).Expression
+	slots := ast.YulSlots(sstore.ASTNode.(*ast.YulFunctionCall).Arguments[0])
+	if len(slots) != 1 || slots[0].ASTNode.(*ast.YulIdentifier).VariableName() != "data" || slots[0].ASTNode.(*ast.YulIdentifier).Declaration != 3 {
+		t.Errorf("Expected the slot of data, got %+v", slots)
+	}
+	if source := ast.YulSource(sstore); source != "sstore(add(data.slot, i), 0)" {
+		t.Errorf("Unexpected source %s", source)
+	}
+
+	// Inspect descends into the assembly
+	var calls []string
+	ast.Inspect(node, func(n *ast.Common) bool {
+		if call, ok := n.ASTNode.(*ast.YulFunctionCall); ok {
+			calls = append(calls, call.FunctionName.Name)
+		}
+		return true
+	})
+	if strings.Join(calls, " ") != "lt add sstore add" {
+		t.Errorf("Expected the calls of the loop in order, got %v", calls)
+	}
+}
+
+func TestParseYul(t *testing.T) {
+	operations := `{
+    // the slot of x
+    let v := sload(x_slot)
+    switch v
+    case 0 { v := 1 }
+    default { sstore(x_slot, add(v, 0x01)) }
+    function f(a, b:u256) -> c { c := "ab" leave }
+    for { } 1 { } { if eq(v, true) { break } continue }
+}`
+	block, err := ast.ParseYul(operations, "5:120:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statements := block.ASTNode.(*ast.YulBlock).Statements
+	var types []string
+	for _, stmt := range statements {
+		if stmt.Src != "5:120:0" {
+			t.Errorf("Expected the location of the block, got %s", stmt.Src)
+		}
+		types = append(types, stmt.NodeType)
+	}
+	if want := "YulVariableDeclaration YulSwitch YulFunctionDefinition YulForLoop"; strings.Join(types, " ") != want {
+		t.Fatalf("Expected %s, got %v", want, types)
+	}
+
+	cases := statements[1].ASTNode.(*ast.YulSwitch).Cases
+	if len(cases) != 2 || cases[0].ASTNode.(*ast.YulCase).Value == nil || cases[1].ASTNode.(*ast.YulCase).Value != nil {
+		t.Errorf("Expected a case and a default, got %+v", cases)
+	}
+	f := statements[2].ASTNode.(*ast.YulFunctionDefinition)
+	if f.Name != "f" || len(f.Parameters) != 2 || f.Parameters[1].Type != "u256" || f.ReturnVariables[0].Name != "c" {
+		t.Errorf("Unexpected function %+v", f)
+	}
+	literal := f.Body.ASTNode.(*ast.YulBlock).Statements[0].ASTNode.(*ast.YulAssignment).Value.ASTNode.(*ast.YulLiteral)
+	if literal.Kind != "string" || literal.Value != "ab" || literal.HexValue != "6162" {
+		t.Errorf("Unexpected literal %+v", literal)
+	}
+
+	for name, source := range map[string]string{
+		"unterminated":   "{ sstore(0, 1)",
+		"stack":          "{ 1 =: x }",
+		"label":          "{ tag: jump(tag) }",
+		"case":           "{ switch x case y { } }",
+		"after":          "{ } }",
+		"assign to call": "{ f() := 1 }",
+	} {
+		if _, err := ast.ParseYul(source, "0:0:0"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
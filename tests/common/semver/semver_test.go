@@ -0,0 +1,94 @@
+package semver
+
+import (
+	"testing"
+	"txtracker/internal/common/semver"
+)
+
+func TestConstraint_Check(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"^0.4.18", "0.4.18", true},
+		{"^0.4.18", "0.4.26", true},
+		{"^0.4.18", "0.4.17", false},
+		{"^0.4.18", "0.5.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"^1.2.3", "1.9.0", true},
+		{"~0.5.2", "0.5.9", true},
+		{"~0.5.2", "0.6.0", false},
+		{">=0.4.22 <0.6.0", "0.5.17", true},
+		{">=0.4.22 <0.6.0", "0.6.0", false},
+		{">= 0.4.22 < 0.6.0", "0.4.21", false},
+		{"0.8.19", "0.8.19", true},
+		{"=0.8.19", "0.8.20", false},
+		{"0.8", "0.8.25", true},
+		{">0.4", "0.4.26", false},
+		{"<=0.4", "0.4.26", true},
+		{"0.4.0 - 0.5", "0.5.17", true},
+		{"0.4.0 - 0.5.0", "0.5.1", false},
+		{"^0.4.24 || ^0.8.0", "0.8.4", true},
+		{"^0.4.24 || ^0.8.0", "0.6.12", false},
+		{"*", "0.7.6", true},
+	}
+
+	for _, tt := range tests {
+		c, err := semver.ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		v, err := semver.Parse(tt.version)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.version, err)
+		}
+		if got := c.Check(v); got != tt.expected {
+			t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.expected)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, s := range []string{"", "0.8", "a.b.c", "0.8.x"} {
+		if _, err := semver.Parse(s); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", s)
+		}
+	}
+	if _, err := semver.ParseConstraint(">=0.4.22 <foo"); err == nil {
+		t.Error("Expected an invalid constraint to fail")
+	}
+}
+
+func TestConstraint_Min(t *testing.T) {
+	tests := []struct {
+		constraint string
+		expected   string // empty if no version satisfies the constraint
+	}{
+		{"^0.4.24", "0.4.24"},
+		{">=0.4.22 <0.9.0", "0.4.22"},
+		{">0.7.6", "0.7.7"},
+		{">0.7", "0.8.0"},
+		{"<0.5.0", "0.0.0"},
+		{"0.8", "0.8.0"},
+		{"^0.8.0 || ^0.6.12", "0.6.12"},
+		{">=0.6.0 <0.5.0", ""},
+	}
+
+	for _, tt := range tests {
+		c, err := semver.ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		min, ok := c.Min()
+		if tt.expected == "" {
+			if ok {
+				t.Errorf("%q.Min() = %s, want none", tt.constraint, min)
+			}
+			continue
+		}
+		if !ok || min.String() != tt.expected {
+			t.Errorf("%q.Min() = %s, %v, want %s", tt.constraint, min, ok, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"txtracker/internal/compiler"
+)
+
+// setupTestEnvironment creates a solc directory in the solc-select and svm layouts
+func setupTestEnvironment(t *testing.T) string {
+	dir := t.TempDir()
+	for _, path := range []string{
+		"solc-0.4.26/solc-0.4.26",
+		"solc-0.5.17/solc-0.5.17",
+		"0.8.19/solc-0.8.19",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// not executable
+	if err := os.WriteFile(filepath.Join(dir, "solc-0.7.6"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestSolcManager_Select(t *testing.T) {
+	manager := compiler.NewSolcManager(setupTestEnvironment(t))
+
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"pragma solidity ^0.4.18;", "0.4.26"},
+		{"pragma solidity >=0.4.22 <0.6.0;", "0.5.17"},
+		{"// pragma solidity ^0.4.18;\npragma solidity ^0.8.0;", "0.8.19"},
+		{"contract A {}", "0.8.19"},
+	}
+
+	for _, tt := range tests {
+		constraints, err := compiler.PragmaConstraints(tt.source)
+		if err != nil {
+			t.Fatalf("PragmaConstraints(%q): %v", tt.source, err)
+		}
+		solc, err := manager.Select(constraints)
+		if err != nil {
+			t.Fatalf("Select(%q): %v", tt.source, err)
+		}
+		if solc.Version.String() != tt.expected {
+			t.Errorf("Select(%q) = %s, want %s", tt.source, solc.Version.String(), tt.expected)
+		}
+	}
+}
+
+func TestSolcManager_NoMatch(t *testing.T) {
+	manager := compiler.NewSolcManager(setupTestEnvironment(t))
+
+	// 0.7.6 is not executable
+	constraints, _ := compiler.PragmaConstraints("pragma solidity ^0.7.0;")
+	_, err := manager.Select(constraints)
+	if err == nil {
+		t.Fatal("Expected an error when no installed version matches")
+	}
+	if !strings.Contains(err.Error(), "^0.7.0") || !strings.Contains(err.Error(), "0.4.26, 0.5.17, 0.8.19") {
+		t.Errorf("Expected the pragma and the installed versions in the error, got %v", err)
+	}
+}
+
+// solc < 0.4.12 has no --ast-compact-json, the legacy --ast-json is used instead
+func TestSolidityCompiler_ASTFormat(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"0.4.11", "0.4.26"} {
+		// prints its arguments as the AST
+		if err := os.WriteFile(filepath.Join(dir, "solc-"+version), []byte("#!/bin/sh\necho \"$@\"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := compiler.NewSolidityCompiler(dir)
+
+	for pragma, flag := range map[string]string{
+		"pragma solidity 0.4.11;":  "--ast-json",
+		"pragma solidity ^0.4.12;": "--ast-compact-json",
+	} {
+		path := filepath.Join(t.TempDir(), "A.sol")
+		if err := os.WriteFile(path, []byte(pragma+"\ncontract A {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SolidityToAST_JSON(path); err != nil {
+			t.Fatal(err)
+		}
+		ast, err := os.ReadFile(path + ".ast.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(ast), flag+" ") {
+			t.Errorf("Expected %s to be compiled with %s, got %q", pragma, flag, ast)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"txtracker/internal/compiler"
+	"txtracker/internal/parser"
+)
+
+// writeFiles creates the files under dir, the keys are slash separated paths
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// setupProject creates a project importing a library through a remapping
+func setupProject(t *testing.T) string {
+	project := t.TempDir()
+	writeFiles(t, project, map[string]string{
+		"remappings.txt": "@oz/=lib/openzeppelin/contracts/\n",
+		"src/Token.sol": `pragma solidity ^0.8.0;
+import "./utils/Math.sol";
+import {Ownable} from "@oz/access/Ownable.sol";
+// import "./Missing.sol";
+contract Token is Ownable {}
+`,
+		"src/utils/Math.sol":                            "pragma solidity ^0.8.0;\nlibrary Math {}\n",
+		"lib/openzeppelin/contracts/access/Ownable.sol": "pragma solidity >=0.8.0;\nimport '../utils/Context.sol';\ncontract Ownable is Context {}\n",
+		"lib/openzeppelin/contracts/utils/Context.sol":  "pragma solidity >=0.6.0;\ncontract Context {}\n",
+	})
+	return project
+}
+
+// setupFakeSolc creates a solc 0.8.19 printing the given standard JSON output
+func setupFakeSolc(t *testing.T, output string) string {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"output.json": output})
+	script := "#!/bin/sh\ncat > " + filepath.Join(dir, "input.json") + "\ncat " + filepath.Join(dir, "output.json") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "solc-0.8.19"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStandardJSONCompiler_BuildInput(t *testing.T) {
+	project := setupProject(t)
+	c := compiler.NewStandardJSONCompiler(t.TempDir(), project, nil)
+
+	input, err := c.BuildInput(filepath.Join(project, "src", "Token.sol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Remappings) != 1 || c.Remappings[0] != "@oz/=lib/openzeppelin/contracts/" {
+		t.Errorf("remappings.txt not loaded: %v", c.Remappings)
+	}
+	expected := []string{
+		"src/Token.sol",
+		"src/utils/Math.sol",
+		"lib/openzeppelin/contracts/access/Ownable.sol",
+		"lib/openzeppelin/contracts/utils/Context.sol",
+	}
+	if len(input.Sources) != len(expected) {
+		t.Errorf("expected %d sources, got %d", len(expected), len(input.Sources))
+	}
+	for _, name := range expected {
+		if _, ok := input.Sources[name]; !ok {
+			t.Errorf("source %s not found", name)
+		}
+	}
+	if selection := input.Settings.OutputSelection["*"]; len(selection[""]) != 1 || selection[""][0] != "ast" {
+		t.Errorf("AST not requested: %v", selection)
+	}
+}
+
+func TestStandardJSONCompiler_MissingImport(t *testing.T) {
+	project := t.TempDir()
+	writeFiles(t, project, map[string]string{
+		"A.sol": "pragma solidity ^0.8.0;\nimport \"./B.sol\";\n",
+	})
+	c := compiler.NewStandardJSONCompiler(t.TempDir(), project, nil)
+
+	_, err := c.BuildInput(filepath.Join(project, "A.sol"))
+	if err == nil || !strings.Contains(err.Error(), "B.sol") {
+		t.Errorf("expected a missing source error for B.sol, got %v", err)
+	}
+}
+
+func TestStandardJSONCompiler_Compile(t *testing.T) {
+	project := setupProject(t)
+	solcDir := setupFakeSolc(t, `{
+  "errors": [{"severity": "warning", "type": "Warning", "message": "Unused variable.", "formattedMessage": "Warning: Unused variable."}],
+  "sources": {
+    "src/utils/Math.sol": {"id": 1, "ast": {"nodeType": "SourceUnit", "id": 2, "src": "0:0:1", "nodes": []}},
+    "src/Token.sol": {"id": 0, "ast": {"nodeType": "SourceUnit", "id": 1, "src": "0:0:0", "nodes": []}}
+  },
+  "contracts": {"src/Token.sol": {"Token": {
+    "abi": [],
+    "evm": {"bytecode": {"object": "6080", "sourceMap": "1:2:0"}, "deployedBytecode": {"object": "6081", "sourceMap": "3:4:0"}}
+  }}}
+}`)
+	c := compiler.NewStandardJSONCompiler(solcDir, project, nil)
+	c.Optimizer = compiler.OptimizerSettings{Enabled: true, Runs: 1000}
+
+	path := filepath.Join(project, "src", "Token.sol")
+	if err := c.SolidityToAST_JSON(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// every source unit of the compilation, by source index
+	units, err := parser.NewASTParser().ParseSources(path + ".ast.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) != 2 || units[0].Path != "src/Token.sol" || units[1].Path != "src/utils/Math.sol" || units[1].Root.ID != 2 {
+		t.Errorf("unexpected source units: %+v", units)
+	}
+	if target, err := parser.TargetUnit(units, path); err != nil || target.Path != "src/Token.sol" {
+		t.Errorf("expected the compiled file as target, got %+v: %v", target, err)
+	}
+
+	input, err := os.ReadFile(filepath.Join(solcDir, "input.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"remappings":["@oz/=lib/openzeppelin/contracts/"]`, `"optimizer":{"enabled":true,"runs":1000}`} {
+		if !strings.Contains(string(input), expected) {
+			t.Errorf("input does not contain %s:\n%s", expected, input)
+		}
+	}
+
+	if warnings := c.Output.Warnings(); len(warnings) != 1 || warnings[0].String() != "Warning: Unused variable." {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	contracts := c.Contracts()
+	if len(contracts) != 1 {
+		t.Fatalf("expected 1 contract, got %d", len(contracts))
+	}
+	token := contracts[0]
+	if token.ContractName != "Token" || token.SourcePath != "src/Token.sol" {
+		t.Errorf("unexpected contract %s in %s", token.ContractName, token.SourcePath)
+	}
+	if token.EVMCode.ByteCode != "6080" || token.EVMCode.DeployedSourceMap != "3:4:0" {
+		t.Errorf("unexpected EVM code: %+v", token.EVMCode)
+	}
+	if !strings.Contains(token.SolidityCode.SourceCode, "contract Token") {
+		t.Errorf("source code not set")
+	}
+}
+
+func TestStandardJSONCompiler_Errors(t *testing.T) {
+	project := setupProject(t)
+	solcDir := setupFakeSolc(t, `{"errors": [
+  {"severity": "error", "type": "TypeError", "message": "Undeclared identifier.", "formattedMessage": "TypeError: Undeclared identifier.\n --> src/Token.sol:5:1:\n"},
+  {"severity": "warning", "type": "Warning", "message": "Unused variable."}
+]}`)
+	c := compiler.NewStandardJSONCompiler(solcDir, project, nil)
+
+	path := filepath.Join(project, "src", "Token.sol")
+	err := c.SolidityToAST_JSON(path)
+	if err == nil || !strings.Contains(err.Error(), "TypeError: Undeclared identifier.") {
+		t.Fatalf("expected the solc error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "Unused variable") {
+		t.Errorf("warnings should not be reported as errors: %v", err)
+	}
+	if _, err := os.Stat(path + ".ast.json"); err == nil {
+		t.Errorf("no AST file should be written on error")
+	}
+}
+
+func TestStandardJSONCompiler_ResetOutput(t *testing.T) {
+	project := setupProject(t)
+	solcDir := setupFakeSolc(t, `{
+  "errors": [{"severity": "warning", "type": "Warning", "message": "Unused variable."}],
+  "sources": {"src/Token.sol": {"id": 0, "ast": {"nodeType": "SourceUnit", "id": 1, "src": "0:0:0", "nodes": []}}}
+}`)
+	c := compiler.NewStandardJSONCompiler(solcDir, project, nil)
+	if err := c.SolidityToAST_JSON(filepath.Join(project, "src", "Token.sol")); err != nil {
+		t.Fatal(err)
+	}
+	if c.Output == nil {
+		t.Fatal("expected the output of the compilation")
+	}
+
+	// the warnings of the previous file are not reported again for a file that fails
+	if err := c.SolidityToAST_JSON(filepath.Join(project, "src", "Missing.sol")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+	if c.Input != nil || c.Output != nil {
+		t.Errorf("expected no input and output after a failed compilation, got %v", c.Output)
+	}
+	if contracts := c.Contracts(); len(contracts) != 0 {
+		t.Errorf("expected no contract, got %v", contracts)
+	}
+}
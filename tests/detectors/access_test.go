@@ -0,0 +1,153 @@
+package detectors
+
+import (
+	"strings"
+	"testing"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/detectors"
+	"txtracker/tests/testutil"
+)
+
+func statementTypes(ctx *detectors.Context, name string) []CFG.StatementType {
+	var res []CFG.StatementType
+	for _, entry := range ctx.CFG.EntryPoints {
+		if entry.Name != name {
+			continue
+		}
+		for _, block := range entry.Blocks {
+			for _, stmt := range block.Statements {
+				res = append(res, stmt.Type)
+			}
+		}
+	}
+	return res
+}
+
+func TestAuthorizeStatements(t *testing.T) {
+	tests := []struct {
+		ctx      *detectors.Context
+		function string
+		expected CFG.StatementType
+	}{
+		// require(msg.sender == owner) in onlyOwner
+		{testutil.LoadContext(t, "test_ast_dataset/Vault.sol.ast.json"), "Vault::pause", CFG.Authorize},
+		// if (!admins[msg.sender]) revert();
+		{testutil.LoadContext(t, "test_ast_dataset/Vault.sol.ast.json"), "Vault::setFee", CFG.Authorize},
+		// if (!admins[msg.sender]) { emit Denied(msg.sender); } goes on
+		{testutil.LoadContext(t, "test_ast_dataset/Vault.sol.ast.json"), "Vault::setFeeLogged", CFG.If},
+		// require(!locked) does not check the caller
+		{testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json"), "Bank::withdrawGuarded", CFG.Require},
+		// require(msg.sender.call.value(amount)())
+		{testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json"), "Bank::withdraw", CFG.Require},
+		// require(hasRole(1, msg.sender)) looks the caller up in roles
+		{testutil.LoadContext(t, "test_ast_dataset/Roles.sol.ast.json"), "Roles::setFee", CFG.Authorize},
+		// if (!isAdmin()) revert(); with isAdmin() returning msg.sender == admin
+		{testutil.LoadContext(t, "test_ast_dataset/Roles.sol.ast.json"), "Roles::pause", CFG.Authorize},
+		// require(token.transfer(msg.sender, amount)) pays the caller
+		{testutil.LoadContext(t, "test_ast_dataset/Roles.sol.ast.json"), "Roles::claim", CFG.Require},
+		// require(token.transferFrom(msg.sender, address(this), amount))
+		{testutil.LoadContext(t, "test_ast_dataset/Roles.sol.ast.json"), "Roles::deposit", CFG.Require},
+		// require(msg.sender != _self()), _self() reads no state variable
+		{testutil.LoadContext(t, "test_ast_dataset/Roles.sol.ast.json"), "Roles::setRate", CFG.Require},
+	}
+
+	for _, tt := range tests {
+		types := statementTypes(tt.ctx, tt.function)
+		if len(types) == 0 {
+			t.Fatalf("No statement in %s", tt.function)
+		}
+		found := false
+		for _, tp := range types {
+			if tp == CFG.Authorize && tt.expected != CFG.Authorize {
+				t.Errorf("Unexpected Authorize statement in %s", tt.function)
+			}
+			if tp == tt.expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a %s statement in %s, got %v", tt.expected, tt.function, types)
+		}
+	}
+}
+
+func TestPermissionMatrix(t *testing.T) {
+	matrices := detectors.NewPermissionMatrices(testutil.LoadContext(t, "test_ast_dataset/Vault.sol.ast.json"))
+	if len(matrices) != 1 || matrices[0].Contract != "Vault" {
+		t.Fatalf("Expected the matrix of Vault, got %d matrices", len(matrices))
+	}
+	m := matrices[0]
+
+	if strings.Join(m.Variables, ",") != "admins,fee,owner,paused" {
+		t.Errorf("Unexpected variables %v", m.Variables)
+	}
+	if !m.Checked["owner"] || !m.Checked["admins"] || m.Checked["paused"] {
+		t.Errorf("Unexpected variables checked against the caller %v", m.Checked)
+	}
+
+	expected := map[string]map[string]string{
+		"Vault::setFee":            {"fee": "!admins[msg.sender]"},
+		"Vault::setFeeLogged":      {"fee": ""},
+		"Vault::addAdmin":          {"admins": "onlyOwner"},
+		"Vault::pause":             {"paused": "onlyOwner"},
+		"Vault::unpause":           {"paused": ""},
+		"Vault::claimOwnership":    {"owner": ""},
+		"Vault::transferOwnership": {"owner": "onlyOwner"}, // in _setOwner
+	}
+	if len(m.Permissions) != len(expected) {
+		t.Errorf("Expected %d entry points, got %d", len(expected), len(m.Permissions))
+	}
+	for _, p := range m.Permissions {
+		cells, ok := expected[p.Function.Name]
+		if !ok {
+			t.Errorf("Unexpected entry point %s", p.Function.Name)
+			continue
+		}
+		for _, v := range m.Variables {
+			guards, written := p.Guards(v)
+			want, expectWrite := cells[v]
+			if written != expectWrite {
+				t.Errorf("%s writes %s: %v, want %v", p.Function.Name, v, written, expectWrite)
+				continue
+			}
+			if written && strings.Join(guards, "|") != want {
+				t.Errorf("%s writes %s under %q, want %q", p.Function.Name, v, guards, want)
+			}
+		}
+		if p.Function.Name == "Vault::transferOwnership" && p.Writes[0].Via != "_setOwner" {
+			t.Errorf("Expected the write of owner in _setOwner, got %q", p.Writes[0].Via)
+		}
+	}
+}
+
+func TestUnguardedFunction(t *testing.T) {
+	findings := detectors.NewUnguardedFunction().Detect(testutil.LoadContext(t, "test_ast_dataset/Vault.sol.ast.json"))
+
+	functions := make(map[string]string)
+	for _, f := range findings {
+		functions[f.Function] = f.Message
+	}
+	if len(findings) != 3 {
+		t.Errorf("Expected 3 findings, got %d: %+v", len(findings), findings)
+	}
+	// the if of setFeeLogged does not stop the caller
+	if !strings.Contains(functions["Vault::setFeeLogged"], "Vault::setFee writes it under !admins[msg.sender]") {
+		t.Errorf("Unexpected message for setFeeLogged %q", functions["Vault::setFeeLogged"])
+	}
+	// paused is written under onlyOwner by pause
+	if !strings.Contains(functions["Vault::unpause"], "Vault::pause writes it under onlyOwner") {
+		t.Errorf("Unexpected message for unpause %q", functions["Vault::unpause"])
+	}
+	// owner is compared to the caller by onlyOwner
+	if !strings.Contains(functions["Vault::claimOwnership"], "state variable owner") {
+		t.Errorf("Unexpected message for claimOwnership %q", functions["Vault::claimOwnership"])
+	}
+
+	// balances is a mapping written by anyone
+	if findings := detectors.NewUnguardedFunction().Detect(testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json")); len(findings) != 0 {
+		t.Errorf("Expected no finding in Bank, got %+v", findings)
+	}
+	if findings := detectors.NewUnguardedFunction().Detect(testutil.LoadContext(t, likerCoin)); len(findings) != 0 {
+		t.Errorf("Expected no finding in LikerCoin, got %+v", findings)
+	}
+}
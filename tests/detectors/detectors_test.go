@@ -0,0 +1,144 @@
+package detectors
+
+import (
+	"strings"
+	"testing"
+	AST "txtracker/internal/ast"
+	"txtracker/internal/detectors"
+	"txtracker/tests/testutil"
+)
+
+const likerCoin = "../parser/test_ast_dataset/0x0a3f9678d6b631386c2dd3de8809b48b0d1bbd56.sol.ast.json"
+
+// functionNames reports every function definition whose name starts with a prefix
+type functionNames struct {
+	id     string
+	prefix string
+}
+
+func (d *functionNames) ID() string                       { return d.id }
+func (d *functionNames) Description() string              { return "functions named " + d.prefix + "*" }
+func (d *functionNames) Severity() detectors.Severity     { return detectors.Low }
+func (d *functionNames) Confidence() detectors.Confidence { return detectors.HighConfidence }
+
+func (d *functionNames) Detect(ctx *detectors.Context) []detectors.Finding {
+	var res []detectors.Finding
+	var visit func(node *AST.Common)
+	visit = func(node *AST.Common) {
+		if f, ok := node.ASTNode.(*AST.FunctionDefinition); ok && strings.HasPrefix(f.Name, d.prefix) {
+			res = append(res, detectors.NewFinding(d, ctx, node, "function "+f.Name))
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	visit(ctx.Root)
+	return res
+}
+
+func init() {
+	detectors.Register(func() detectors.Detector { return &functionNames{"test-pause", "pause"} })
+	detectors.Register(func() detectors.Detector { return &functionNames{"test-transfer", "transfer"} })
+}
+
+func TestParseSrc(t *testing.T) {
+	location, err := detectors.ParseSrc("120:35:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.Start != 120 || location.Length != 35 || location.File != 2 {
+		t.Errorf("Unexpected location %+v", location)
+	}
+
+	if _, err := detectors.ParseSrc("120:35"); err == nil {
+		t.Errorf("Expected an error for an incomplete src")
+	}
+}
+
+func TestNewLocationWithSources(t *testing.T) {
+	ctx := testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json")
+	testutil.LoadSource(t, ctx, "test_ast_dataset/Bank.sol", "contracts/Bank.sol")
+
+	findings := detectors.NewReentrancy().Detect(ctx)
+	if len(findings) == 0 {
+		t.Fatal("Expected reentrancy findings in Bank")
+	}
+	// balances[msg.sender] = 0; in withdraw
+	f := findings[0]
+	if f.Location.String() != "contracts/Bank.sol:21:9" || f.Location.EndLine != 21 {
+		t.Errorf("Unexpected location %+v", f.Location)
+	}
+	if !strings.Contains(f.Snippet, "21 |         balances[msg.sender] = 0;") {
+		t.Errorf("Unexpected snippet\n%s", f.Snippet)
+	}
+
+	// without the sources, the location is the byte range of the context path
+	f = detectors.NewReentrancy().Detect(testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json"))[0]
+	if f.Location.Line != 0 || f.Snippet != "" || !strings.HasPrefix(f.Location.String(), "Bank.sol@") {
+		t.Errorf("Unexpected location %s", f.Location)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		enabled  []string
+		disabled []string
+		expected []string
+	}{
+		{nil, nil, []string{"test-pause", "test-transfer"}},
+		{[]string{"test-transfer"}, nil, []string{"test-transfer"}},
+		{nil, []string{"test-transfer"}, []string{"test-pause"}},
+	}
+
+	for _, tt := range tests {
+		selected, err := detectors.Select(tt.enabled, tt.disabled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, d := range selected {
+			if strings.HasPrefix(d.ID(), "test-") {
+				ids = append(ids, d.ID())
+			}
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("Select(%v, %v) = %v, want %v", tt.enabled, tt.disabled, ids, tt.expected)
+		}
+	}
+
+	if _, err := detectors.Select([]string{"unknown"}, nil); err == nil || !strings.Contains(err.Error(), "test-pause") {
+		t.Errorf("Expected an unknown detector error listing the detectors, got %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := testutil.LoadContext(t, likerCoin)
+	selected, err := detectors.Select([]string{"test-transfer", "test-pause"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := detectors.Run(selected, ctx)
+	if len(findings) == 0 {
+		t.Fatal("Expected findings")
+	}
+
+	var pause bool
+	for i, f := range findings {
+		if f.Location.Path != ctx.Path || f.Location.Length == 0 {
+			t.Errorf("Unexpected location %+v", f.Location)
+		}
+		if i > 0 && findings[i-1].Location.Start > f.Location.Start {
+			t.Errorf("Findings are not sorted by location")
+		}
+		if f.ID == "test-pause" {
+			pause = true
+			if f.Severity != detectors.Low || f.Confidence != detectors.HighConfidence {
+				t.Errorf("Unexpected severity %s and confidence %s", f.Severity, f.Confidence)
+			}
+		}
+	}
+	if !pause {
+		t.Errorf("Expected a finding for pause")
+	}
+}
@@ -0,0 +1,50 @@
+package detectors
+
+import (
+	"strings"
+	"testing"
+	"txtracker/internal/detectors"
+	"txtracker/tests/testutil"
+)
+
+func TestIntegerOverflow(t *testing.T) {
+	findings := detectors.NewIntegerOverflow().Detect(testutil.LoadContext(t, "test_ast_dataset/Token.sol.ast.json"))
+
+	// SafeMath, transferSafe and the balance compared by require in burn are not
+	// reported, the repeated pragma is named once
+	expected := []string{
+		"a + b may overflow (uint256 + uint256) under pragma solidity ^0.4.24",
+		"balances[msg.sender] -= value may underflow (uint256 -= uint256) under pragma solidity ^0.4.24",
+		"balances[to] += value may overflow (uint256 += uint256) under pragma solidity ^0.4.24",
+		"totalSupply + amount * 2 may overflow (uint256 + uint256) under pragma solidity ^0.4.24",
+		"amount * 2 may overflow (uint256 * int_const 2) under pragma solidity ^0.4.24",
+		"totalSupply -= amount may underflow (uint256 -= uint256) under pragma solidity ^0.4.24",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for i, f := range findings {
+		if f.Message != expected[i] {
+			t.Errorf("Unexpected finding %q, want %q", f.Message, expected[i])
+		}
+		if f.Function == "Token::transferSafe" || strings.HasPrefix(f.Function, "SafeMath::") {
+			t.Errorf("Unexpected finding in %s: %s", f.Function, f.Message)
+		}
+	}
+}
+
+func TestIntegerOverflowUnchecked(t *testing.T) {
+	findings := detectors.NewIntegerOverflow().Detect(testutil.LoadContext(t, "test_ast_dataset/Counter.sol.ast.json"))
+
+	// count += step reverts on overflow since 0.8.0, the free function wrap is
+	// checked as well
+	expected := []string{"Counter::decrement", "wrap"}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for i, f := range findings {
+		if f.Function != expected[i] || !strings.HasSuffix(f.Message, "in an unchecked block") {
+			t.Errorf("Unexpected finding in %s: %s", f.Function, f.Message)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package detectors
+
+import (
+	"strings"
+	"testing"
+	"txtracker/internal/detectors"
+	"txtracker/tests/testutil"
+)
+
+func TestReentrancy(t *testing.T) {
+	ctx := testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json")
+	findings := detectors.NewReentrancy().Detect(ctx)
+
+	byFunction := map[string]detectors.Finding{}
+	for _, f := range findings {
+		if f.ID != "reentrancy" || f.Severity != detectors.High {
+			t.Errorf("Unexpected finding %+v", f)
+		}
+		byFunction[f.Function] = f
+	}
+	if len(findings) != 2 {
+		t.Errorf("Expected 2 findings, got %d: %+v", len(findings), findings)
+	}
+
+	// require(msg.sender.call.value(amount)()); balances[msg.sender] = 0;
+	withdraw, ok := byFunction["Bank::withdraw"]
+	if !ok {
+		t.Fatal("Expected a finding in withdraw")
+	}
+	if !strings.Contains(withdraw.Message, "balances") || !strings.Contains(withdraw.Message, "msg.sender.call") {
+		t.Errorf("Unexpected message %q", withdraw.Message)
+	}
+	if len(withdraw.Related) != 1 || withdraw.Related[0].Start >= withdraw.Location.Start {
+		t.Errorf("Expected the call site before the write, got %+v", withdraw.Related)
+	}
+
+	// _send(...) transfers, then _reset() deletes the balance
+	internal, ok := byFunction["Bank::withdrawInternal"]
+	if !ok {
+		t.Fatal("Expected a finding in withdrawInternal")
+	}
+	if !strings.Contains(internal.Message, "_reset()") || !strings.Contains(internal.Message, "_send()") {
+		t.Errorf("Unexpected message %q", internal.Message)
+	}
+
+	// the write before the call and the noReentrancy mutex are safe
+	for _, name := range []string{"Bank::withdrawSafe", "Bank::withdrawGuarded", "Bank::deposit"} {
+		if f, ok := byFunction[name]; ok {
+			t.Errorf("Unexpected finding in %s: %s", name, f.Message)
+		}
+	}
+}
+
+func TestReentrancyNoExternalCall(t *testing.T) {
+	ctx := testutil.LoadContext(t, likerCoin)
+	if findings := detectors.NewReentrancy().Detect(ctx); len(findings) != 0 {
+		t.Errorf("Expected no finding in LikerCoin, got %+v", findings)
+	}
+}
+
+func TestReentrancyLock(t *testing.T) {
+	ctx := testutil.LoadContext(t, "test_ast_dataset/Escrow.sol.ast.json")
+	byFunction := map[string]detectors.Finding{}
+	for _, f := range detectors.NewReentrancy().Detect(ctx) {
+		byFunction[f.Function] = f
+	}
+
+	// require(balances[to] >= amount); balances[to] -= amount; to.call(...); total -= amount;
+	// the balance is checked and written before the call, it is no lock
+	withdraw, ok := byFunction["Escrow::withdraw"]
+	if !ok {
+		t.Fatal("Expected a finding in withdraw, the checked balance is no lock")
+	}
+	if !strings.Contains(withdraw.Message, "total") || !strings.Contains(withdraw.Message, "to.call") {
+		t.Errorf("Unexpected message %q", withdraw.Message)
+	}
+	// closed is checked and set before the call but never reset
+	if _, ok := byFunction["Escrow::withdrawAll"]; !ok {
+		t.Error("Expected a finding in withdrawAll, a flag that is never reset is no lock")
+	}
+	// the lock modifier checks, sets and resets the bool locked
+	if f, ok := byFunction["Escrow::withdrawLocked"]; ok {
+		t.Errorf("Unexpected finding in withdrawLocked: %s", f.Message)
+	}
+}
+
+// Sale.sol is given as the legacy JSON AST of solc 0.4.11, where token.transfer
+// has the type function (address,uint256) external returns (bool)
+func TestReentrancyLegacy(t *testing.T) {
+	findings := detectors.NewReentrancy().Detect(testutil.LoadContext(t, "test_ast_dataset/Sale.sol.ast.json"))
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Function != "Sale::claim" || !strings.Contains(f.Message, "bought") || !strings.Contains(f.Message, "token.transfer") {
+		t.Errorf("Unexpected finding %+v", f)
+	}
+}
@@ -0,0 +1,183 @@
+package detectors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/detectors"
+	"txtracker/tests/testutil"
+)
+
+func findSummary(summaries []*detectors.FunctionSummary, contract, function string) *detectors.FunctionSummary {
+	for _, s := range summaries {
+		if s.Contract == contract && s.Function == function {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestFunctionSummaries(t *testing.T) {
+	ctx := testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json")
+	summaries := detectors.NewFunctionSummaries(ctx)
+	if len(summaries) != len(ctx.CFG.EntryPoints) {
+		t.Fatalf("Expected a summary per entry point, got %d", len(summaries))
+	}
+
+	deposit := findSummary(summaries, "Bank", "deposit()")
+	if deposit == nil || !deposit.Payable || deposit.Visibility != "public" || deposit.Path != "Bank.sol" {
+		t.Fatalf("Expected Bank::deposit to be public and payable, got %+v", deposit)
+	}
+	if !reflect.DeepEqual(deposit.Writes, []string{"balances"}) || len(deposit.Reads) != 0 {
+		t.Errorf("Expected deposit to write balances only, got reads %v writes %v", deposit.Reads, deposit.Writes)
+	}
+
+	withdraw := findSummary(summaries, "Bank", "withdraw()")
+	if withdraw.Payable || !reflect.DeepEqual(withdraw.ExternalCalls, []string{"msg.sender.call"}) {
+		t.Errorf("Expected withdraw to call msg.sender.call, got %v", withdraw.ExternalCalls)
+	}
+	if !reflect.DeepEqual(withdraw.Reads, []string{"balances"}) {
+		t.Errorf("Expected withdraw to read balances, got %v", withdraw.Reads)
+	}
+
+	guarded := findSummary(summaries, "Bank", "withdrawGuarded()")
+	if !reflect.DeepEqual(guarded.Modifiers, []string{"noReentrancy"}) || !reflect.DeepEqual(guarded.Writes, []string{"locked", "balances"}) {
+		t.Errorf("Expected the writes of the modifier, got modifiers %v writes %v", guarded.Modifiers, guarded.Writes)
+	}
+}
+
+func TestFunctionSummariesInternalCalls(t *testing.T) {
+	// withdrawInternal transfers the balance in _send and deletes it in _reset
+	summaries := detectors.NewFunctionSummaries(testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json"))
+	internal := findSummary(summaries, "Bank", "withdrawInternal()")
+	if internal == nil || !reflect.DeepEqual(internal.Writes, []string{"balances"}) || !reflect.DeepEqual(internal.ExternalCalls, []string{"msg.sender.transfer"}) {
+		t.Errorf("Expected the write and the call of _send, got %+v", internal)
+	}
+
+	// transferOwnership writes owner in _setOwner
+	summaries = detectors.NewFunctionSummaries(testutil.LoadContext(t, "test_ast_dataset/Vault.sol.ast.json"))
+	if s := findSummary(summaries, "Vault", "transferOwnership(address)"); s == nil || !reflect.DeepEqual(s.Writes, []string{"owner"}) {
+		t.Errorf("Expected transferOwnership to write owner, got %+v", s)
+	}
+}
+
+func TestFunctionSummariesGuardsAndEvents(t *testing.T) {
+	summaries := detectors.NewFunctionSummaries(testutil.LoadContext(t, "test_ast_dataset/Vault.sol.ast.json"))
+	if s := findSummary(summaries, "Vault", "addAdmin(address)"); s == nil || !reflect.DeepEqual(s.Guards, []string{"msg.sender == owner"}) {
+		t.Errorf("Expected addAdmin to be guarded by msg.sender == owner, got %+v", s)
+	}
+	if s := findSummary(summaries, "Vault", "unpause()"); s == nil || len(s.Guards) != 0 {
+		t.Errorf("Expected unpause to be unguarded, got %+v", s)
+	}
+
+	summaries = detectors.NewFunctionSummaries(testutil.LoadContext(t, likerCoin))
+	if s := findSummary(summaries, "LikerCoin", "constructor"); s == nil || !reflect.DeepEqual(s.Events, []string{"Transfer"}) {
+		t.Errorf("Expected the constructor to emit Transfer, got %+v", s)
+	}
+}
+
+func TestFunctionSummariesSolidity08(t *testing.T) {
+	ctx := testutil.LoadContext(t, "test_ast_dataset/Ledger.sol.ast.json")
+	summaries := detectors.NewFunctionSummaries(ctx)
+
+	deposit := findSummary(summaries, "Ledger", "deposit()")
+	if deposit == nil || !reflect.DeepEqual(deposit.Events, []string{"Deposit"}) {
+		t.Fatalf("Expected deposit to emit Deposit, got %+v", deposit)
+	}
+	if !reflect.DeepEqual(deposit.Writes, []string{"balances", "total"}) {
+		t.Errorf("Expected deposit to write balances and total, got %v", deposit.Writes)
+	}
+	if withdraw := findSummary(summaries, "Ledger", "withdraw(uint256)"); !reflect.DeepEqual(withdraw.ExternalCalls, []string{"msg.sender.call"}) {
+		t.Errorf("Expected msg.sender.call{value: amount} to be an external call, got %v", withdraw.ExternalCalls)
+	}
+	// payable(msg.sender).transfer(balances[msg.sender])
+	escrow := detectors.NewFunctionSummaries(testutil.LoadContext(t, "test_ast_dataset/Escrow.sol.ast.json"))
+	if withdraw := findSummary(escrow, "Escrow", "withdrawAll()"); !reflect.DeepEqual(withdraw.ExternalCalls, []string{"payable(msg.sender).transfer"}) {
+		t.Errorf("Expected payable(msg.sender).transfer to be an external call, got %v", withdraw.ExternalCalls)
+	}
+	if drain := findSummary(summaries, "Ledger", "drain(uint256)"); !reflect.DeepEqual(drain.Writes, []string{"total"}) {
+		t.Errorf("Expected the loop body of drain to write total, got %v", drain.Writes)
+	}
+	// s := sload(total.slot)
+	if slot := findSummary(summaries, "Ledger", "slot()"); !reflect.DeepEqual(slot.Reads, []string{"total"}) || len(slot.Writes) != 0 {
+		t.Errorf("Expected the assembly of slot to read total, got %+v", slot)
+	}
+
+	for name, want := range map[string]CFG.StatementType{
+		"Ledger::deposit":  CFG.Emit,
+		"Ledger::withdraw": CFG.Revert,
+		"Ledger::drain":    CFG.While,
+		"Ledger::slot":     CFG.InlineAssembly,
+	} {
+		found := false
+		for _, typ := range statementTypes(ctx, name) {
+			found = found || typ == want
+		}
+		if !found {
+			t.Errorf("Expected a %s statement in %s, got %v", want, name, statementTypes(ctx, name))
+		}
+	}
+}
+
+func TestFunctionSummariesLegacyAST(t *testing.T) {
+	ctx := testutil.LoadContext(t, "test_ast_dataset/Wallet.sol.ast.json")
+	summaries := detectors.NewFunctionSummaries(ctx)
+	if len(summaries) != 4 {
+		t.Fatalf("Expected the constructor and 3 functions, got %d", len(summaries))
+	}
+
+	// function Wallet() is the constructor of solc < 0.4.22
+	if ctor := findSummary(summaries, "Wallet", "constructor"); ctor == nil || !reflect.DeepEqual(ctor.Writes, []string{"owner"}) {
+		t.Errorf("Expected the constructor to write owner, got %+v", ctor)
+	}
+	// an event is called without emit
+	deposit := findSummary(summaries, "Wallet", "deposit()")
+	if !deposit.Payable || !reflect.DeepEqual(deposit.Events, []string{"Deposit"}) || !reflect.DeepEqual(deposit.Writes, []string{"balances"}) {
+		t.Errorf("Expected deposit to be payable, write balances and emit Deposit, got %+v", deposit)
+	}
+	if withdraw := findSummary(summaries, "Wallet", "withdraw(uint256)"); !reflect.DeepEqual(withdraw.ExternalCalls, []string{"msg.sender.call"}) {
+		t.Errorf("Expected msg.sender.call.value(amount)() to be an external call, got %v", withdraw.ExternalCalls)
+	}
+	// if (msg.sender != owner) throw;
+	if kill := findSummary(summaries, "Wallet", "kill()"); !reflect.DeepEqual(kill.Guards, []string{"msg.sender != owner"}) {
+		t.Errorf("Expected kill to be guarded by onlyOwner, got %v", kill.Guards)
+	}
+
+	findings := detectors.NewReentrancy().Detect(ctx)
+	if len(findings) != 1 || findings[0].Function != "Wallet::withdraw" {
+		t.Errorf("Expected a reentrancy in withdraw, got %+v", findings)
+	}
+}
+
+func TestFunctionSummariesInlineAssembly(t *testing.T) {
+	ctx := testutil.LoadContext(t, "test_ast_dataset/Proxy.sol.ast.json")
+	summaries := detectors.NewFunctionSummaries(ctx)
+
+	// sstore(admin_slot, newAdmin)
+	if change := findSummary(summaries, "Proxy", "changeAdmin(address)"); !reflect.DeepEqual(change.Writes, []string{"admin"}) {
+		t.Errorf("Expected changeAdmin to write admin, got %v", change.Writes)
+	}
+	// sstore(slot, impl) to a slot that is not the one of a variable, slot holds IMPLEMENTATION_SLOT
+	if upgrade := findSummary(summaries, "Proxy", "upgradeTo(address)"); !reflect.DeepEqual(upgrade.Writes, []string{"storage[IMPLEMENTATION_SLOT]"}) {
+		t.Errorf("Expected upgradeTo to write storage[IMPLEMENTATION_SLOT], got %v", upgrade.Writes)
+	}
+	fallback := findSummary(summaries, "Proxy", "fallback")
+	if fallback == nil || !reflect.DeepEqual(fallback.ExternalCalls, []string{"delegatecall(impl)"}) {
+		t.Fatalf("Expected the fallback to delegate to impl, got %+v", fallback)
+	}
+	// sload(cell), cell holds IMPLEMENTATION_SLOT too
+	if !reflect.DeepEqual(fallback.Reads, []string{"IMPLEMENTATION_SLOT", "storage[IMPLEMENTATION_SLOT]"}) {
+		t.Errorf("Expected the fallback to load the implementation, got %v", fallback.Reads)
+	}
+
+	// call(gas, caller, amount, 0, 0, 0, 0), then credits[msg.sender] = 0
+	claim := findSummary(summaries, "Proxy", "claim()")
+	if !reflect.DeepEqual(claim.ExternalCalls, []string{"call(caller)"}) {
+		t.Errorf("Expected claim to call the caller, got %v", claim.ExternalCalls)
+	}
+	findings := detectors.NewReentrancy().Detect(ctx)
+	if len(findings) != 1 || findings[0].Function != "Proxy::claim" || !strings.Contains(findings[0].Message, "call(caller)") {
+		t.Errorf("Expected a reentrancy after the call of the assembly of claim, got %+v", findings)
+	}
+}
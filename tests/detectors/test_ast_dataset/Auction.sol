@@ -0,0 +1,27 @@
+pragma solidity ^0.8.19;
+
+contract Auction {
+    address public highestBidder;
+    uint256 public highestBid;
+    uint256 public round;
+    mapping(address => uint256) public refunds;
+
+    function bid() public payable {
+        require(msg.value > highestBid);
+        refunds[highestBidder] += highestBid;
+        (highestBidder, highestBid) = (msg.sender, msg.value);
+    }
+
+    function reset() public {
+        _clear();
+    }
+
+    function restart() public {
+        round = 0;
+        round += 1;
+    }
+
+    function _clear() internal {
+        highestBid = 0;
+    }
+}
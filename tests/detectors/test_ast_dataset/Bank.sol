@@ -0,0 +1,48 @@
+pragma solidity ^0.4.24;
+
+contract Bank {
+    mapping(address => uint256) public balances;
+    bool locked;
+
+    modifier noReentrancy() {
+        require(!locked);
+        locked = true;
+        _;
+        locked = false;
+    }
+
+    function deposit() public payable {
+        balances[msg.sender] += msg.value;
+    }
+
+    function withdraw() public {
+        uint256 amount = balances[msg.sender];
+        require(msg.sender.call.value(amount)());
+        balances[msg.sender] = 0;
+    }
+
+    function withdrawSafe() public {
+        uint256 amount = balances[msg.sender];
+        balances[msg.sender] = 0;
+        msg.sender.transfer(amount);
+    }
+
+    function withdrawGuarded() public noReentrancy {
+        uint256 amount = balances[msg.sender];
+        msg.sender.transfer(amount);
+        balances[msg.sender] = 0;
+    }
+
+    function withdrawInternal() public {
+        _send(balances[msg.sender]);
+        _reset();
+    }
+
+    function _send(uint256 amount) internal {
+        msg.sender.transfer(amount);
+    }
+
+    function _reset() internal {
+        delete balances[msg.sender];
+    }
+}
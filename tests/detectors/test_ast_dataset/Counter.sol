@@ -0,0 +1,22 @@
+pragma solidity >=0.8.0 <0.9.0;
+
+contract Counter {
+    uint256 count;
+
+    function increment(uint256 step) public {
+        count += step;
+    }
+
+    function decrement(uint256 step) public {
+        unchecked {
+            count -= step;
+        }
+    }
+}
+
+// a free function, out of the contract
+function wrap(uint256 a, uint256 b) pure returns (uint256) {
+    unchecked {
+        return a + b;
+    }
+}
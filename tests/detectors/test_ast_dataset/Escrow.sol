@@ -0,0 +1,44 @@
+pragma solidity ^0.8.19;
+
+contract Escrow {
+    mapping(address => uint256) public balances;
+    uint256 public total;
+    bool locked;
+    bool closed;
+
+    modifier lock() {
+        require(!locked);
+        locked = true;
+        _;
+        locked = false;
+    }
+
+    function deposit() public payable {
+        balances[msg.sender] += msg.value;
+        total += msg.value;
+    }
+
+    // the balance is checked then written before the call, it is no lock
+    function withdraw(address payable to, uint256 amount) public {
+        require(balances[to] >= amount);
+        balances[to] -= amount;
+        (bool ok, ) = to.call{value: amount}("");
+        require(ok);
+        total -= amount;
+    }
+
+    // closed is set before the call but never reset, it is no lock
+    function withdrawAll() public {
+        require(!closed);
+        closed = true;
+        payable(msg.sender).transfer(balances[msg.sender]);
+        total -= balances[msg.sender];
+    }
+
+    function withdrawLocked(uint256 amount) public lock {
+        require(balances[msg.sender] >= amount);
+        balances[msg.sender] -= amount;
+        payable(msg.sender).transfer(amount);
+        total -= amount;
+    }
+}
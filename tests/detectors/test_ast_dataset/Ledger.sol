@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.8;
+
+type Amount is uint256;
+
+error Insufficient(uint256 balance, uint256 amount);
+
+contract Ledger {
+    /// @notice credited balances
+    mapping(address => uint256) public balances;
+    uint256 public total;
+
+    event Deposit(address indexed from, uint256 amount);
+
+    function deposit() external payable {
+        balances[msg.sender] += msg.value;
+        total += msg.value;
+        emit Deposit(msg.sender, msg.value);
+    }
+
+    function withdraw(uint256 amount) external {
+        uint256 balance = balances[msg.sender];
+        if (balance < amount) {
+            revert Insufficient(balance, amount);
+        }
+        unchecked {
+            balances[msg.sender] = balance - amount;
+        }
+        (bool ok, ) = msg.sender.call{value: amount}("");
+        require(ok);
+    }
+
+    function drain(uint256 n) external {
+        uint256 i = 0;
+        while (i < n) {
+            total -= 1;
+            i++;
+        }
+    }
+
+    function slot() external view returns (uint256 s) {
+        assembly {
+            s := sload(total.slot)
+        }
+    }
+}
@@ -0,0 +1,34 @@
+pragma solidity ^0.4.24;
+
+contract Payout {
+    mapping(address => uint256) credits;
+
+    function pay(address to, uint256 amount) public {
+        to.send(amount);
+    }
+
+    function payChecked(address to, uint256 amount) public {
+        require(to.send(amount));
+    }
+
+    function payLocal(address to, uint256 amount) public {
+        bool ok = to.send(amount);
+        credits[to] = 0;
+    }
+
+    function payLocalChecked(address to, uint256 amount) public {
+        bool ok = to.call.value(amount)();
+        if (!ok) {
+            revert();
+        }
+    }
+
+    function forward(address target) public {
+        target.delegatecall(msg.data);
+    }
+
+    function _refund(address to, uint256 amount) internal {
+        bool sent;
+        sent = to.send(amount);
+    }
+}
@@ -0,0 +1,48 @@
+pragma solidity ^0.4.24;
+
+contract Proxy {
+    address public admin;
+    mapping(address => uint256) public credits;
+    bytes32 internal constant IMPLEMENTATION_SLOT = 0x7050c9e0f4ca769c69bd3a8ef740bc37934f8e2c036e5a723fd8ee048ed3f8c3;
+
+    constructor() public {
+        admin = msg.sender;
+    }
+
+    function upgradeTo(address impl) public {
+        require(msg.sender == admin);
+        bytes32 slot = IMPLEMENTATION_SLOT;
+        assembly {
+            sstore(slot, impl)
+        }
+    }
+
+    function changeAdmin(address newAdmin) public {
+        require(msg.sender == admin);
+        assembly {
+            sstore(admin_slot, newAdmin)
+        }
+    }
+
+    function claim() public {
+        uint256 amount = credits[msg.sender];
+        assembly {
+            let ok := call(gas, caller, amount, 0, 0, 0, 0)
+            if iszero(ok) { revert(0, 0) }
+        }
+        credits[msg.sender] = 0;
+    }
+
+    function() public payable {
+        bytes32 cell = IMPLEMENTATION_SLOT;
+        assembly {
+            let impl := sload(cell)
+            calldatacopy(0, 0, calldatasize)
+            let result := delegatecall(gas, impl, 0, calldatasize, 0, 0)
+            returndatacopy(0, 0, returndatasize)
+            switch result
+            case 0 { revert(0, returndatasize) }
+            default { return(0, returndatasize) }
+        }
+    }
+}
@@ -0,0 +1,56 @@
+pragma solidity ^0.8.19;
+
+interface IERC20 {
+    function transfer(address to, uint256 amount) external returns (bool);
+    function transferFrom(address from, address to, uint256 amount) external returns (bool);
+}
+
+contract Roles {
+    IERC20 token;
+    address admin;
+    mapping(uint256 => mapping(address => bool)) roles;
+    mapping(address => uint256) deposits;
+    uint256 fee;
+    uint256 rate;
+    bool paused;
+
+    function hasRole(uint256 role, address account) public view returns (bool) {
+        return roles[role][account];
+    }
+
+    function isAdmin() public view returns (bool) {
+        return msg.sender == admin;
+    }
+
+    function _self() internal view returns (address) {
+        return address(this);
+    }
+
+    // the caller is passed to hasRole, which looks it up in roles
+    function setFee(uint256 _fee) public {
+        require(hasRole(1, msg.sender));
+        fee = _fee;
+    }
+
+    function pause() public {
+        if (!isAdmin()) revert();
+        paused = true;
+    }
+
+    // the caller receives the tokens, it is not checked
+    function claim(uint256 amount) public {
+        require(token.transfer(msg.sender, amount));
+        deposits[msg.sender] = 0;
+    }
+
+    function deposit(uint256 amount) public {
+        require(token.transferFrom(msg.sender, address(this), amount));
+        deposits[msg.sender] += amount;
+    }
+
+    // _self() reads no state variable
+    function setRate(uint256 _rate) public {
+        require(msg.sender != _self());
+        rate = _rate;
+    }
+}
@@ -0,0 +1,16 @@
+pragma solidity ^0.4.11;
+
+contract Token {
+    function transfer(address to, uint256 value) returns (bool);
+}
+
+contract Sale {
+    Token token;
+    mapping(address => uint256) bought;
+
+    // the tokens are sent before the purchase is cleared
+    function claim() {
+        token.transfer(msg.sender, bought[msg.sender]);
+        bought[msg.sender] = 0;
+    }
+}
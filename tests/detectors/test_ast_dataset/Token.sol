@@ -0,0 +1,56 @@
+pragma solidity ^0.4.24;
+
+library SafeMath {
+    function add(uint256 a, uint256 b) internal pure returns (uint256) {
+        uint256 c = a + b;
+        assert(c >= a);
+        return c;
+    }
+
+    function mul(uint256 a, uint256 b) internal pure returns (uint256) {
+        uint256 c = a * b;
+        require(a == 0 || c / a == b);
+        return c;
+    }
+
+    function sub(uint256 a, uint256 b) internal pure returns (uint256) {
+        assert(b <= a);
+        return a - b;
+    }
+}
+
+library Average {
+    // the require does not check the sum
+    function mean(uint256 a, uint256 b) internal pure returns (uint256) {
+        require(b > 0);
+        return (a + b) / 2;
+    }
+}
+
+// the pragma repeated by a flattened source
+pragma solidity ^0.4.24;
+
+contract Token {
+    mapping(address => uint256) balances;
+    uint256 totalSupply;
+
+    function transfer(address to, uint256 value) public {
+        balances[msg.sender] -= value;
+        balances[to] += value;
+    }
+
+    function transferSafe(address to, uint256 value) public {
+        balances[to] = SafeMath.add(balances[to], value);
+    }
+
+    function mint(uint256 amount) public {
+        totalSupply = totalSupply + amount * 2;
+    }
+
+    // the balance is compared to amount before it is decreased
+    function burn(uint256 amount) public {
+        require(balances[msg.sender] >= amount);
+        balances[msg.sender] -= amount;
+        totalSupply -= amount;
+    }
+}
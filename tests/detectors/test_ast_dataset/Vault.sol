@@ -0,0 +1,54 @@
+pragma solidity ^0.4.24;
+
+contract Vault {
+    address public owner;
+    mapping(address => bool) admins;
+    bool paused;
+    uint256 fee;
+
+    event Denied(address caller);
+
+    modifier onlyOwner() {
+        require(msg.sender == owner);
+        _;
+    }
+
+    function setFee(uint256 _fee) public {
+        if (!admins[msg.sender]) {
+            revert();
+        }
+        fee = _fee;
+    }
+
+    // the check logs the caller but does not stop it
+    function setFeeLogged(uint256 _fee) public {
+        if (!admins[msg.sender]) {
+            emit Denied(msg.sender);
+        }
+        fee = _fee;
+    }
+
+    function addAdmin(address admin) public onlyOwner {
+        admins[admin] = true;
+    }
+
+    function pause() public onlyOwner {
+        paused = true;
+    }
+
+    function unpause() public {
+        paused = false;
+    }
+
+    function claimOwnership() public {
+        owner = msg.sender;
+    }
+
+    function transferOwnership(address newOwner) public onlyOwner {
+        _setOwner(newOwner);
+    }
+
+    function _setOwner(address newOwner) internal {
+        owner = newOwner;
+    }
+}
@@ -0,0 +1,32 @@
+pragma solidity ^0.4.11;
+
+contract Wallet {
+    address owner;
+    mapping(address => uint) balances;
+
+    event Deposit(address from, uint amount);
+
+    modifier onlyOwner() {
+        if (msg.sender != owner) throw;
+        _;
+    }
+
+    function Wallet() {
+        owner = msg.sender;
+    }
+
+    function deposit() payable {
+        balances[msg.sender] += msg.value;
+        Deposit(msg.sender, msg.value);
+    }
+
+    function withdraw(uint amount) {
+        if (balances[msg.sender] < amount) throw;
+        if (!msg.sender.call.value(amount)()) throw;
+        balances[msg.sender] -= amount;
+    }
+
+    function kill() onlyOwner {
+        selfdestruct(owner);
+    }
+}
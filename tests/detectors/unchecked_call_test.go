@@ -0,0 +1,43 @@
+package detectors
+
+import (
+	"strings"
+	"testing"
+	"txtracker/internal/detectors"
+	"txtracker/tests/testutil"
+)
+
+func TestUncheckedCall(t *testing.T) {
+	findings := detectors.NewUncheckedCall().Detect(testutil.LoadContext(t, "test_ast_dataset/Payout.sol.ast.json"))
+
+	expected := map[string]string{
+		"Payout::pay":      "return value of to.send() is not checked",
+		"Payout::payLocal": "stored in ok, which is never checked",
+		"Payout::forward":  "return value of target.delegatecall() is not checked",
+		// internal functions are inspected too
+		"Payout::_refund": "stored in sent, which is never checked",
+	}
+	if len(findings) != len(expected) {
+		t.Errorf("Expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for _, f := range findings {
+		want, ok := expected[f.Function]
+		if !ok {
+			t.Errorf("Unexpected finding in %s: %s", f.Function, f.Message)
+			continue
+		}
+		if !strings.Contains(f.Message, want) {
+			t.Errorf("Unexpected message in %s: %q", f.Function, f.Message)
+		}
+		if f.Location.Path != "Payout.sol" || f.Location.Length == 0 {
+			t.Errorf("Unexpected location %+v", f.Location)
+		}
+	}
+}
+
+func TestUncheckedCallChecked(t *testing.T) {
+	// require(msg.sender.call.value(amount)()) and transfer, which reverts
+	if findings := detectors.NewUncheckedCall().Detect(testutil.LoadContext(t, "test_ast_dataset/Bank.sol.ast.json")); len(findings) != 0 {
+		t.Errorf("Expected no finding in Bank, got %+v", findings)
+	}
+}
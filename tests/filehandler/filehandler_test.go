@@ -5,7 +5,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
-	"txtracker/pkg/filehandler"
+	"txtracker/internal/filehandler"
 )
 
 // Setup and Teardown functionality can be used to create a temporary testing environment.
@@ -83,7 +83,8 @@ func TestSolidityFileHandler_GetContractSolPathList(t *testing.T) {
 
 	contractPathList := handler.GetContractSolPathList()
 
-	expected := []string{filepath.Join(DataPath, "TestContract1.sol"), filepath.Join(DataPath, "TestContract2.sol")}
+	// TestContract2 only has bytecode, there is nothing to compile
+	expected := []string{filepath.Join(DataPath, "TestContract1.sol")}
 	if !reflect.DeepEqual(contractPathList, expected) {
 		t.Errorf("Expected contract path list to be %v, got %v", expected, contractPathList)
 	}
@@ -0,0 +1,154 @@
+package main_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	buildOnce sync.Once
+	binary    string
+	buildErr  error
+)
+
+// txtracker builds the command once for the tests of the package
+func txtracker(t *testing.T) string {
+	buildOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "txtracker")
+		if err != nil {
+			buildErr = err
+			return
+		}
+		binary = filepath.Join(dir, "txtracker")
+		out, err := exec.Command("go", "build", "-o", binary, "txtracker/cmd/txtracker").CombinedOutput()
+		if err != nil {
+			buildErr = errors.New(string(out))
+		}
+	})
+	if buildErr != nil {
+		t.Fatalf("Error building txtracker: %v", buildErr)
+	}
+	return binary
+}
+
+// run returns the exit code, the output and the error output of the command
+func run(t *testing.T, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(txtracker(t), args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode(), stdout.String(), stderr.String()
+	}
+	if err != nil {
+		t.Fatalf("Error running txtracker: %v", err)
+	}
+	return 0, stdout.String(), stderr.String()
+}
+
+func TestCommandLineParsing(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"no command", nil, 2, "no command given"},
+		{"unknown command", []string{"compile"}, 2, `unknown command "compile"`},
+		{"help", []string{"--help"}, 0, "Usage: txtracker <command>"},
+		{"help of a command", []string{"help", "cfg"}, 0, "Usage: txtracker cfg [flags] [input]"},
+		{"unknown flag", []string{"cfg", "--depth", "2"}, 2, "flag provided but not defined: -depth"},
+		{"flag of another command", []string{"detect", "--length", "2"}, 2, "flag provided but not defined: -length"},
+		{"unsupported format", []string{"symbols", "-f", "dot"}, 2, `command symbols does not support format "dot" (supported: text, json)`},
+		{"too many inputs", []string{"ast", "A.sol", "B.sol"}, 2, "too many arguments: A.sol B.sol"},
+		{"graph directory without a graph format", []string{"cfg", "--graph-dir", "graphs"}, 2, "--graph-dir and --per-function require the dot or mermaid format"},
+		{"missing input", []string{"analyze", "-i", filepath.Join(t.TempDir(), "missing")}, 1, "error reading input path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := run(t, tt.args...)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d: %s", tt.code, code, stderr)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("Expected %q in the error output, got %s", tt.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestListDetectors(t *testing.T) {
+	code, stdout, stderr := run(t, "detect", "--list")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "reentrancy") {
+		t.Errorf("Expected the reentrancy detector in the list, got %s", stdout)
+	}
+}
+
+func TestInputWithoutSolidityFiles(t *testing.T) {
+	// a contract of the directory only has its bytecode, there is nothing to compile
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Token.evm"), []byte("0x6001600081"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr := run(t, "analyze", "-i", dir)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("Expected no contract, got %s", stdout)
+	}
+}
+
+func TestBuildInfoImports(t *testing.T) {
+	// src/Token.sol inherits Ownable from lib/oz/Ownable.sol, compiled with it
+	code, stdout, stderr := run(t, "access", "--build-info", "test_build_info")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if strings.Contains(stderr, "Processing: lib/oz/Ownable.sol") {
+		t.Errorf("Expected the dependency not to be reported, got %s", stderr)
+	}
+	for _, line := range []string{
+		"Token::mint                msg.sender == owner   .",
+		"Token::transferOwnership   .                     msg.sender == owner",
+	} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("Expected %q, the guard on the inherited owner, got\n%s", line, stdout)
+		}
+	}
+}
+
+func TestGraphDirectory(t *testing.T) {
+	dir := t.TempDir()
+	code, _, stderr := run(t, "cfg", "-f", "dot", "--per-function", "--graph-dir", dir, "--build-info", "test_build_info")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	// a directory per source unit, a file per signature
+	for _, name := range []string{"Token.mint-address-uint256.dot", "Token.transferOwnership-address.dot", "Token.balances-address.dot"} {
+		if _, err := os.Stat(filepath.Join(dir, "src", "Token", name)); err != nil {
+			t.Errorf("Expected the graph %s: %v", name, err)
+		}
+	}
+}
+
+func TestReportDirectory(t *testing.T) {
+	dir := t.TempDir()
+	code, _, stderr := run(t, "report", "--report-dir", dir, "--build-info", "test_build_info")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "Token", "Token.html")); err != nil {
+		t.Errorf("Expected the report of Token in the directory of src/Token.sol: %v", err)
+	}
+}
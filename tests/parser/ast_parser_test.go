@@ -1,8 +1,16 @@
 package parser
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
-	"txtracker/pkg/parser"
+	"txtracker/internal/ast"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/parser"
+	symboltable "txtracker/internal/symbol_table"
 )
 
 func setupTestEnvironment() string {
@@ -12,7 +20,10 @@ func setupTestEnvironment() string {
 
 func TestASTParserImpl_ParseAST_JSON(t *testing.T) {
 	DataPath := setupTestEnvironment()
-	root := parser.NewASTParser().ParseAST_JSON(DataPath)
+	root, err := parser.NewASTParser().ParseAST_JSON(DataPath)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if root == nil {
 		t.Errorf("Expected root to be non-nil")
@@ -35,3 +46,192 @@ func TestASTParserImpl_ParseAST_JSON(t *testing.T) {
 		t.Errorf("Expected second child's first child to have ID 34, got %d", root.Children[1].Children[0].ID)
 	}
 }
+
+// writeAST writes the AST file of a compiled file to a temporary directory and
+// returns its path
+func writeAST(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name+".ast.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// a statement of a node type unknown to the ast package, e.g. of a newer solc
+func TestASTParserImpl_ParseAST_JSONUnknownNode(t *testing.T) {
+	content, err := os.ReadFile("../detectors/test_ast_dataset/Ledger.sol.ast.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeAST(t, "Ledger.sol", strings.Replace(string(content), `"nodeType": "WhileStatement"`, `"nodeType": "UntilStatement"`, 1))
+
+	root, err := parser.NewASTParser().ParseAST_JSON(path)
+	if err != nil {
+		t.Fatalf("Expected the unknown node to be kept, got %v", err)
+	}
+
+	var generic *ast.GenericNode
+	drain := root.Children[len(root.Children)-1].Children[5].ASTNode.(*ast.FunctionDefinition)
+	ast.InspectBlock(&drain.Body, func(node *ast.Common) bool {
+		if g, ok := node.ASTNode.(*ast.GenericNode); ok {
+			generic = g
+		}
+		return true
+	})
+	if generic == nil || generic.Data["nodeType"] != "UntilStatement" || generic.Data["condition"] == nil {
+		t.Fatalf("Expected a GenericNode keeping the JSON of UntilStatement, got %+v", generic)
+	}
+
+	symbolTable, err := symboltable.NewGlobalSymbolTable(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := CFG.NewCFG(root, symbolTable)
+	found := false
+	for _, w := range cfg.Warnings {
+		found = found || w.Message == "Skipped unknown statement UntilStatement"
+	}
+	if !found {
+		t.Errorf("Expected the CFG to skip the statement with a warning, got %+v", cfg.Warnings)
+	}
+}
+
+func TestASTParserImpl_ParseAST_JSONErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"not JSON":       "JSON AST (compact format):\n\n{ not json",
+		"no src":         `{"id": 1, "nodeType": "SourceUnit", "nodes": []}`,
+		"nested no type": `{"id": 2, "nodeType": "SourceUnit", "src": "0:0:0", "nodes": [{"id": 1, "src": "0:0:0"}]}`,
+		"not a node":     `{"id": 2, "nodeType": "SourceUnit", "src": "0:0:0", "nodes": [1]}`,
+		"truncated":      "======= A.sol =======\n{\"id\": 2, \"nodeType\": \"SourceUnit\", \"src\": \"0:0:0\", \"nodes\": [",
+		"second unit":    "{\"id\": 2, \"nodeType\": \"SourceUnit\", \"src\": \"0:0:0\", \"nodes\": []}\n======= B.sol =======\n{ not json",
+		"no AST":         "JSON AST (compact format):\n",
+		"other unit":     "======= B.sol =======\n{\"id\": 2, \"nodeType\": \"SourceUnit\", \"src\": \"0:0:0\", \"nodes\": []}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			root, err := parser.NewASTParser().ParseAST_JSON(writeAST(t, "A.sol", content))
+			if err == nil {
+				t.Errorf("Expected an error, got %+v", root)
+			}
+		})
+	}
+
+	if _, err := parser.NewASTParser().ParseAST_JSON(filepath.Join(t.TempDir(), "missing.sol.ast.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+// solc prints the AST of every source file, the imports included
+func TestASTParserImpl_ParseSources(t *testing.T) {
+	var content strings.Builder
+	for _, name := range []string{"Bank", "Counter"} {
+		data, err := os.ReadFile("../detectors/test_ast_dataset/" + name + ".sol.ast.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content.Len() > 0 {
+			// drop the "JSON AST (compact format):" line of the second file
+			data = data[bytes.Index(data, []byte("=======")):]
+		}
+		content.Write(data)
+		content.WriteString("\n")
+	}
+	path := filepath.Join(t.TempDir(), "Counter.sol.ast.json")
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	units, err := parser.NewASTParser().ParseSources(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) != 2 || units[0].Path != "Bank.sol" || units[1].Path != "Counter.sol" {
+		t.Fatalf("Expected the source units of Bank.sol and Counter.sol, got %+v", units)
+	}
+	for _, unit := range units {
+		if unit.Root.NodeType != "SourceUnit" || unit.Root.Parent != unit.Root {
+			t.Errorf("Expected %s to have a SourceUnit root, got %s", unit.Path, unit.Root.NodeType)
+		}
+	}
+
+	root, err := parser.NewASTParser().ParseAST_JSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.ID != units[1].Root.ID {
+		t.Errorf("Expected the AST of Counter.sol, got the source unit %d", root.ID)
+	}
+}
+
+func TestTargetUnit(t *testing.T) {
+	units := []parser.SourceUnit{{Path: "src/Token.sol"}, {Path: "lib/token/Token.sol"}, {Path: "src/Sale.sol"}}
+	tests := []struct {
+		path     string
+		expected string // empty for an error
+	}{
+		{"src/Token.sol", "src/Token.sol"},
+		{"/home/me/project/lib/token/Token.sol", "lib/token/Token.sol"},
+		// run from src/, the base name is shared by two units
+		{"Token.sol", ""},
+		{"Sale.sol", "src/Sale.sol"},
+		{"Crowdsale.sol", ""},
+	}
+	for _, tt := range tests {
+		target, err := parser.TargetUnit(units, tt.path)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("Expected an error for %s, got %s", tt.path, target.Path)
+			}
+			continue
+		}
+		if err != nil || target.Path != tt.expected {
+			t.Errorf("Expected %s for %s, got %+v: %v", tt.expected, tt.path, target, err)
+		}
+	}
+}
+
+// an AST on a single line, without header, e.g. extracted from a standard JSON output
+func TestASTParserImpl_ParseAST_JSONCompact(t *testing.T) {
+	data, err := os.ReadFile(setupTestEnvironment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data[bytes.IndexByte(data, '{'):]); err != nil {
+		t.Fatal(err)
+	}
+	path := writeAST(t, "Test.sol", compact.String())
+
+	units, err := parser.NewASTParser().ParseSources(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) != 1 || units[0].Path != strings.TrimSuffix(path, ".ast.json") {
+		t.Fatalf("Expected a source unit named after the file, got %+v", units)
+	}
+	root := units[0].Root
+	if len(root.Children) < 2 || root.Children[1].ID != 97 || root.Children[1].Children[0].ID != 34 {
+		t.Errorf("Expected the same tree as the indented AST")
+	}
+	for _, child := range root.Children {
+		if child.Parent != root {
+			t.Errorf("Expected %s to be a child of the root", child.NodeType)
+		}
+	}
+}
+
+// a contract without a name cannot namespace its symbols
+func TestNewGlobalSymbolTableUnnamedContract(t *testing.T) {
+	content, err := os.ReadFile("../detectors/test_ast_dataset/Counter.sol.ast.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeAST(t, "Counter.sol", strings.Replace(string(content), `"name": "Counter"`, `"name": ""`, 1))
+
+	root, err := parser.NewASTParser().ParseAST_JSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := symboltable.NewGlobalSymbolTable(root); err == nil || !strings.Contains(err.Error(), "contract name not found") {
+		t.Errorf("Expected a contract name error, got %v", err)
+	}
+}
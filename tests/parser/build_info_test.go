@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"txtracker/internal/parser"
+)
+
+// setupBuildInfoProject creates a Foundry project whose build-info holds the test
+// AST, plus an older build-info compiling another version of src/Empty.sol
+func setupBuildInfoProject(t *testing.T) string {
+	content, err := os.ReadFile(setupTestEnvironment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// drop the header printed by solc before the AST
+	ast := string(content[strings.Index(string(content), "{"):])
+
+	project := t.TempDir()
+	dir := filepath.Join(project, "out", "build-info")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	emptyAST := func(id int) string {
+		return `{"nodeType": "SourceUnit", "id": ` + strconv.Itoa(id) + `, "src": "0:0:1", "absolutePath": "src/Empty.sol", "nodes": []}`
+	}
+	files := map[string]string{
+		"new.json": `{"id": "new", "solcVersion": "0.4.26", "input": {"sources": {
+			"src/Empty.sol": {"content": "pragma solidity ^0.4.26;\n"}}}, "output": {"sources": {
+			"src/LikerCoin.sol": {"id": 0, "ast": ` + ast + `},
+			"src/Empty.sol": {"id": 1, "ast": ` + emptyAST(2) + `},
+			"lib/forge-std/Test.sol": {"id": 2, "ast": {"nodeType": "SourceUnit", "id": 3, "src": "0:0:2", "nodes": []}}}}}`,
+		"old.json": `{"id": "old", "solcVersion": "0.4.24", "input": {}, "output": {"sources": {
+			"src/Empty.sol": {"id": 0, "ast": ` + emptyAST(1) + `}}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+func TestBuildInfoLoader_Load(t *testing.T) {
+	units, err := parser.NewBuildInfoLoader().Load(setupBuildInfoProject(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lib/forge-std/Test.sol is a dependency, only part of the compilation
+	if len(units) != 2 {
+		t.Fatalf("Expected 2 source units, got %d", len(units))
+	}
+	empty, liker := units[0], units[1]
+	if empty.Path != "src/Empty.sol" || liker.Path != "src/LikerCoin.sol" {
+		t.Errorf("Unexpected source units %s and %s", empty.Path, liker.Path)
+	}
+
+	// src/Empty.sol is taken from the most recent build-info
+	if empty.Root.ID != 2 || empty.SolcVersion != "0.4.26" {
+		t.Errorf("Expected src/Empty.sol from new.json, got ID %d compiled by %s", empty.Root.ID, empty.SolcVersion)
+	}
+
+	// the file indices of the src attributes map to the sources of the input
+	if file := empty.Sources.File(1); file == nil || file.Path != "src/Empty.sol" || file.Line(1) != "pragma solidity ^0.4.26;" {
+		t.Errorf("Expected the source of src/Empty.sol at index 1, got %+v", file)
+	}
+	if empty.Sources.File(0) != nil {
+		t.Errorf("Expected no source for src/LikerCoin.sol, missing from the input")
+	}
+
+	if liker.Compilation != empty.Compilation || len(liker.Compilation.Roots) != 3 {
+		t.Errorf("Expected the 3 source units of new.json in the compilation, got %+v", liker.Compilation)
+	}
+
+	root := liker.Root
+	if root.NodeType != "SourceUnit" {
+		t.Errorf("Expected root to have name SourceUnit, got %s", root.NodeType)
+	}
+	if root.Children[1].ID != 97 {
+		t.Errorf("Expected second child to have ID 97, got %d", root.Children[1].ID)
+	}
+}
+
+func TestBuildInfoLoader_NotFound(t *testing.T) {
+	_, err := parser.NewBuildInfoLoader().Load(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "no build-info file found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"txtracker/internal/ast"
+	"txtracker/internal/parser"
+)
+
+// Wallet.sol is given as the legacy JSON AST of solc 0.4.11, whose identifiers
+// have no referencedDeclaration
+func TestASTParserImpl_ParseAST_JSONLegacy(t *testing.T) {
+	root, err := parser.NewASTParser().ParseAST_JSON("../detectors/test_ast_dataset/Wallet.sol.ast.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.NodeType != "SourceUnit" || len(root.Children) != 2 || root.Children[0].NodeType != "PragmaDirective" {
+		t.Fatalf("Expected a source unit with a pragma and a contract, got %+v", root.Children)
+	}
+
+	contract := root.Children[1]
+	contractDef := contract.ASTNode.(*ast.ContractDefinition)
+	if contractDef.Name != "Wallet" || contractDef.ContractKind != "contract" || len(contractDef.LinearizedBaseContracts) != 1 {
+		t.Errorf("Unexpected contract %+v", contractDef)
+	}
+	if len(contract.Children) != 8 {
+		t.Fatalf("Expected the 8 members of the contract, got %d", len(contract.Children))
+	}
+	owner := contract.Children[0].ASTNode.(*ast.VariableDeclaration)
+	if !owner.StateVariable || owner.TypeDescriptions.TypeIdentifier != "t_address" {
+		t.Errorf("Expected owner to be a state variable of type t_address, got %+v", owner)
+	}
+	if balances := contract.Children[1].ASTNode.(*ast.VariableDeclaration); balances.TypeName.NodeType != "Mapping" {
+		t.Errorf("Expected balances to be a mapping, got %s", balances.TypeName.NodeType)
+	}
+
+	for i, kind := range map[int]ast.FunctionKind{4: ast.FunctionKind_Constructor, 5: ast.FunctionKind_Function} {
+		if funcDef := contract.Children[i].ASTNode.(*ast.FunctionDefinition); funcDef.Kind != kind || !funcDef.Implemented {
+			t.Errorf("Expected %s to be an implemented %s, got %s", funcDef.Name, kind, funcDef.Kind)
+		}
+	}
+	deposit := contract.Children[5].ASTNode.(*ast.FunctionDefinition)
+	if deposit.StateMutability != ast.StateMutability_Payable || len(deposit.Body.Statements) != 2 {
+		t.Errorf("Expected deposit to be payable with 2 statements, got %+v", deposit)
+	}
+	kill := contract.Children[7].ASTNode.(*ast.FunctionDefinition)
+	if id, ok := kill.Modifiers[0].ReferencedDeclaration(); len(kill.Modifiers) != 1 || !ok || id != contract.Children[3].ID {
+		t.Errorf("Expected kill to invoke onlyOwner, got %+v", kill.Modifiers)
+	}
+
+	// the identifiers are resolved by name, the event by its declaration
+	call := deposit.Body.Statements[1].ASTNode.(*ast.ExpressionStatement).Expression.ASTNode.(*ast.FunctionCall)
+	event := call.Expression.ASTNode.(*ast.Identifier)
+	if event.ReferencedDeclaration != contract.Children[2].ID || event.TypeDescriptions.TypeIdentifier != "t_function_event_nonpayable" {
+		t.Errorf("Expected Deposit to refer to the event, got %+v", event)
+	}
+	if len(call.Arguments) != 2 || call.Kind != ast.FunctionCallKind_FunctionCall {
+		t.Errorf("Expected a function call with 2 arguments, got %+v", call)
+	}
+	withdraw := contract.Children[6].ASTNode.(*ast.FunctionDefinition)
+	check := withdraw.Body.Statements[0].ASTNode.(*ast.IfStatement)
+	amount := check.Condition.ASTNode.(*ast.BinaryOperation).RightExpression.ASTNode.(*ast.Identifier)
+	sub := withdraw.Body.Statements[2].ASTNode.(*ast.ExpressionStatement).Expression.ASTNode.(*ast.Assignment)
+	if ref := sub.RightHandSide.ASTNode.(*ast.Identifier).ReferencedDeclaration; amount.ReferencedDeclaration == 0 || ref != amount.ReferencedDeclaration {
+		t.Errorf("Expected both uses of amount to refer to the parameter, got %d and %d", amount.ReferencedDeclaration, ref)
+	}
+	if check.TrueBody.NodeType != "Throw" || check.FalseBody != nil {
+		t.Errorf("Expected if (...) throw; without else, got %+v", check)
+	}
+}
+
+// the legacy JSON AST of solc 0.4.12 to 0.4.26, written next to the compact one,
+// has referencedDeclaration and writes an empty list of nodes [null]
+func TestASTParserImpl_ParseAST_JSONLegacyFor(t *testing.T) {
+	path := writeAST(t, "Loop.sol", `JSON AST:
+
+
+======= Loop.sol =======
+{
+  "attributes": {"absolutePath": "Loop.sol", "exportedSymbols": {"Loop": [20]}},
+  "children": [{
+    "attributes": {"baseContracts": [null], "contractDependencies": [null], "contractKind": "contract",
+      "documentation": null, "fullyImplemented": true, "linearizedBaseContracts": [20], "name": "Loop", "scope": 21},
+    "children": [{
+      "attributes": {"constant": false, "implemented": true, "isConstructor": false, "modifiers": [null],
+        "name": "f", "payable": false, "scope": 20, "stateMutability": "pure", "superFunction": null, "visibility": "public"},
+      "children": [
+        {"attributes": {"parameters": [null]}, "children": [], "id": 1, "name": "ParameterList", "src": "30:2:0"},
+        {"attributes": {"parameters": [null]}, "children": [], "id": 2, "name": "ParameterList", "src": "40:0:0"},
+        {"children": [{
+          "children": [
+            {"attributes": {"commonType": {"typeIdentifier": "t_uint256", "typeString": "uint256"}, "isConstant": false,
+              "isLValue": false, "isPure": false, "lValueRequested": false, "operator": "<", "type": "bool"},
+              "children": [
+                {"attributes": {"argumentTypes": null, "overloadedDeclarations": [null], "referencedDeclaration": 9,
+                  "type": "uint256", "value": "i"}, "id": 3, "name": "Identifier", "src": "60:1:0"},
+                {"attributes": {"argumentTypes": null, "hexvalue": "3130", "isConstant": false, "isLValue": false,
+                  "isPure": true, "lValueRequested": false, "subdenomination": null, "token": "number",
+                  "type": "int_const 10", "value": "10"}, "id": 4, "name": "Literal", "src": "64:2:0"}
+              ], "id": 5, "name": "BinaryOperation", "src": "60:6:0"},
+            {"children": [{"attributes": {"operator": "++", "prefix": false, "type": "uint256"},
+              "children": [{"attributes": {"referencedDeclaration": 9, "type": "uint256", "value": "i"},
+                "id": 6, "name": "Identifier", "src": "68:1:0"}],
+              "id": 7, "name": "UnaryOperation", "src": "68:3:0"}], "id": 8, "name": "ExpressionStatement", "src": "68:3:0"},
+            {"attributes": {"statements": [null]}, "children": [], "id": 10, "name": "Block", "src": "73:2:0"}
+          ], "id": 11, "name": "ForStatement", "src": "50:25:0"}],
+          "id": 12, "name": "Block", "src": "42:35:0"}
+      ], "id": 13, "name": "FunctionDefinition", "src": "20:57:0"}],
+    "id": 20, "name": "ContractDefinition", "src": "0:80:0"}],
+  "id": 21, "name": "SourceUnit", "src": "0:80:0"
+}
+`)
+
+	root, err := parser.NewASTParser().ParseAST_JSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := root.Children[0].Children[0].ASTNode.(*ast.FunctionDefinition)
+	if f.StateMutability != "pure" || f.Kind != ast.FunctionKind_Function || len(f.Modifiers) != 0 {
+		t.Errorf("Unexpected function %+v", f)
+	}
+	// for (; i < 10; i++) {}
+	loop := f.Body.Statements[0].ASTNode.(*ast.ForStatement)
+	if loop.InitializationExpression != nil || loop.Condition.NodeType != "BinaryOperation" ||
+		loop.LoopExpression.NodeType != "ExpressionStatement" || loop.Body.NodeType != "Block" {
+		t.Errorf("Unexpected parts of the for statement %+v", loop)
+	}
+	literal := loop.Condition.ASTNode.(*ast.BinaryOperation).RightExpression.ASTNode.(*ast.Literal)
+	if literal.Kind != ast.LiteralKind_Integer || literal.HexValue != "3130" || literal.TypeDescriptions.TypeIdentifier != "t_rational_10_by_1" {
+		t.Errorf("Unexpected literal %+v", literal)
+	}
+}
+
+// the legacy JSON AST leaves the absent clauses of a for loop out, a lone
+// statement is either the initialization or the loop expression
+func TestASTParserImpl_ParseAST_JSONLegacyForClauses(t *testing.T) {
+	//	contract Loop {
+	//	    uint i;
+	//	    function f() {
+	//	        for (;; i++) {}
+	//	        for (i = 0;;) {}
+	//	    }
+	//	}
+	content := `{
+  "children": [{
+    "attributes": {"fullyImplemented": true, "isLibrary": false, "linearizedBaseContracts": [20], "name": "Loop"},
+    "children": [
+      {"attributes": {"constant": false, "name": "i", "type": "uint256", "visibility": "internal"},
+        "children": [{"attributes": {"name": "uint"}, "id": 1, "name": "ElementaryTypeName", "src": "20:4:0"}],
+        "id": 2, "name": "VariableDeclaration", "src": "20:7:0"},
+      {"attributes": {"constant": false, "name": "f", "payable": false, "visibility": "public"},
+        "children": [
+          {"children": [], "id": 3, "name": "ParameterList", "src": "42:2:0"},
+          {"children": [], "id": 4, "name": "ParameterList", "src": "45:0:0"},
+          {"children": [
+            {"attributes": {}, "children": [
+              {"children": [{"attributes": {"operator": "++", "prefix": false, "type": "uint256"},
+                "children": [{"attributes": {"type": "uint256", "value": "i"}, "id": 5, "name": "Identifier", "src": "63:1:0"}],
+                "id": 6, "name": "UnaryOperation", "src": "63:3:0"}], "id": 7, "name": "ExpressionStatement", "src": "63:3:0"},
+              {"children": [], "id": 8, "name": "Block", "src": "68:2:0"}
+            ], "id": 9, "name": "ForStatement", "src": "55:15:0"},
+            {"attributes": {}, "children": [
+              {"children": [{"attributes": {"operator": "=", "type": "uint256"}, "children": [
+                {"attributes": {"type": "uint256", "value": "i"}, "id": 10, "name": "Identifier", "src": "84:1:0"},
+                {"attributes": {"hexvalue": "30", "token": null, "type": "int_const 0", "value": "0"}, "id": 11, "name": "Literal", "src": "88:1:0"}
+              ], "id": 12, "name": "Assignment", "src": "84:5:0"}], "id": 13, "name": "ExpressionStatement", "src": "84:5:0"},
+              {"children": [], "id": 14, "name": "Block", "src": "93:2:0"}
+            ], "id": 15, "name": "ForStatement", "src": "79:16:0"}
+          ], "id": 16, "name": "Block", "src": "45:56:0"}
+        ], "id": 17, "name": "FunctionDefinition", "src": "32:69:0"}
+    ], "id": 20, "name": "ContractDefinition", "src": "0:103:0"}],
+  "name": "SourceUnit", "id": 21, "src": "0:103:0"
+}`
+
+	for name, content := range map[string]string{
+		// solc < 0.4.12: told apart by their position
+		"position": content,
+		// solc >= 0.4.12 writes the absent clauses as null, whatever the position
+		"attributes": strings.NewReplacer(
+			`"attributes": {}, "children": [
+              {"children": [{"attributes": {"operator": "++"`, `"attributes": {"condition": null, "initializationExpression": null}, "children": [
+              {"children": [{"attributes": {"operator": "++"`,
+			`"attributes": {}, "children": [
+              {"children": [{"attributes": {"operator": "="`, `"attributes": {"condition": null, "loopExpression": null}, "children": [
+              {"children": [{"attributes": {"operator": "="`,
+			`"src": "63:3:0"}`, `"src": "0:0:0"}`,
+			`"src": "84:5:0"}`, `"src": "0:0:0"}`,
+		).Replace(content),
+	} {
+		t.Run(name, func(t *testing.T) {
+			root, err := parser.NewASTParser().ParseAST_JSON(writeAST(t, "Loop.sol", content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := root.Children[0].Children[1].ASTNode.(*ast.FunctionDefinition)
+
+			// for (;; i++) {}
+			increment := f.Body.Statements[0].ASTNode.(*ast.ForStatement)
+			if increment.InitializationExpression != nil || increment.Condition != nil ||
+				increment.LoopExpression == nil || increment.LoopExpression.ID != 7 || increment.Body.ID != 8 {
+				t.Errorf("Expected i++ as loop expression, got %+v", increment)
+			}
+			// for (i = 0;;) {}
+			init := f.Body.Statements[1].ASTNode.(*ast.ForStatement)
+			if init.InitializationExpression == nil || init.InitializationExpression.ID != 13 || init.LoopExpression != nil {
+				t.Errorf("Expected i = 0 as initialization, got %+v", init)
+			}
+		})
+	}
+}
+
+// the functions of a contract and the members of an address are message calls,
+// not internal functions
+func TestASTParserImpl_ParseAST_JSONLegacyMemberTypes(t *testing.T) {
+	tests := []struct {
+		path     string
+		member   string
+		expected string
+	}{
+		{"../detectors/test_ast_dataset/Sale.sol.ast.json", "transfer", "t_function_external"},
+		{"../detectors/test_ast_dataset/Wallet.sol.ast.json", "call", "t_function_barecall_payable"},
+		{"../detectors/test_ast_dataset/Wallet.sol.ast.json", "value", "t_function_setvalue_nonpayable"},
+	}
+	for _, tt := range tests {
+		root, err := parser.NewASTParser().ParseAST_JSON(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found *ast.MemberAccess
+		for _, contract := range root.Children {
+			for _, node := range contract.Children {
+				funcDef, ok := node.ASTNode.(*ast.FunctionDefinition)
+				if !ok {
+					continue
+				}
+				ast.InspectBlock(&funcDef.Body, func(node *ast.Common) bool {
+					if member, ok := node.ASTNode.(*ast.MemberAccess); ok && member.MemberName == tt.member && strings.HasPrefix(member.TypeDescriptions.TypeString, "function ") && found == nil {
+						found = member
+					}
+					return found == nil
+				})
+			}
+		}
+		if found == nil {
+			t.Fatalf("No member %s in %s", tt.member, tt.path)
+		}
+		if id := found.TypeDescriptions.TypeIdentifier; id != tt.expected {
+			t.Errorf("Expected %s to have the type %s, got %s", tt.member, tt.expected, id)
+		}
+	}
+}
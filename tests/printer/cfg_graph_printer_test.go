@@ -0,0 +1,140 @@
+package printer
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/parser"
+	"txtracker/internal/printer"
+	symboltable "txtracker/internal/symbol_table"
+)
+
+func bankCFG() *CFG.CFG {
+	root, err := parser.NewASTParser().ParseAST_JSON("../detectors/test_ast_dataset/Bank.sol.ast.json")
+	if err != nil {
+		panic(err)
+	}
+	symbolTable, err := symboltable.NewGlobalSymbolTable(root)
+	if err != nil {
+		panic(err)
+	}
+	return CFG.NewCFG(root, symbolTable)
+}
+
+func TestCFGGraphs(t *testing.T) {
+	cfg := bankCFG()
+
+	byContract := printer.CFGGraphsByContract(cfg)
+	if len(byContract) != 1 || byContract[0].Name != "Bank" || len(byContract[0].Functions) != len(cfg.EntryPoints) {
+		t.Fatalf("Expected a single graph of Bank holding every entry point, got %d graphs", len(byContract))
+	}
+	byFunction := printer.CFGGraphsByFunction(cfg)
+	if len(byFunction) != len(cfg.EntryPoints) {
+		t.Fatalf("Expected a graph per entry point, got %d", len(byFunction))
+	}
+	if name := byFunction[2].FileName("dot"); name != "Bank.withdraw.dot" {
+		t.Errorf("Expected Bank.withdraw.dot, got %s", name)
+	}
+	// overloads are told apart by their parameter types
+	for signature, want := range map[string]string{
+		"f(uint256)":         "A.f-uint256.mmd",
+		"f(uint256,address)": "A.f-uint256-address.mmd",
+		"f(uint256[])":       "A.f-uint256__.mmd",
+	} {
+		if name := (printer.CFGGraph{Name: "A::f", Signature: signature}).FileName("mmd"); name != want {
+			t.Errorf("Expected %s for %s, got %s", want, signature, name)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"Bank.sol":             "Bank",
+		"src/tokens/Token.sol": filepath.Join("src", "tokens", "Token"),
+		"../lib/Token.sol":     filepath.Join("lib", "Token"),
+	} {
+		if dir := printer.UnitDir(path); dir != want {
+			t.Errorf("Expected the directory %s for %s, got %s", want, path, dir)
+		}
+	}
+}
+
+func TestCFGPrinter_PrintDOT(t *testing.T) {
+	cfg := bankCFG()
+	var out bytes.Buffer
+	p := printer.NewCFGPrinter(cfg, nil, &out)
+	for _, g := range printer.CFGGraphsByFunction(cfg) {
+		if g.Name == "Bank::withdrawGuarded" || g.Name == "Bank::withdrawInternal" {
+			p.PrintDOT(g)
+		}
+	}
+	dot := out.String()
+
+	for _, want := range []string{
+		`digraph "Bank::withdrawGuarded" {`,
+		`label="modifier noReentrancy";`,
+		`b14 [label="B14 (modifier.noReentrancy)\lRequire [locked]\l`,
+		`label="internal calls";`,
+		`f0_c0 [label="_send()", shape=component];`,
+		`b19 -> f0_c1 [style="dashed"`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected the DOT output to contain %s", want)
+		}
+	}
+	if strings.Count(dot, "{") != strings.Count(dot, "}") {
+		t.Error("Expected balanced braces")
+	}
+}
+
+func TestCFGPrinter_PrintMermaid(t *testing.T) {
+	cfg := bankCFG()
+	var out bytes.Buffer
+	printer.NewCFGPrinter(cfg, nil, &out).PrintMermaid(printer.CFGGraphsByContract(cfg)[0])
+	mermaid := out.String()
+
+	if !strings.HasPrefix(mermaid, "flowchart TD\n") {
+		t.Errorf("Expected a flowchart, got %q", strings.SplitN(mermaid, "\n", 2)[0])
+	}
+	for _, want := range []string{
+		`subgraph f4_m0["modifier noReentrancy"]`,
+		`b12(["B12 (entry)"])`,
+		`Assign [locked]*  #lt;-`,
+		`f5_c1[["_reset()"]]`,
+		"b19 -.-> f5_c0",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Expected the Mermaid output to contain %s", want)
+		}
+	}
+	if strings.Count(mermaid, "subgraph") != strings.Count(mermaid, "\n  end\n")+strings.Count(mermaid, "\n    end\n") {
+		t.Error("Expected every subgraph to end")
+	}
+}
+
+func TestCFGPrinter_PrintMermaidConditional(t *testing.T) {
+	root, err := parser.NewASTParser().ParseAST_JSON("../parser/test_ast_dataset/0x0a3f9678d6b631386c2dd3de8809b48b0d1bbd56.sol.ast.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbolTable, err := symboltable.NewGlobalSymbolTable(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := CFG.NewCFG(root, symbolTable)
+	var out bytes.Buffer
+	p := printer.NewCFGPrinter(cfg, nil, &out)
+	for _, g := range printer.CFGGraphsByFunction(cfg) {
+		if g.Name == "LikerCoin::addLockDate" {
+			p.PrintMermaid(g)
+		}
+	}
+	mermaid := out.String()
+
+	if !strings.Contains(mermaid, "-->|true|") || !strings.Contains(mermaid, "-->|false|") {
+		t.Error("Expected labeled conditional edges")
+	}
+	if !strings.Contains(mermaid, "linkStyle") || !strings.Contains(mermaid, "stroke:darkgreen") {
+		t.Error("Expected the conditional edges to be colored")
+	}
+}
@@ -0,0 +1,77 @@
+package printer
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"txtracker/internal/detectors"
+	"txtracker/internal/printer"
+	"txtracker/tests/testutil"
+)
+
+func TestHTMLPrinter(t *testing.T) {
+	findings, _ := detectBank(t)
+	ctx := testutil.LoadContext(t, "../detectors/test_ast_dataset/Bank.sol.ast.json")
+	testutil.LoadSource(t, ctx, "../detectors/test_ast_dataset/Bank.sol", "contracts/Bank.sol")
+
+	p := printer.NewHTMLPrinter(ctx, findings, nil)
+	if contracts := p.Contracts(); len(contracts) != 1 || contracts[0] != "Bank" {
+		t.Fatalf("Expected the single contract Bank, got %v", contracts)
+	}
+	// the file of the contract in the directory of its source unit
+	if name := p.FileName("Bank"); name != filepath.Join("Bank", "Bank.html") {
+		t.Errorf("Expected the report file Bank/Bank.html, got %s", name)
+	}
+	var out bytes.Buffer
+	p.Out = &out
+	if err := p.Print("Bank"); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+
+	for _, want := range []string{
+		"<title>Bank - TxTracker report</title>",
+		// the entry points link to their CFG and to the source
+		`<a href="#fn-2">Bank::withdraw</a> at <a href="#L18">contracts/Bank.sol:18:5</a>`,
+		`<tr id="L18"><td class="ln"><a href="#L18">18</a></td><td class="entries"><a class="entry" href="#fn-2">Bank::withdraw</a>`,
+		// the findings link to the source, whose lines are highlighted
+		`in <code>Bank::withdraw</code> at <a href="#L21">contracts/Bank.sol:21:9</a>`,
+		`<tr id="L21" class="hit">`,
+		// the statements with their symbols, escaped
+		`<table class="block" id="b5">`,
+		`[amount]  &lt;- [balances] [msg]`,
+		`<span class="state">balances</span>`,
+		// the read/write matrix with the guards
+		`<tr><th>entry point</th><th>balances</th><th>locked</th></tr>`,
+		`<td>RW<span class="guards">none</span></td>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected the report to contain %s", want)
+		}
+	}
+	// nothing is fetched from the network
+	for _, forbidden := range []string{"http://", "https://", "<script", "<link"} {
+		if strings.Contains(html, forbidden) {
+			t.Errorf("Expected a self-contained report, found %s", forbidden)
+		}
+	}
+}
+
+func TestHTMLPrinterWithoutSource(t *testing.T) {
+	ctx := testutil.LoadContext(t, "../detectors/test_ast_dataset/Bank.sol.ast.json")
+	testutil.LoadSource(t, ctx, "../detectors/test_ast_dataset/Bank.sol", "contracts/Bank.sol")
+	ctx.Sources = nil
+
+	var out bytes.Buffer
+	if err := printer.NewHTMLPrinter(ctx, []detectors.Finding{}, &out).Print("Bank"); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+	if !strings.Contains(html, "The source file of Bank is unknown.") || !strings.Contains(html, "No finding.") {
+		t.Error("Expected the report to tell the source and the findings are missing")
+	}
+	if strings.Contains(html, `href="#L`) {
+		t.Error("Expected no link to a source line")
+	}
+}
@@ -0,0 +1,144 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"txtracker/internal/detectors"
+	"txtracker/internal/printer"
+	"txtracker/tests/testutil"
+)
+
+// sarif is the part of a SARIF log checked by the tests
+type sarif struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Rules []struct {
+					ID                   string `json:"id"`
+					DefaultConfiguration struct {
+						Level string `json:"level"`
+					} `json:"defaultConfiguration"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Invocations []struct {
+			ToolExecutionNotifications []struct {
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+			} `json:"toolExecutionNotifications"`
+		} `json:"invocations"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			RuleIndex int    `json:"ruleIndex"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine  int `json:"startLine"`
+						ByteOffset int `json:"byteOffset"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+			CodeFlows []struct {
+				ThreadFlows []struct {
+					Locations []json.RawMessage `json:"locations"`
+				} `json:"threadFlows"`
+			} `json:"codeFlows"`
+			PartialFingerprints map[string]string `json:"partialFingerprints"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+func detectBank(t *testing.T) ([]detectors.Finding, []detectors.Detector) {
+	ctx := testutil.LoadContext(t, "../detectors/test_ast_dataset/Bank.sol.ast.json")
+	testutil.LoadSource(t, ctx, "../detectors/test_ast_dataset/Bank.sol", "contracts/Bank.sol")
+	selected, err := detectors.Select([]string{"reentrancy", "unchecked-call"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return detectors.Run(selected, ctx), selected
+}
+
+func printSARIF(t *testing.T, findings []detectors.Finding, rules []detectors.Detector, warnings []detectors.Step) sarif {
+	var out bytes.Buffer
+	if err := printer.NewSARIFPrinter(findings, rules, warnings, &out).Print(); err != nil {
+		t.Fatal(err)
+	}
+	var log sarif
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Expected a SARIF 2.1.0 log of one run, got version %q with %d runs", log.Version, len(log.Runs))
+	}
+	return log
+}
+
+func TestSARIFPrinter(t *testing.T) {
+	findings, rules := detectBank(t)
+	warning := detectors.Step{
+		Location: detectors.Location{Path: "Bank.sol", Start: 10, Length: 5},
+		Message:  "Unreachable statement Return",
+	}
+	run := printSARIF(t, findings, rules, []detectors.Step{warning}).Runs[0]
+
+	driverRules := run.Tool.Driver.Rules
+	if len(driverRules) != 2 || driverRules[0].ID != "reentrancy" || driverRules[0].DefaultConfiguration.Level != "error" {
+		t.Errorf("Unexpected rules %+v", driverRules)
+	}
+	notifications := run.Invocations[0].ToolExecutionNotifications
+	if len(notifications) != 1 || notifications[0].Message.Text != warning.Message {
+		t.Errorf("Expected the CFG warning as a notification, got %+v", notifications)
+	}
+
+	if len(run.Results) != len(findings) {
+		t.Fatalf("Expected %d results, got %d", len(findings), len(run.Results))
+	}
+	// balances[msg.sender] = 0; after msg.sender.call in withdraw
+	result := run.Results[0]
+	if result.RuleID != "reentrancy" || result.RuleIndex != 0 {
+		t.Errorf("Unexpected rule %s at %d", result.RuleID, result.RuleIndex)
+	}
+	location := result.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "contracts/Bank.sol" || location.Region.StartLine != 21 || location.Region.ByteOffset != findings[0].Location.Start {
+		t.Errorf("Unexpected location %+v", location)
+	}
+	// entry point, external call, write
+	if len(result.CodeFlows) != 1 || len(result.CodeFlows[0].ThreadFlows[0].Locations) != 3 {
+		t.Errorf("Expected a code flow of 3 steps, got %+v", result.CodeFlows)
+	}
+}
+
+func TestSARIFPrinterFingerprints(t *testing.T) {
+	findings, rules := detectBank(t)
+	before := printSARIF(t, findings, rules, nil).Runs[0].Results
+
+	// the same findings two lines below
+	for i := range findings {
+		findings[i].Location.Line += 2
+		findings[i].Location.Start += 10
+	}
+	after := printSARIF(t, findings, rules, nil).Runs[0].Results
+
+	for i := range before {
+		if before[i].PartialFingerprints["txtrackerFinding/v1"] != after[i].PartialFingerprints["txtrackerFinding/v1"] {
+			t.Errorf("Fingerprint of result %d changed with its line", i)
+		}
+	}
+	if len(before) > 1 && before[0].PartialFingerprints["txtrackerFinding/v1"] == before[1].PartialFingerprints["txtrackerFinding/v1"] {
+		t.Errorf("Expected distinct fingerprints for distinct findings")
+	}
+
+	// the same finding at two locations of a function, e.g. a write after two calls
+	twice := append([]detectors.Finding{findings[0]}, findings[0])
+	twice[1].Location.Start += 10
+	results := printSARIF(t, twice, rules, nil).Runs[0].Results
+	if results[0].PartialFingerprints["txtrackerFinding/v1"] == results[1].PartialFingerprints["txtrackerFinding/v1"] {
+		t.Errorf("Expected distinct fingerprints for the occurrences of a finding")
+	}
+}
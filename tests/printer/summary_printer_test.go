@@ -0,0 +1,67 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"txtracker/internal/detectors"
+	"txtracker/internal/printer"
+	"txtracker/tests/testutil"
+)
+
+func TestSummaryPrinter_PrintCSV(t *testing.T) {
+	summaries := detectors.NewFunctionSummaries(testutil.LoadContext(t, "../detectors/test_ast_dataset/Bank.sol.ast.json"))
+
+	var out bytes.Buffer
+	p := printer.NewSummaryPrinter(&out)
+	// two source units make a single table
+	if err := p.PrintCSV(summaries[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PrintCSV(summaries[2:]); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(summaries)+1 || records[0][0] != "path" || records[0][9] != "external_calls" {
+		t.Fatalf("Expected a header and a row per entry point, got %v", records)
+	}
+	want := []string{"Bank.sol", "Bank", "withdrawGuarded()", "public", "false", "noReentrancy", "locked;balances", "locked;balances", "", "msg.sender.transfer", ""}
+	if got := records[5]; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSummaryPrinter_PrintJSONL(t *testing.T) {
+	summaries := detectors.NewFunctionSummaries(testutil.LoadContext(t, "../detectors/test_ast_dataset/Bank.sol.ast.json"))
+
+	var out bytes.Buffer
+	if err := printer.NewSummaryPrinter(&out).PrintJSONL(summaries); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != len(summaries) {
+		t.Fatalf("Expected a line per entry point, got %d", len(lines))
+	}
+	var getter, deposit map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &getter); err != nil {
+		t.Fatal(err)
+	}
+	if getter["function"] != "balances(address)" || getter["visibility"] != "public" || len(getter["reads"].([]interface{})) != 1 {
+		t.Errorf("Expected the getter of balances to read it, got %v", getter)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &deposit); err != nil {
+		t.Fatal(err)
+	}
+	if deposit["function"] != "deposit()" || deposit["payable"] != true {
+		t.Errorf("Expected deposit to be payable, got %v", deposit)
+	}
+	if reads, ok := deposit["reads"].([]interface{}); !ok || len(reads) != 0 {
+		t.Errorf("Expected an empty list of reads, got %v", deposit["reads"])
+	}
+}
@@ -0,0 +1,99 @@
+package srcmap
+
+import (
+	"testing"
+	"txtracker/internal/srcmap"
+)
+
+const source = "pragma solidity ^0.4.24;\r\n" +
+	"// Café ☕\n" +
+	"contract Café { uint256 total; }\n"
+
+func TestParse(t *testing.T) {
+	r, err := srcmap.Parse("120:35:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Start != 120 || r.Length != 35 || r.File != 2 || r.End() != 155 {
+		t.Errorf("Unexpected range %+v", r)
+	}
+	for _, src := range []string{"120:35", "a:1:0", ""} {
+		if _, err := srcmap.Parse(src); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", src)
+		}
+	}
+}
+
+func TestFile_Position(t *testing.T) {
+	f := srcmap.NewFile("Cafe.sol", []byte(source))
+	contract := len("pragma solidity ^0.4.24;\r\n// Café ☕\n")
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "1:1"},
+		{len("pragma"), "1:7"},
+		// the \r belongs to the first line
+		{len("pragma solidity ^0.4.24;\r"), "1:26"},
+		{len("pragma solidity ^0.4.24;\r\n"), "2:1"},
+		// é is 2 bytes and ☕ 3 bytes, each one column
+		{len("pragma solidity ^0.4.24;\r\n// Café ☕"), "2:10"},
+		{contract + len("contract Café { "), "3:17"},
+		{len(source) + 10, "4:1"},
+	}
+
+	for _, tt := range tests {
+		if got := f.Position(tt.offset).String(); got != tt.expected {
+			t.Errorf("Position(%d) = %s, want %s", tt.offset, got, tt.expected)
+		}
+	}
+	if f.Line(1) != "pragma solidity ^0.4.24;" || f.Line(3) != "contract Café { uint256 total; }" || f.Line(5) != "" {
+		t.Errorf("Unexpected lines %q, %q", f.Line(1), f.Line(3))
+	}
+	if text := f.Text(srcmap.Range{Start: contract + len("contract "), Length: len("Café")}); text != "Café" {
+		t.Errorf("Text() = %q, want Café", text)
+	}
+}
+
+func TestFile_Snippet(t *testing.T) {
+	f := srcmap.NewFile("Cafe.sol", []byte(source))
+	start := len("pragma solidity ^0.4.24;\r\n// Café ☕\ncontract Café { ")
+
+	expected := "3 | contract Café { uint256 total; }\n" +
+		"  |                 ^^^^^^^^^^^^^\n"
+	if got := f.Snippet(srcmap.Range{Start: start, Length: len("uint256 total")}); got != expected {
+		t.Errorf("Snippet() =\n%s\nwant\n%s", got, expected)
+	}
+
+	// a range over two lines underlines the end of the first one
+	expected = "2 | // Café ☕\n" +
+		"  |         ^\n" +
+		"3 | contract Café { uint256 total; }\n" +
+		"  | ^^^^^^^^\n"
+	from := len("pragma solidity ^0.4.24;\r\n// Café ")
+	if got := f.Snippet(srcmap.Range{Start: from, Length: start - from - len(" Café { ")}); got != expected {
+		t.Errorf("Snippet() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestSourceMap_Resolve(t *testing.T) {
+	sources := srcmap.NewSourceMap()
+	sources.Add(1, "src/Cafe.sol", []byte(source))
+
+	r, file, err := sources.Resolve("26:11:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file == nil || file.Path != "src/Cafe.sol" || file.Position(r.Start).Line != 2 {
+		t.Errorf("Unexpected file %+v for %+v", file, r)
+	}
+	if _, file, _ := sources.Resolve("0:10:0"); file != nil {
+		t.Errorf("Expected no file at index 0, got %s", file.Path)
+	}
+
+	// a nil source map knows no file
+	var none *srcmap.SourceMap
+	if _, file, err := none.Resolve("0:10:0"); file != nil || err != nil {
+		t.Errorf("Expected nothing from a nil source map, got %v, %v", file, err)
+	}
+}
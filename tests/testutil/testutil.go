@@ -0,0 +1,48 @@
+// Package testutil holds the helpers shared by the tests of the packages
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	CFG "txtracker/internal/cfg"
+	"txtracker/internal/detectors"
+	"txtracker/internal/parser"
+	"txtracker/internal/srcmap"
+	symboltable "txtracker/internal/symbol_table"
+)
+
+// LoadContext parses an AST file, e.g. test_ast_dataset/Bank.sol.ast.json, and
+// builds its symbol table and CFG. The context is named after the Solidity file,
+// Bank.sol, and has no source. The test fails if the AST cannot be parsed or
+// its symbols cannot be resolved.
+func LoadContext(t testing.TB, file string) *detectors.Context {
+	t.Helper()
+	root, err := parser.NewASTParser().ParseAST_JSON(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbolTable, err := symboltable.NewGlobalSymbolTable(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &detectors.Context{
+		Path:        strings.TrimSuffix(filepath.Base(file), ".ast.json"),
+		Root:        root,
+		SymbolTable: symbolTable,
+		CFG:         CFG.NewCFG(root, symbolTable),
+	}
+}
+
+// LoadSource sets the sources of the context to the Solidity file, as the source
+// of index 0 named name, e.g. contracts/Bank.sol
+func LoadSource(t testing.TB, ctx *detectors.Context, file string, name string) {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Sources = srcmap.NewSourceMap()
+	ctx.Sources.Add(0, name, content)
+}
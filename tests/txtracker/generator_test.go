@@ -0,0 +1,114 @@
+package txtracker
+
+import (
+	"testing"
+	"txtracker/internal/txtracker"
+	"txtracker/tests/testutil"
+)
+
+const likerCoin = "../parser/test_ast_dataset/0x0a3f9678d6b631386c2dd3de8809b48b0d1bbd56.sol.ast.json"
+
+func generate(t *testing.T, testPath string, maxLength int) []*txtracker.TxSeQuence {
+	return txtracker.NewGenerator(testutil.LoadContext(t, testPath), maxLength).Generate()
+}
+
+func findSequence(sequences []*txtracker.TxSeQuence, name string) *txtracker.TxSeQuence {
+	for _, seq := range sequences {
+		if seq.Name == name {
+			return seq
+		}
+	}
+	return nil
+}
+
+func TestGenerator_Dependencies(t *testing.T) {
+	sequences := generate(t, likerCoin, 2)
+
+	seq := findSequence(sequences, "LikerCoin::pause -> LikerCoin::transfer")
+	if seq == nil {
+		t.Fatal("Expected pause -> transfer, transfer is guarded by whenNotPaused")
+	}
+	deps := seq.Dependencies(1)
+	if len(deps) != 1 || deps[0] != "LikerCoin::paused" {
+		t.Errorf("Expected transfer to depend on LikerCoin::paused, got %v", deps)
+	}
+
+	// useBalanceOf writes no state variable, nothing can depend on it
+	if findSequence(sequences, "LikerCoin::useBalanceOf -> LikerCoin::transfer") != nil {
+		t.Error("Expected no sequence starting with a read-only function")
+	}
+}
+
+func TestGenerator_Sequences(t *testing.T) {
+	sequences := generate(t, likerCoin, 3)
+	if len(sequences) == 0 {
+		t.Fatal("Expected sequences to be generated")
+	}
+
+	for _, seq := range sequences {
+		if len(seq.Tx) < 2 || len(seq.Tx) > 3 {
+			t.Errorf("%s: expected 2 to 3 transactions, got %d", seq.Name, len(seq.Tx))
+		}
+		for i, tx := range seq.Tx {
+			if tx.Name == "LikerCoin::LikerCoin" {
+				t.Errorf("%s: expected no constructor", seq.Name)
+			}
+			if i > 0 && len(seq.Dependencies(i)) == 0 {
+				t.Errorf("%s: expected %s to depend on a previous transaction", seq.Name, tx.Name)
+			}
+		}
+	}
+}
+
+func TestGenerator_Writes(t *testing.T) {
+	sequences := generate(t, "../detectors/test_ast_dataset/Auction.sol.ast.json", 2)
+
+	// (highestBidder, highestBid) = (msg.sender, msg.value) writes both components
+	seq := findSequence(sequences, "Auction::bid -> Auction::highestBidder")
+	if seq == nil {
+		t.Fatal("Expected bid -> highestBidder, bid assigns highestBidder in a tuple")
+	}
+	if deps := seq.Dependencies(1); len(deps) != 1 || deps[0] != "Auction::highestBidder" {
+		t.Errorf("Expected highestBidder to depend on Auction::highestBidder, got %v", deps)
+	}
+
+	// reset writes highestBid through the internal function _clear
+	seq = findSequence(sequences, "Auction::reset -> Auction::bid")
+	if seq == nil {
+		t.Fatal("Expected reset -> bid, _clear writes highestBid")
+	}
+	if deps := seq.Dependencies(1); len(deps) != 1 || deps[0] != "Auction::highestBid" {
+		t.Errorf("Expected bid to depend on Auction::highestBid, got %v", deps)
+	}
+}
+
+func TestGenerator_AssignedBeforeRead(t *testing.T) {
+	sequences := generate(t, "../detectors/test_ast_dataset/Auction.sol.ast.json", 2)
+
+	// restart reads round only after assigning it, the previous value is lost
+	if findSequence(sequences, "Auction::restart -> Auction::restart") != nil {
+		t.Error("Expected no restart -> restart, restart assigns round before reading it")
+	}
+	// bid reads the values of highestBid and refunds left by the previous bid
+	seq := findSequence(sequences, "Auction::bid -> Auction::bid")
+	if seq == nil {
+		t.Fatal("Expected bid -> bid")
+	}
+	if deps := seq.Dependencies(1); len(deps) != 3 {
+		t.Errorf("Expected bid to depend on the three state variables, got %v", deps)
+	}
+}
+
+func TestGenerator_StorageSlots(t *testing.T) {
+	sequences := generate(t, "../detectors/test_ast_dataset/Proxy.sol.ast.json", 2)
+
+	// upgradeTo stores the implementation in slot, the fallback loads it from cell,
+	// both locals hold IMPLEMENTATION_SLOT
+	seq := findSequence(sequences, "Proxy::upgradeTo -> Proxy::fallback")
+	if seq == nil {
+		t.Fatal("Expected upgradeTo -> fallback, the fallback loads the implementation")
+	}
+	if deps := seq.Dependencies(1); len(deps) != 1 || deps[0] != "Proxy::storage[IMPLEMENTATION_SLOT]" {
+		t.Errorf("Expected the fallback to depend on Proxy::storage[IMPLEMENTATION_SLOT], got %v", deps)
+	}
+}